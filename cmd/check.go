@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/series"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+)
+
+const defaultCheckPeriod = 24 * time.Hour
+
+func newCheckCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	var (
+		debug        bool
+		fromString   string
+		untilString  string
+		maxGapString string
+		maxAgeString string
+		tzString     string
+
+		fromDate  *time.Time
+		untilDate *time.Time
+		option    series.CheckOption
+		location  *time.Location
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check [flags] URL POINT_ID...",
+		Short: "Check fetched points for gaps and stale data",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			argumentErrors := make([]error, 0, 5)
+
+			if loc, err := parseLocation(tzString); err == nil {
+				location = loc
+			} else {
+				argumentErrors = append(argumentErrors, err)
+			}
+			if fromString != "" {
+				if dt, err := tools.ParseTime(fromString, timeNow(), location); err == nil {
+					fromDate = &dt
+				} else {
+					argumentErrors = append(argumentErrors, errors.Wrap(err, "from allows only datetime, date, unix time or time expression"))
+				}
+			}
+			if untilString != "" {
+				if dt, err := tools.ParseTime(untilString, timeNow(), location); err == nil {
+					untilDate = &dt
+				} else {
+					argumentErrors = append(argumentErrors, errors.Wrap(err, "until allows only datetime, date, unix time or time expression"))
+				}
+			}
+			if maxGapString != "" {
+				if d, err := series.ParseInterval(maxGapString); err == nil {
+					option.MaxGap = d
+				} else {
+					argumentErrors = append(argumentErrors, errors.Wrap(err, "max-gap allows only duration"))
+				}
+			}
+			if maxAgeString != "" {
+				if d, err := series.ParseInterval(maxAgeString); err == nil {
+					option.MaxAge = d
+				} else {
+					argumentErrors = append(argumentErrors, errors.Wrap(err, "max-age allows only duration"))
+				}
+			}
+			if len(args) < 2 {
+				argumentErrors = append(argumentErrors, errors.New("too few arguments"))
+			} else if err := validateKeys(args[1:], fromDate, untilDate, nil, model.SelectTypeNone); err != nil {
+				argumentErrors = append(argumentErrors, err)
+			}
+
+			if len(argumentErrors) > 0 {
+				return errors.Join(argumentErrors...)
+			}
+			cmd.SilenceUsage = true
+
+			connectionURL := args[0]
+			ids := args[1:]
+			option.Now = timeNow()
+			if fromDate == nil {
+				dt := option.Now.Add(-defaultCheckPeriod)
+				fromDate = &dt
+			}
+
+			if debug {
+				cmd.Println("url:", connectionURL)
+				cmd.Println("ids:", ids)
+				cmd.Println("from:", fromDate)
+				cmd.Println("until:", untilDate)
+				cmd.Println("max-gap:", option.MaxGap)
+				cmd.Println("max-age:", option.MaxAge)
+			}
+
+			report, err := executeCheck(connectionURL, ids, fromDate, untilDate, option, location, clientOptions(debug)...)
+			if err != nil {
+				// 検査を実行できなかった場合は、監視プラグインの慣習に従いUNKNOWNとする
+				return &exitError{code: exitCodeUnknown, err: err}
+			}
+			cmd.Print(formatCheckReport(report))
+			if !report.OK() {
+				return &exitError{
+					code: exitCodeCheckFailed,
+					err:  errors.Newf("%d of %d points failed the check", len(report.Failed()), len(report.Points)),
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+
+	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "set output log level to debug")
+	cmd.Flags().StringVar(&fromString, "from", "", "check values from datetime (default 24 hours ago) string=<Datetime in RFC 3339 format, date, unix time or time expression such as -24h, now-7d, today>")
+	cmd.Flags().StringVar(&untilString, "until", "", "check values until datetime string=<Datetime in RFC 3339 format, date, unix time or time expression such as -24h, now-7d, today>")
+	cmd.Flags().StringVar(&tzString, "tz", "", "time zone of output and of from/until without offset. string=<Local|UTC|Time zone name such as Asia/Tokyo>")
+	cmd.Flags().StringVar(&maxGapString, "max-gap", "", "maximum allowed interval between values. string=<Duration such as 15m, 1h or 1d>")
+	cmd.Flags().StringVar(&maxAgeString, "max-age", "", "maximum allowed age of the last value. string=<Duration such as 15m, 1h or 1d>")
+
+	return cmd
+}
+
+func executeCheck(connectionURL string, ids []string, fromDate, untilDate *time.Time, option series.CheckOption, location *time.Location, opts ...fiap.Option) (series.Report, error) {
+	fetchClient := createFetchClient(connectionURL, append([]fiap.Option{fiap.WithLocation(location)}, opts...)...)
+	_, points, fiapErr, err := fetchClient.FetchDateRange(fromDate, untilDate, ids...)
+	if err != nil {
+		return series.Report{}, errors.Wrapf(err, "failed to fetch from %s", connectionURL)
+	}
+	if fiapErr != nil {
+		return series.Report{}, fiap.NewFIAPError(fiapErr)
+	}
+
+	// 値が1つも返らなかったIDも検査対象に含める
+	checked := make(map[string]([]model.Value), len(ids))
+	for _, id := range ids {
+		checked[id] = points[id]
+	}
+	return series.Check(checked, option), nil
+}
+
+func formatCheckReport(report series.Report) string {
+	var b strings.Builder
+	if report.OK() {
+		fmt.Fprintf(&b, "OK - %d points checked\n", len(report.Points))
+	} else {
+		fmt.Fprintf(&b, "CRITICAL - %d of %d points failed the check\n", len(report.Failed()), len(report.Points))
+	}
+	for _, p := range report.Points {
+		status := "ok"
+		if !p.OK() {
+			status = "failed"
+		}
+		if p.LastSeen != nil {
+			fmt.Fprintf(&b, "%s: %s, count %d, last seen %s (%s ago)", p.ID, status, p.Count, p.LastSeen.Format(time.RFC3339Nano), p.Age)
+		} else {
+			fmt.Fprintf(&b, "%s: %s, count %d, never seen", p.ID, status, p.Count)
+		}
+		if p.Stale {
+			b.WriteString(", stale")
+		}
+		b.WriteString("\n")
+		for _, g := range p.Gaps {
+			fmt.Fprintf(&b, "  gap: %s - %s (%s)\n", g.From.Format(time.RFC3339Nano), g.Until.Format(time.RFC3339Nano), g.Duration)
+		}
+		for _, d := range p.Duplicates {
+			fmt.Fprintf(&b, "  duplicate: %s\n", d.Format(time.RFC3339Nano))
+		}
+		for _, o := range p.OutOfOrder {
+			fmt.Fprintf(&b, "  out of order: %s after %s\n", o.Time.Format(time.RFC3339Nano), o.Previous.Format(time.RFC3339Nano))
+		}
+	}
+	return b.String()
+}
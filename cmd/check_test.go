@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+func TestCheckCommandRun(t *testing.T) {
+	originalTimeNow := timeNow
+	timeNow = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { timeNow = originalTimeNow }()
+
+	mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = true, true, false
+	mockClient.results.pointSets = map[string](model.ProcessedPointSet){}
+	mockClient.results.points = map[string]([]model.Value){
+		"http://test.url/id1": {
+			{Time: time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), Value: "1"},
+			{Time: time.Date(2024, 1, 1, 11, 30, 0, 0, time.UTC), Value: "2"},
+		},
+		"http://test.url/id2": {
+			{Time: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC), Value: "1"},
+			{Time: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), Value: "2"},
+		},
+	}
+	mockClient.results.fiapErr = nil
+
+	t.Run("OK", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "check", "--max-gap", "1h", "--max-age", "1h", "http://test.url", "http://test.url/id1"}
+		expectedOut := `OK - 1 points checked
+http://test.url/id1: ok, count 2, last seen 2024-01-01T11:30:00Z (30m0s ago)
+`
+		expectedFrom := time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC)
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err != nil {
+			t.Error("failed to run command")
+		}
+		if ExitCode(err) != 0 {
+			t.Error("assertion error of exit code")
+		}
+		if mockOut.String() != expectedOut {
+			t.Error("assertion error of stdout")
+		}
+		if mockErrOut.String() != "" {
+			t.Error("assertion error of stderr")
+		}
+		if mockClient.actualArguments.fromDate == nil || !mockClient.actualArguments.fromDate.Equal(expectedFrom) {
+			t.Error("assertion error of from date")
+		}
+		if mockClient.actualArguments.untilDate != nil {
+			t.Error("assertion error of until date")
+		}
+	})
+	t.Run("Failed", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "check", "--from", "2024-01-01T00:00:00Z", "--max-gap", "1h", "--max-age", "1h", "http://test.url", "http://test.url/id1", "http://test.url/id2", "http://test.url/id3"}
+		expectedOut := `CRITICAL - 2 of 3 points failed the check
+http://test.url/id1: ok, count 2, last seen 2024-01-01T11:30:00Z (30m0s ago)
+http://test.url/id2: failed, count 2, last seen 2024-01-01T10:00:00Z (2h0m0s ago), stale
+  gap: 2024-01-01T08:00:00Z - 2024-01-01T10:00:00Z (2h0m0s)
+http://test.url/id3: failed, count 0, never seen, stale
+`
+		expectedErrOut := `Error: 2 of 3 points failed the check
+`
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err == nil {
+			t.Error("expected to fail command but succeed")
+		}
+		if ExitCode(err) != exitCodeCheckFailed {
+			t.Error("assertion error of exit code")
+		}
+		if mockOut.String() != expectedOut {
+			t.Error("assertion error of stdout")
+		}
+		if mockErrOut.String() != expectedErrOut {
+			t.Error("assertion error of stderr")
+		}
+		if len(mockClient.actualArguments.ids) != 3 {
+			t.Error("assertion error of id")
+		}
+	})
+	t.Run("ArgumentError", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "check", "--max-gap", "often", "http://test.url"}
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err == nil {
+			t.Error("expected to fail command but succeed")
+		} else {
+			if !strings.Contains(err.Error(), "max-gap allows only duration") {
+				t.Error("expected max-gap argument error but not")
+			}
+			if !strings.Contains(err.Error(), "too few arguments") {
+				t.Error("expected too few arguments error but not")
+			}
+		}
+		if ExitCode(err) != exitCodeError {
+			t.Error("assertion error of exit code")
+		}
+	})
+	t.Run("InvalidKeys", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "check", "http://test.url", "http://test.url/id1", "http://test.url/id2", "http://test.url/id1"}
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err == nil {
+			t.Error("expected to fail command but succeed")
+		} else if !strings.Contains(err.Error(), "keys is duplicated, index: 0 and 2, id: http://test.url/id1") {
+			t.Error("expected duplicated keys error but not")
+		}
+		if ExitCode(err) != exitCodeError {
+			t.Error("assertion error of exit code")
+		}
+		if mockClient.actualArguments.connectionURL != "" {
+			t.Error("expected not to fetch but fetched")
+		}
+	})
+	t.Run("FetchError", func(t *testing.T) {
+		mockClient.failDateRange = true
+		defer func() { mockClient.failDateRange = false }()
+		os.Args = []string{"go-fiap-client", "check", "http://test.url", "http://test.url/id1"}
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err == nil {
+			t.Error("expected to fail command but succeed")
+		} else if !strings.Contains(err.Error(), "test FetchDateRange error") {
+			t.Error("expected fetch error but not")
+		}
+		if ExitCode(err) != exitCodeUnknown {
+			t.Error("assertion error of exit code")
+		}
+	})
+	t.Run("FIAPError", func(t *testing.T) {
+		mockClient.results.fiapErr = &model.Error{Type: "POINT_NOT_FOUND", Value: "http://test.url/id1"}
+		defer func() { mockClient.results.fiapErr = nil }()
+		os.Args = []string{"go-fiap-client", "check", "http://test.url", "http://test.url/id1"}
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err == nil {
+			t.Error("expected to fail command but succeed")
+		} else if !errors.Is(err, fiap.ErrPointNotFound) {
+			t.Error("expected fiap error but not")
+		}
+		if ExitCode(err) != exitCodeUnknown {
+			t.Error("assertion error of exit code")
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/conformance"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+)
+
+func newConformanceCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	var (
+		debug          bool
+		pointSetID     string
+		unknownPointID string
+		acceptableSize uint
+	)
+
+	cmd := &cobra.Command{
+		Use:   "conformance [flags] URL POINT_ID",
+		Short: "Check FIAP server conformance of FETCH queries",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			argumentErrors := make([]error, 0, 2)
+
+			if len(args) < 2 {
+				argumentErrors = append(argumentErrors, errors.New("too few arguments"))
+			} else if len(args) > 2 {
+				argumentErrors = append(argumentErrors, errors.New("too many arguments"))
+			} else {
+				ids := []string{args[1]}
+				if pointSetID != "" {
+					ids = append(ids, pointSetID)
+				}
+				if unknownPointID != "" {
+					ids = append(ids, unknownPointID)
+				}
+				if err := validateKeys(ids, nil, nil, nil, model.SelectTypeNone); err != nil {
+					argumentErrors = append(argumentErrors, err)
+				}
+			}
+
+			if len(argumentErrors) > 0 {
+				return errors.Join(argumentErrors...)
+			}
+			cmd.SilenceUsage = true
+
+			connectionURL := args[0]
+			target := conformance.Target{
+				PointID:        args[1],
+				PointSetID:     pointSetID,
+				UnknownPointID: unknownPointID,
+				AcceptableSize: acceptableSize,
+			}
+
+			if debug {
+				cmd.Println("url:", connectionURL)
+				cmd.Println("point:", target.PointID)
+				cmd.Println("pointset:", target.PointSetID)
+				cmd.Println("unknown-point:", target.UnknownPointID)
+				cmd.Println("acceptable-size:", target.AcceptableSize)
+			}
+
+			report, err := executeConformance(connectionURL, target, clientOptions(debug)...)
+			if err != nil {
+				return err
+			}
+			cmd.Print(formatConformanceReport(report))
+			if !report.OK() {
+				return &exitError{
+					code: exitCodeCheckFailed,
+					err:  errors.Newf("%d of %d checks failed", len(report.Failed()), len(report.Results)),
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+
+	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "set output log level to debug")
+	cmd.Flags().StringVar(&pointSetID, "pointset", "", "pointSet which has child pointSets or points. string=<POINTSET_ID>")
+	cmd.Flags().StringVar(&unknownPointID, "unknown-point", "", "point which does not exist on the server (default POINT_ID with a suffix) string=<POINT_ID>")
+	cmd.Flags().UintVar(&acceptableSize, "acceptable-size", conformance.DefaultAcceptableSize, "acceptableSize used to check pagination")
+
+	return cmd
+}
+
+func executeConformance(connectionURL string, target conformance.Target, opts ...fiap.Option) (conformance.Report, error) {
+	fetchClient := createFetchClient(connectionURL, opts...)
+	report, err := conformance.Run(fetchClient, target)
+	if err != nil {
+		return conformance.Report{}, errors.Wrapf(err, "failed to check %s", connectionURL)
+	}
+	return report, nil
+}
+
+func formatConformanceReport(report conformance.Report) string {
+	var b strings.Builder
+	if report.OK() {
+		fmt.Fprintf(&b, "OK - %d checks run\n", len(report.Results))
+	} else {
+		fmt.Fprintf(&b, "FAILED - %d of %d checks failed\n", len(report.Failed()), len(report.Results))
+	}
+	for _, r := range report.Results {
+		fmt.Fprintf(&b, "%s: %s", r.Name, r.Status)
+		if r.Message != "" {
+			fmt.Fprintf(&b, ", %s", r.Message)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
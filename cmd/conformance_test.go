@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/fiaptest"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+func TestConformanceCommandRun(t *testing.T) {
+	server := fiaptest.NewServer()
+	defer server.Close()
+	base := time.Date(2012, 2, 2, 16, 34, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		server.AddPoint("http://xxxxxxxx/tokyo/building1/Room101/", model.Value{Time: base.Add(time.Duration(i) * time.Minute), Value: "30"})
+	}
+	server.AddPoint("http://xxxxxxxx/tokyo/building1/Room102/")
+	server.AddPointSet("http://xxxxxxxx/tokyo/building1/", nil, []string{"http://xxxxxxxx/tokyo/building1/Room101/", "http://xxxxxxxx/tokyo/building1/Room102/"})
+
+	// 実際のFetchClientでfiaptestのサーバに問い合わせる
+	createFetchClient = originalCreateFetchClient
+	defer func() { createFetchClient = mockCreateFetchClient }()
+
+	t.Run("OK", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "conformance", "--pointset", "http://xxxxxxxx/tokyo/building1/", server.URL, "http://xxxxxxxx/tokyo/building1/Room101/"}
+		expectedOut := `OK - 8 checks run
+select max: pass, value at 2012-02-02T16:38:00Z
+select min: pass, value at 2012-02-02T16:34:00Z
+range inclusive: pass, 5 values
+range exclusive: pass, 3 values
+acceptable size: pass, 2 values with acceptableSize 2
+cursor pagination: pass, 5 values in 3 pages
+pointset listing: pass, 0 pointSets and 2 points
+unknown point: pass, POINT_NOT_FOUND
+`
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err != nil {
+			t.Errorf("failed to run command: %v", err)
+		}
+		if ExitCode(err) != 0 {
+			t.Error("assertion error of exit code")
+		}
+		if mockOut.String() != expectedOut {
+			t.Errorf("assertion error of stdout: %s", mockOut.String())
+		}
+	})
+	t.Run("Failed", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "conformance", "--acceptable-size", "10", "--unknown-point", "http://xxxxxxxx/tokyo/building1/Room102/", server.URL, "http://xxxxxxxx/tokyo/building1/Room101/"}
+		expectedOut := `FAILED - 1 of 8 checks failed
+select max: pass, value at 2012-02-02T16:38:00Z
+select min: pass, value at 2012-02-02T16:34:00Z
+range inclusive: pass, 5 values
+range exclusive: pass, 3 values
+acceptable size: pass, 5 values with acceptableSize 10
+cursor pagination: skip, needs more than 10 values
+pointset listing: skip, no pointSet specified
+unknown point: fail, no error returned for http://xxxxxxxx/tokyo/building1/Room102/
+`
+		expectedErrOut := `Error: 1 of 8 checks failed
+`
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err == nil {
+			t.Error("expected to fail command but succeed")
+		}
+		if ExitCode(err) != exitCodeCheckFailed {
+			t.Error("assertion error of exit code")
+		}
+		if mockOut.String() != expectedOut {
+			t.Errorf("assertion error of stdout: %s", mockOut.String())
+		}
+		if mockErrOut.String() != expectedErrOut {
+			t.Errorf("assertion error of stderr: %s", mockErrOut.String())
+		}
+	})
+	t.Run("RuntimeError", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "conformance", server.URL, "http://xxxxxxxx/tokyo/building1/Room102/"}
+		expectedErrOut := "Error: failed to check " + server.URL + ": point http://xxxxxxxx/tokyo/building1/Room102/ has no values\n"
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if ExitCode(err) != exitCodeError {
+			t.Error("assertion error of exit code")
+		}
+		if mockErrOut.String() != expectedErrOut {
+			t.Errorf("assertion error of stderr: %s", mockErrOut.String())
+		}
+	})
+	t.Run("ArgumentError", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "conformance", "http://test.url"}
+		expectedErrOut := `Error: too few arguments`
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err == nil {
+			t.Error("expected to fail command but succeed")
+		}
+		if !strings.HasPrefix(mockErrOut.String(), expectedErrOut) {
+			t.Errorf("assertion error of stderr: %s", mockErrOut.String())
+		}
+	})
+}
@@ -1,21 +1,28 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"io"
+	"log/slog"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/cache"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/cassette"
 	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/series"
 	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
 	"github.com/cockroachdb/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
-	createFetchClient func(string) fiap.Fetcher = func(connectionURL string) fiap.Fetcher {
-		return &fiap.FetchClient{ConnectionURL: connectionURL}
+	createFetchClient func(string, ...fiap.Option) fiap.Fetcher = func(connectionURL string, opts ...fiap.Option) fiap.Fetcher {
+		return fiap.NewFetchClient(connectionURL, opts...)
 	}
 	createFile func(string) (io.WriteCloser, error) = func(name string) (io.WriteCloser, error) {
 		return os.Create(name)
@@ -23,18 +30,37 @@ var (
 	marshalJSON func(v any) ([]byte, error) = json.Marshal
 )
 
+// clientOptions はコマンドのフラグからFetchClientの設定を作成する。秒未満の時刻を指定できるように、keyの時刻はRFC3339Nanoで送信する
+func clientOptions(debug bool) []fiap.Option {
+	opts := []fiap.Option{fiap.WithTimeLayout(time.RFC3339Nano)}
+	if debug {
+		opts = append(opts, fiap.WithLogLevel(slog.LevelDebug))
+	}
+	return opts
+}
+
 func newFetchCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 	var (
-		debug        bool
-		outputString string
-		selectString string
-		fromString   string
-		untilString  string
+		debug           bool
+		outputString    string
+		selectString    string
+		fromString      string
+		untilString     string
+		aggregateString string
+		intervalString  string
+		tzString        string
+		recordString    string
+		replayString    string
+		cacheDirString  string
+		cacheRefresh    bool
 
 		output     io.WriteCloser
 		selectType model.SelectType = model.SelectTypeMaximum
 		fromDate   *time.Time
 		untilDate  *time.Time
+		value      *model.ValueCondition
+		resample   *series.ResampleOption
+		location   *time.Location
 	)
 
 	cmd := &cobra.Command{
@@ -43,34 +69,60 @@ func newFetchCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			argumentErrors := make([]error, 0, 4)
 
-			switch selectString {
-			case "max":
-				selectType = model.SelectTypeMaximum
-			case "min":
-				selectType = model.SelectTypeMinimum
-			case "none":
-				selectType = model.SelectTypeNone
-			default:
-				argumentErrors = append(argumentErrors, errors.New("select type allows only max, min, or none"))
+			if st, err := parseSelect(selectString); err == nil {
+				selectType = st
+			} else {
+				argumentErrors = append(argumentErrors, err)
+			}
+			value = parseValueCondition(cmd.Flags())
+			if value != nil {
+				if fromString != "" || untilString != "" {
+					argumentErrors = append(argumentErrors, errors.New("value conditions cannot be combined with from or until"))
+				}
+				// --value-eqは1つの値に一致するデータを取得するため、--selectを指定しない場合はselectを使用しない
+				if value.Eq != nil && !cmd.Flags().Changed("select") {
+					selectType = model.SelectTypeNone
+				}
+			}
+			if loc, err := parseLocation(tzString); err == nil {
+				location = loc
+			} else {
+				argumentErrors = append(argumentErrors, err)
 			}
 			if fromString != "" {
-				if dt, err := time.Parse(time.RFC3339, fromString); err == nil {
+				if dt, err := tools.ParseTime(fromString, timeNow(), location); err == nil {
 					fromDate = &dt
 				} else {
-					argumentErrors = append(argumentErrors, errors.Wrap(err, "from allows only datetime in RFC3339 format"))
+					argumentErrors = append(argumentErrors, errors.Wrap(err, "from allows only datetime, date, unix time or time expression"))
 				}
 			}
 			if untilString != "" {
-				if dt, err := time.Parse(time.RFC3339, untilString); err == nil {
+				if dt, err := tools.ParseTime(untilString, timeNow(), location); err == nil {
 					untilDate = &dt
 				} else {
-					argumentErrors = append(argumentErrors, errors.Wrap(err, "until allows only datetime in RFC3339 format"))
+					argumentErrors = append(argumentErrors, errors.Wrap(err, "until allows only datetime, date, unix time or time expression"))
 				}
 			}
+			if r, err := parseResample(aggregateString, intervalString, location); err == nil {
+				resample = r
+			} else {
+				argumentErrors = append(argumentErrors, err)
+			}
+			if recordString != "" && replayString != "" {
+				argumentErrors = append(argumentErrors, errors.New("record and replay cannot be specified together"))
+			}
+			if cacheDirString != "" && (selectType != model.SelectTypeNone || value != nil) {
+				argumentErrors = append(argumentErrors, errors.New("cache-dir can be used only with select none and without value conditions"))
+			}
+			if cacheRefresh && cacheDirString == "" {
+				argumentErrors = append(argumentErrors, errors.New("cache-refresh requires cache-dir"))
+			}
 			if len(args) < 2 {
 				argumentErrors = append(argumentErrors, errors.New("too few arguments"))
 			} else if len(args) > 2 {
 				argumentErrors = append(argumentErrors, errors.New("too many arguments"))
+			} else if err := validateKeys(args[1:], fromDate, untilDate, value, selectType); err != nil {
+				argumentErrors = append(argumentErrors, err)
 			}
 
 			if len(argumentErrors) > 0 {
@@ -81,9 +133,6 @@ func newFetchCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 
 			connectionURL := args[0]
 			id := args[1]
-			if debug {
-				tools.SetLogLevel(tools.LogLevelDebug)
-			}
 			if outputString != "" {
 				if f, err := createFile(outputString); err == nil {
 					output = f
@@ -92,6 +141,24 @@ func newFetchCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 				}
 			}
 
+			opts := clientOptions(debug)
+			var recorder *cassette.Recorder
+			if replayString != "" {
+				c, err := cassette.Load(replayString)
+				if err != nil {
+					return errors.Wrapf(err, "cannot load cassette '%s'", replayString)
+				}
+				opts = append(opts, cassette.WithReplay(c))
+			}
+			if recordString != "" {
+				recorder = cassette.NewRecorder(nil)
+				opts = append(opts, cassette.WithRecorder(recorder))
+			}
+			var cacheOpt *cacheOption
+			if cacheDirString != "" {
+				cacheOpt = &cacheOption{dir: cacheDirString, refresh: cacheRefresh}
+			}
+
 			if debug {
 				cmd.Println("url:", connectionURL)
 				cmd.Println("id:", id)
@@ -100,9 +167,15 @@ func newFetchCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 				cmd.Println("select:", selectType)
 				cmd.Println("from:", fromDate)
 				cmd.Println("until:", untilDate)
+				if value != nil {
+					cmd.Println("value:", formatValueCondition(value))
+				}
+				if cacheOpt != nil {
+					cmd.Println("cache-dir:", cacheOpt.dir)
+				}
 			}
 
-			if jsonResult, fErr, err := executeFetch(connectionURL, id, fromDate, untilDate, selectType); err == nil {
+			if jsonResult, fErr, err := executeFetch(cmd.Context(), connectionURL, id, fromDate, untilDate, value, selectType, resample, location, cacheOpt, opts...); err == nil {
 				if fErr != nil {
 					runtimeErrors = append(runtimeErrors, fErr)
 				}
@@ -120,6 +193,11 @@ func newFetchCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 				runtimeErrors = append(runtimeErrors, err)
 			}
 
+			if recorder != nil {
+				if err := recorder.Cassette().Save(recordString); err != nil {
+					runtimeErrors = append(runtimeErrors, err)
+				}
+			}
 			if output != nil {
 				if err := output.Close(); err != nil {
 					runtimeErrors = append(runtimeErrors, errors.Wrapf(err, "failed to close file '%s'", outputString))
@@ -138,53 +216,240 @@ func newFetchCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "set output log level to debug")
 	cmd.Flags().StringVarP(&outputString, "output", "o", "", "specify output file path. string=<filepath>")
 	cmd.Flags().StringVarP(&selectString, "select", "s", "max", "fiap select option. string=<max|min|none>")
-	cmd.Flags().StringVar(&fromString, "from", "", "filter query from datetime string=<Datetime in RFC 3339 format>")
-	cmd.Flags().StringVar(&untilString, "until", "", "filter query until datetime string=<Datetime in RFC 3339 format>")
+	cmd.Flags().StringVar(&fromString, "from", "", "filter query from datetime string=<Datetime in RFC 3339 format, date, unix time or time expression such as -24h, now-7d, today>")
+	cmd.Flags().StringVar(&untilString, "until", "", "filter query until datetime string=<Datetime in RFC 3339 format, date, unix time or time expression such as -24h, now-7d, today>")
+	cmd.Flags().String("value-eq", "", "filter query by value equal to the string (select is none unless --select is specified). string=<Value>")
+	cmd.Flags().String("value-neq", "", "filter query by value not equal to the string. string=<Value>")
+	cmd.Flags().String("value-gt", "", "filter query by value greater than the string. string=<Value>")
+	cmd.Flags().String("value-lt", "", "filter query by value less than the string. string=<Value>")
+	cmd.Flags().String("value-gteq", "", "filter query by value greater than or equal to the string. string=<Value>")
+	cmd.Flags().String("value-lteq", "", "filter query by value less than or equal to the string. string=<Value>")
+	cmd.Flags().StringVar(&tzString, "tz", "", "time zone of output and of from/until without offset. string=<Local|UTC|Time zone name such as Asia/Tokyo>")
+	cmd.Flags().StringVar(&aggregateString, "aggregate", "", "aggregate values in each interval. string=<mean|min|max|sum|count|first|last|delta>")
+	cmd.Flags().StringVar(&intervalString, "interval", "", "interval of aggregation. string=<Duration such as 15m, 1h or 1d>")
+	cmd.Flags().StringVar(&recordString, "record", "", "record FIAP exchanges to cassette file. string=<filepath>")
+	cmd.Flags().StringVar(&replayString, "replay", "", "replay FIAP exchanges from cassette file instead of connecting to URL. string=<filepath>")
+	cmd.Flags().StringVar(&cacheDirString, "cache-dir", "", "cache fetched values in the directory and fetch only the missing tail (select none only). string=<dirpath>")
+	cmd.Flags().BoolVar(&cacheRefresh, "cache-refresh", false, "discard the cached values of the point before fetching")
 
 	return cmd
 }
 
-func executeFetch(connectionURL string, id string, fromDate, untilDate *time.Time, selectType model.SelectType) ([]byte, error, error) {
+// parseSelect は--selectで指定された文字列をSelectTypeに変換する
+func parseSelect(s string) (model.SelectType, error) {
+	switch s {
+	case "max":
+		return model.SelectTypeMaximum, nil
+	case "min":
+		return model.SelectTypeMinimum, nil
+	case "none":
+		return model.SelectTypeNone, nil
+	}
+	return model.SelectTypeNone, errors.New("select type allows only max, min, or none")
+}
+
+// parseResample は--aggregateと--intervalから集約の設定を作成する。どちらも空の場合はnilを返す
+func parseResample(aggregate, interval string, location *time.Location) (*series.ResampleOption, error) {
+	if aggregate == "" && interval == "" {
+		return nil, nil
+	}
+	if aggregate == "" || interval == "" {
+		return nil, errors.New("aggregate and interval must be specified together")
+	}
+	resample := &series.ResampleOption{Location: time.Local}
+	if location != nil {
+		resample.Location = location
+	}
+	var errs []error
+	if a, err := series.ParseAggregation(aggregate); err == nil {
+		resample.Aggregation = a
+	} else {
+		errs = append(errs, err)
+	}
+	if d, err := series.ParseInterval(interval); err == nil {
+		resample.Interval = d
+	} else {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return resample, nil
+}
+
+// parseValueCondition は--value-eqなどの値の条件のフラグから値の条件を作成する。指定されたフラグがない場合はnilを返す
+func parseValueCondition(flags *pflag.FlagSet) *model.ValueCondition {
+	var value *model.ValueCondition
+	for _, c := range []struct {
+		name string
+		dst  func(*model.ValueCondition) **string
+	}{
+		{"value-eq", func(v *model.ValueCondition) **string { return &v.Eq }},
+		{"value-neq", func(v *model.ValueCondition) **string { return &v.Neq }},
+		{"value-gt", func(v *model.ValueCondition) **string { return &v.Gt }},
+		{"value-lt", func(v *model.ValueCondition) **string { return &v.Lt }},
+		{"value-gteq", func(v *model.ValueCondition) **string { return &v.Gteq }},
+		{"value-lteq", func(v *model.ValueCondition) **string { return &v.Lteq }},
+	} {
+		// 空文字が指定された場合もfiap.ValidateKeysでエラーにするため、指定されたかどうかで判定する
+		if !flags.Changed(c.name) {
+			continue
+		}
+		if value == nil {
+			value = &model.ValueCondition{}
+		}
+		s, _ := flags.GetString(c.name)
+		*c.dst(value) = &s
+	}
+	return value
+}
+
+// formatValueCondition はデバッグ出力のために値の条件を文字列にする
+func formatValueCondition(value *model.ValueCondition) string {
+	var conditions []string
+	for _, c := range []struct {
+		name  string
+		value *string
+	}{
+		{"eq", value.Eq}, {"neq", value.Neq}, {"gt", value.Gt}, {"lt", value.Lt}, {"gteq", value.Gteq}, {"lteq", value.Lteq},
+	} {
+		if c.value != nil {
+			conditions = append(conditions, c.name+"="+*c.value)
+		}
+	}
+	return strings.Join(conditions, " ")
+}
+
+// validateKeys はidsとfrom、until、value、selectから作成されるkeyをfiap.ValidateKeysで検証する
+func validateKeys(ids []string, fromDate, untilDate *time.Time, value *model.ValueCondition, selectType model.SelectType) error {
+	keys := make([]model.UserInputKey, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, model.UserInputKey{
+			ID:              id,
+			Gteq:            fromDate,
+			Lteq:            untilDate,
+			Value:           value,
+			MinMaxIndicator: selectType,
+		})
+	}
+	if err := fiap.ValidateKeysWithLayout(keys, time.RFC3339Nano); err != nil {
+		return errors.Wrap(err, "invalid arguments")
+	}
+	return nil
+}
+
+// cacheOption はfetchコマンドのキャッシュの設定
+type cacheOption struct {
+	dir     string
+	refresh bool
+}
+
+// contextFetcher はfiap.ResultFetcherのcontextを受け取るメソッドにctxを渡して呼び出すfiap.Fetcher
+type contextFetcher struct {
+	fiap.Fetcher
+	ctx     context.Context
+	fetcher fiap.ResultFetcher
+}
+
+func (f *contextFetcher) FetchByIdsWithKey(key model.UserInputKeyNoID, ids ...string) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
+	return fromResult(f.fetcher.FetchByIdsWithKeyContext(f.ctx, key, ids...))
+}
+
+func (f *contextFetcher) FetchLatest(fromDate *time.Time, untilDate *time.Time, ids ...string) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
+	return fromResult(f.fetcher.FetchLatestContext(f.ctx, fromDate, untilDate, ids...))
+}
+
+func (f *contextFetcher) FetchOldest(fromDate *time.Time, untilDate *time.Time, ids ...string) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
+	return fromResult(f.fetcher.FetchOldestContext(f.ctx, fromDate, untilDate, ids...))
+}
+
+func (f *contextFetcher) FetchDateRange(fromDate *time.Time, untilDate *time.Time, ids ...string) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
+	return fromResult(f.fetcher.FetchDateRangeContext(f.ctx, fromDate, untilDate, ids...))
+}
+
+// fromResult はFetchResultをfiap.Fetcherのメソッドの戻り値に変換する
+func fromResult(result *fiap.FetchResult, err error) (map[string](model.ProcessedPointSet), map[string]([]model.Value), *model.Error, error) {
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return result.PointSets, result.Points, result.FIAPError, nil
+}
+
+// executeFetch はFIAPサーバからidのデータを取得し、JSONに変換する。クライアントがfiap.ResultFetcherを実装している場合、ctxがキャンセルされると通信を中断する
+func executeFetch(ctx context.Context, connectionURL string, id string, fromDate, untilDate *time.Time, value *model.ValueCondition, selectType model.SelectType, resample *series.ResampleOption, location *time.Location, cacheOpt *cacheOption, opts ...fiap.Option) ([]byte, error, error) {
 	var result struct {
 		PointSets map[string](model.ProcessedPointSet) `json:"point_sets,omitempty"`
 		Points    map[string]([]model.Value)           `json:"points,omitempty"`
 	}
 	var fiapError error = nil
 
-	fetchClient := createFetchClient(connectionURL)
-	switch selectType {
-	case model.SelectTypeMaximum:
+	fetchClient := createFetchClient(connectionURL, append([]fiap.Option{fiap.WithLocation(location)}, opts...)...)
+	if rf, ok := fetchClient.(fiap.ResultFetcher); ok {
+		fetchClient = &contextFetcher{Fetcher: fetchClient, ctx: ctx, fetcher: rf}
+	}
+	if cacheOpt != nil {
+		c, err := cache.New(fetchClient, cacheOpt.dir, cache.WithLocation(location))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to open cache")
+		}
+		if cacheOpt.refresh {
+			if err := c.Invalidate(id); err != nil {
+				return nil, nil, err
+			}
+		}
+		fetchClient = c
+	}
+	switch {
+	case value != nil:
+		if pointSets, points, fiapErr, err := fetchClient.FetchByIdsWithKey(model.UserInputKeyNoID{Value: value, MinMaxIndicator: selectType}, id); err == nil {
+			result.PointSets = pointSets
+			result.Points = points
+			if fiapErr != nil {
+				fiapError = fiap.NewFIAPError(fiapErr)
+			}
+		} else {
+			return nil, nil, errors.Wrapf(err, "failed to fetch from %s", connectionURL)
+		}
+	case selectType == model.SelectTypeMaximum:
 		if pointSets, points, fiapErr, err := fetchClient.FetchLatest(fromDate, untilDate, id); err == nil {
 			result.PointSets = pointSets
 			result.Points = points
 			if fiapErr != nil {
-				fiapError = errors.Newf("fiap error: type %s, value %s", fiapErr.Type, fiapErr.Value)
+				fiapError = fiap.NewFIAPError(fiapErr)
 			}
 		} else {
 			return nil, nil, errors.Wrapf(err, "failed to fetch from %s", connectionURL)
 		}
-	case model.SelectTypeMinimum:
+	case selectType == model.SelectTypeMinimum:
 		if pointSets, points, fiapErr, err := fetchClient.FetchOldest(fromDate, untilDate, id); err == nil {
 			result.PointSets = pointSets
 			result.Points = points
 			if fiapErr != nil {
-				fiapError = errors.Newf("fiap error: type %s, value %s", fiapErr.Type, fiapErr.Value)
+				fiapError = fiap.NewFIAPError(fiapErr)
 			}
 		} else {
 			return nil, nil, errors.Wrapf(err, "failed to fetch from %s", connectionURL)
 		}
-	case model.SelectTypeNone:
+	case selectType == model.SelectTypeNone:
 		if pointSets, points, fiapErr, err := fetchClient.FetchDateRange(fromDate, untilDate, id); err == nil {
 			result.PointSets = pointSets
 			result.Points = points
 			if fiapErr != nil {
-				fiapError = errors.Newf("fiap error: type %s, value %s", fiapErr.Type, fiapErr.Value)
+				fiapError = fiap.NewFIAPError(fiapErr)
 			}
 		} else {
 			return nil, nil, errors.Wrapf(err, "failed to fetch from %s", connectionURL)
 		}
 	}
 
+	if resample != nil && result.Points != nil {
+		if points, err := series.ResamplePoints(result.Points, *resample); err == nil {
+			result.Points = points
+		} else {
+			return nil, fiapError, errors.Wrap(err, "failed to aggregate values")
+		}
+	}
+
 	if b, err := marshalJSON(&result); err == nil {
 		return b, fiapError, nil
 	} else {
@@ -1,14 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"io"
+	"log/slog"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/cassette"
 	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
 	"github.com/cockroachdb/errors"
 )
 
@@ -28,6 +34,8 @@ type fetchFuncArguments struct {
 	connectionURL string
 	fromDate      *time.Time
 	untilDate     *time.Time
+	value         *model.ValueCondition
+	selectType    model.SelectType
 	ids           []string
 }
 
@@ -39,18 +47,30 @@ type fetchFuncResults struct {
 
 type mockFetchClient struct {
 	ConnectionURL string
+	Location      *time.Location
+	Logger        *slog.Logger
+	HTTPClient    *http.Client
+	TimeLayout    string
 
-	failLatest, failOldest, failDateRange bool
+	failLatest, failOldest, failDateRange, failByIdsWithKey bool
 
 	actualArguments fetchFuncArguments
 	results         fetchFuncResults
 }
 
-func mockCreateFetchClient(connectionURL string) fiap.Fetcher {
-	mockClient.ConnectionURL = connectionURL
+func mockCreateFetchClient(connectionURL string, opts ...fiap.Option) fiap.Fetcher {
+	// 設定の適用結果を確認するため、実際のクライアントに設定を適用する
+	client := fiap.NewFetchClient(connectionURL, opts...)
+	mockClient.ConnectionURL = client.ConnectionURL
+	mockClient.Location = client.Location
+	mockClient.Logger = client.Logger
+	mockClient.HTTPClient = client.HTTPClient
+	mockClient.TimeLayout = client.TimeLayout
 	mockClient.actualArguments.connectionURL = ""
 	mockClient.actualArguments.fromDate = nil
 	mockClient.actualArguments.untilDate = nil
+	mockClient.actualArguments.value = nil
+	mockClient.actualArguments.selectType = model.SelectTypeNone
 	mockClient.actualArguments.ids = nil
 	return mockClient
 }
@@ -64,7 +84,15 @@ func (f *mockFetchClient) FetchOnce(keys []model.UserInputKey, option *model.Fet
 }
 
 func (f *mockFetchClient) FetchByIdsWithKey(key model.UserInputKeyNoID, ids ...string) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
-	return nil, nil, nil, errors.New("unimplemented")
+	if f.failByIdsWithKey {
+		return nil, nil, nil, errors.New("test FetchByIdsWithKey error")
+	} else {
+		f.actualArguments.connectionURL = f.ConnectionURL
+		f.actualArguments.value = key.Value
+		f.actualArguments.selectType = key.MinMaxIndicator
+		f.actualArguments.ids = ids
+		return f.results.pointSets, f.results.points, f.results.fiapErr, nil
+	}
 }
 
 func (f *mockFetchClient) FetchLatest(fromDate *time.Time, untilDate *time.Time, ids ...string) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
@@ -151,6 +179,8 @@ func resetActualValues() {
 	mockClient.actualArguments.connectionURL = ""
 	mockClient.actualArguments.fromDate = nil
 	mockClient.actualArguments.untilDate = nil
+	mockClient.actualArguments.value = nil
+	mockClient.actualArguments.selectType = model.SelectTypeNone
 	mockClient.actualArguments.ids = nil
 	mockFile.fileName = ""
 	mockFile.opened = false
@@ -177,7 +207,7 @@ func TestFetchCommandRun(t *testing.T) {
 	t.Run("Normal", func(t *testing.T) {
 		mockClient.results.pointSets = map[string](model.ProcessedPointSet){}
 		mockClient.results.points = map[string]([]model.Value){
-			"test_id": []model.Value{
+			"http://test.url/test_id": []model.Value{
 				{Time: time.Date(2004, 4, 30, 12, 15, 3, 0, tokyoTz), Value: "100"},
 				{Time: time.Date(2004, 5, 2, 9, 0, 15, 0, time.UTC), Value: "200"},
 				{Time: time.Date(2004, 12, 1, 0, 0, 0, 0, newYorkTz), Value: "300"},
@@ -188,7 +218,7 @@ func TestFetchCommandRun(t *testing.T) {
 		t.Run("WithoutFileOutput", func(t *testing.T) {
 			mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = true, true, true
 
-			expectedOut := `{"points":{"test_id":[{"time":"2004-04-30T12:15:03+09:00","value":"100"},{"time":"2004-05-02T09:00:15Z","value":"200"},{"time":"2004-12-01T00:00:00-04:00","value":"300"}]}}
+			expectedOut := `{"points":{"http://test.url/test_id":[{"time":"2004-04-30T12:15:03+09:00","value":"100"},{"time":"2004-05-02T09:00:15Z","value":"200"},{"time":"2004-12-01T00:00:00-04:00","value":"300"}]}}
 `
 			expectedErrOut := ""
 
@@ -196,7 +226,7 @@ func TestFetchCommandRun(t *testing.T) {
 				mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = false, true, true
 
 				t.Run("LeastFlags", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "http://test.url", "http://test.url/test_id"}
 
 					resetActualValues()
 					if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
@@ -222,14 +252,17 @@ func TestFetchCommandRun(t *testing.T) {
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
+					if mockClient.Logger != nil {
+						t.Error("assertion error of logger")
+					}
 				})
 				t.Run("ExplicitSelectFlag", func(t *testing.T) {
 					t.Run("Short", func(t *testing.T) {
-						os.Args = []string{"go-fiap-client", "fetch", "-s", "max", "http://test.url", "test_id"}
+						os.Args = []string{"go-fiap-client", "fetch", "-s", "max", "http://test.url", "http://test.url/test_id"}
 
 						resetActualValues()
 						if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
@@ -255,13 +288,13 @@ func TestFetchCommandRun(t *testing.T) {
 						} else {
 							if len(mockClient.actualArguments.ids) != 1 {
 								t.Error("assertion error of id")
-							} else if mockClient.actualArguments.ids[0] != "test_id" {
+							} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 								t.Error("assertion error of id")
 							}
 						}
 					})
 					t.Run("Long", func(t *testing.T) {
-						os.Args = []string{"go-fiap-client", "fetch", "--select", "max", "http://test.url", "test_id"}
+						os.Args = []string{"go-fiap-client", "fetch", "--select", "max", "http://test.url", "http://test.url/test_id"}
 
 						resetActualValues()
 						if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
@@ -287,7 +320,7 @@ func TestFetchCommandRun(t *testing.T) {
 						} else {
 							if len(mockClient.actualArguments.ids) != 1 {
 								t.Error("assertion error of id")
-							} else if mockClient.actualArguments.ids[0] != "test_id" {
+							} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 								t.Error("assertion error of id")
 							}
 						}
@@ -295,9 +328,9 @@ func TestFetchCommandRun(t *testing.T) {
 				})
 				t.Run("Debug", func(t *testing.T) {
 					t.Run("Short", func(t *testing.T) {
-						os.Args = []string{"go-fiap-client", "fetch", "-d", "http://test.url", "test_id"}
+						os.Args = []string{"go-fiap-client", "fetch", "-d", "http://test.url", "http://test.url/test_id"}
 						expectedDebugPrint := `url: http://test.url
-id: test_id
+id: http://test.url/test_id
 debug: true
 output: 
 select: maximum
@@ -329,15 +362,21 @@ until: <nil>
 						} else {
 							if len(mockClient.actualArguments.ids) != 1 {
 								t.Error("assertion error of id")
-							} else if mockClient.actualArguments.ids[0] != "test_id" {
+							} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 								t.Error("assertion error of id")
 							}
 						}
+						if mockClient.Logger == nil || !mockClient.Logger.Enabled(context.Background(), slog.LevelDebug) {
+							t.Error("assertion error of logger")
+						}
+						if tools.GetLogLevel() != tools.LogLevelError {
+							t.Error("assertion error of global log level")
+						}
 					})
 					t.Run("Long", func(t *testing.T) {
-						os.Args = []string{"go-fiap-client", "fetch", "--debug", "http://test.url", "test_id"}
+						os.Args = []string{"go-fiap-client", "fetch", "--debug", "http://test.url", "http://test.url/test_id"}
 						expectedDebugPrint := `url: http://test.url
-id: test_id
+id: http://test.url/test_id
 debug: true
 output: 
 select: maximum
@@ -369,14 +408,14 @@ until: <nil>
 						} else {
 							if len(mockClient.actualArguments.ids) != 1 {
 								t.Error("assertion error of id")
-							} else if mockClient.actualArguments.ids[0] != "test_id" {
+							} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 								t.Error("assertion error of id")
 							}
 						}
 					})
 				})
 				t.Run("WithFrom", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "--from", "2012-01-01T00:00:00+09:00", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "--from", "2012-01-01T00:00:00+09:00", "http://test.url", "http://test.url/test_id"}
 					expectedFrom := time.Date(2012, 1, 1, 0, 0, 0, 0, tokyoTz)
 
 					resetActualValues()
@@ -405,13 +444,69 @@ until: <nil>
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
 				})
+				t.Run("WithTz", func(t *testing.T) {
+					os.Args = []string{"go-fiap-client", "fetch", "--tz", "UTC", "--from", "2012-01-01T00:00:00", "--until", "2012-12-31T23:59:59+09:00", "http://test.url", "http://test.url/test_id"}
+					expectedFrom := time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)
+					expectedUntil := time.Date(2012, 12, 31, 23, 59, 59, 0, tokyoTz)
+
+					resetActualValues()
+					if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+						t.Error("failed to run command")
+					}
+					if mockOut.String() != expectedOut {
+						t.Error("assertion error of stdout")
+					}
+					if mockErrOut.String() != expectedErrOut {
+						t.Error("assertion error of stderr")
+					}
+					if mockClient.Location != time.UTC {
+						t.Error("assertion error of location")
+					}
+					if mockClient.actualArguments.fromDate == nil {
+						t.Error("assertion error of from date")
+					} else if !mockClient.actualArguments.fromDate.Equal(expectedFrom) {
+						t.Error("assertion error of from date")
+					}
+					if mockClient.actualArguments.untilDate == nil {
+						t.Error("assertion error of until date")
+					} else if !mockClient.actualArguments.untilDate.Equal(expectedUntil) {
+						t.Error("assertion error of until date")
+					}
+				})
+				t.Run("WithTimeExpression", func(t *testing.T) {
+					os.Args = []string{"go-fiap-client", "fetch", "--from=now-1d", "--until", "-1h", "http://test.url", "http://test.url/test_id"}
+					now := time.Date(2012, 6, 1, 12, 0, 0, 0, time.UTC)
+					expectedFrom := time.Date(2012, 5, 31, 12, 0, 0, 0, time.UTC)
+					expectedUntil := time.Date(2012, 6, 1, 11, 0, 0, 0, time.UTC)
+					originalTimeNow := timeNow
+					timeNow = func() time.Time { return now }
+					defer func() { timeNow = originalTimeNow }()
+
+					resetActualValues()
+					if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+						t.Error("failed to run command")
+					}
+					if mockOut.String() != expectedOut {
+						t.Error("assertion error of stdout")
+					}
+					if mockClient.actualArguments.fromDate == nil {
+						t.Error("assertion error of from date")
+					} else if !mockClient.actualArguments.fromDate.Equal(expectedFrom) {
+						t.Error("assertion error of from date")
+					}
+					if mockClient.actualArguments.untilDate == nil {
+						t.Error("assertion error of until date")
+					} else if !mockClient.actualArguments.untilDate.Equal(expectedUntil) {
+						t.Error("assertion error of until date")
+					}
+				})
 				t.Run("WithUntil", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "--until", "2012-12-31T23:59:59+09:00", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "--until", "2012-12-31T23:59:59+09:00", "http://test.url", "http://test.url/test_id"}
 					expectedUntil := time.Date(2012, 12, 31, 23, 59, 59, 0, tokyoTz)
 
 					resetActualValues()
@@ -440,7 +535,7 @@ until: <nil>
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
@@ -450,7 +545,7 @@ until: <nil>
 				mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = true, false, true
 
 				t.Run("Short", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "-s", "min", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "-s", "min", "http://test.url", "http://test.url/test_id"}
 
 					resetActualValues()
 					if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
@@ -476,13 +571,13 @@ until: <nil>
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
 				})
 				t.Run("Long", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "--select", "min", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "--select", "min", "http://test.url", "http://test.url/test_id"}
 
 					resetActualValues()
 					if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
@@ -508,7 +603,7 @@ until: <nil>
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
@@ -519,7 +614,7 @@ until: <nil>
 				mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = true, true, false
 
 				t.Run("Short", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "-s", "none", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "-s", "none", "http://test.url", "http://test.url/test_id"}
 
 					resetActualValues()
 					if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
@@ -545,13 +640,13 @@ until: <nil>
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
 				})
 				t.Run("Long", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "--select", "none", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "--select", "none", "http://test.url", "http://test.url/test_id"}
 
 					resetActualValues()
 					if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
@@ -577,12 +672,30 @@ until: <nil>
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
 				})
+				t.Run("WithAggregate", func(t *testing.T) {
+					os.Args = []string{"go-fiap-client", "fetch", "-s", "none", "--aggregate", "sum", "--interval", "1d", "http://test.url", "http://test.url/test_id"}
+					expectedAggregateOut := `{"points":{"http://test.url/test_id":[{"time":"2004-04-30T00:00:00Z","value":"100"},{"time":"2004-05-02T00:00:00Z","value":"200"},{"time":"2004-12-01T00:00:00Z","value":"300"}]}}
+`
+					originalLocal := time.Local
+					time.Local = time.UTC
+					defer func() { time.Local = originalLocal }()
 
+					resetActualValues()
+					if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+						t.Error("failed to run command")
+					}
+					if mockOut.String() != expectedAggregateOut {
+						t.Error("assertion error of stdout")
+					}
+					if mockErrOut.String() != expectedErrOut {
+						t.Error("assertion error of stderr")
+					}
+				})
 			})
 		})
 		t.Run("WithFileOutput", func(t *testing.T) {
@@ -590,12 +703,12 @@ until: <nil>
 			mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, false, false
 
 			expectedOut := ""
-			expectedFileOut := `{"points":{"test_id":[{"time":"2004-04-30T12:15:03+09:00","value":"100"},{"time":"2004-05-02T09:00:15Z","value":"200"},{"time":"2004-12-01T00:00:00-04:00","value":"300"}]}}`
+			expectedFileOut := `{"points":{"http://test.url/test_id":[{"time":"2004-04-30T12:15:03+09:00","value":"100"},{"time":"2004-05-02T09:00:15Z","value":"200"},{"time":"2004-12-01T00:00:00-04:00","value":"300"}]}}`
 			expectedErrOut := ""
 
 			t.Run("LeastFlags", func(t *testing.T) {
 				t.Run("Short", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "http://test.url/test_id"}
 
 					resetActualValues()
 					if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
@@ -621,7 +734,7 @@ until: <nil>
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
@@ -639,7 +752,7 @@ until: <nil>
 					}
 				})
 				t.Run("Long", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "--output", "./test/file.ext", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "--output", "./test/file.ext", "http://test.url", "http://test.url/test_id"}
 
 					resetActualValues()
 					if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
@@ -665,7 +778,7 @@ until: <nil>
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
@@ -685,11 +798,11 @@ until: <nil>
 			})
 			t.Run("FullFlags", func(t *testing.T) {
 				t.Run("Short", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "-o", "/abs/test/file.ext", "-s", "max", "-d", "--from", "2012-12-31T23:00:00+09:00", "--until", "2012-12-31T23:59:59+09:00", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "-o", "/abs/test/file.ext", "-s", "max", "-d", "--from", "2012-12-31T23:00:00+09:00", "--until", "2012-12-31T23:59:59+09:00", "http://test.url", "http://test.url/test_id"}
 					expectedFrom := time.Date(2012, 12, 31, 23, 0, 0, 0, tokyoTz)
 					expectedUntil := time.Date(2012, 12, 31, 23, 59, 59, 0, tokyoTz)
 					expectedDebugPrint := `url: http://test.url
-id: test_id
+id: http://test.url/test_id
 debug: true
 output: /abs/test/file.ext
 select: maximum
@@ -725,7 +838,7 @@ until: 2012-12-31 23:59:59 +0900 +0900
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
@@ -743,11 +856,11 @@ until: 2012-12-31 23:59:59 +0900 +0900
 					}
 				})
 				t.Run("Long", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "--until", "2012-12-31T23:59:59+09:00", "--debug", "--output", "/abs/test/file.ext", "--from", "2012-12-31T23:00:00+09:00", "--select", "max", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "--until", "2012-12-31T23:59:59+09:00", "--debug", "--output", "/abs/test/file.ext", "--from", "2012-12-31T23:00:00+09:00", "--select", "max", "http://test.url", "http://test.url/test_id"}
 					expectedFrom := time.Date(2012, 12, 31, 23, 0, 0, 0, tokyoTz)
 					expectedUntil := time.Date(2012, 12, 31, 23, 59, 59, 0, tokyoTz)
 					expectedDebugPrint := `url: http://test.url
-id: test_id
+id: http://test.url/test_id
 debug: true
 output: /abs/test/file.ext
 select: maximum
@@ -783,7 +896,7 @@ until: 2012-12-31 23:59:59 +0900 +0900
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
@@ -801,11 +914,11 @@ until: 2012-12-31 23:59:59 +0900 +0900
 					}
 				})
 				t.Run("EqualSyntax", func(t *testing.T) {
-					os.Args = []string{"go-fiap-client", "fetch", "--until=2012-12-31T23:59:59+09:00", "-d", "-o=/abs/test/spaced file.ext", "--from=2012-12-31T23:00:00+09:00", "--select=max", "http://test.url", "test_id"}
+					os.Args = []string{"go-fiap-client", "fetch", "--until=2012-12-31T23:59:59+09:00", "-d", "-o=/abs/test/spaced file.ext", "--from=2012-12-31T23:00:00+09:00", "--select=max", "http://test.url", "http://test.url/test_id"}
 					expectedFrom := time.Date(2012, 12, 31, 23, 0, 0, 0, tokyoTz)
 					expectedUntil := time.Date(2012, 12, 31, 23, 59, 59, 0, tokyoTz)
 					expectedDebugPrint := `url: http://test.url
-id: test_id
+id: http://test.url/test_id
 debug: true
 output: /abs/test/spaced file.ext
 select: maximum
@@ -841,7 +954,7 @@ until: 2012-12-31 23:59:59 +0900 +0900
 					} else {
 						if len(mockClient.actualArguments.ids) != 1 {
 							t.Error("assertion error of id")
-						} else if mockClient.actualArguments.ids[0] != "test_id" {
+						} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 							t.Error("assertion error of id")
 						}
 					}
@@ -871,12 +984,25 @@ Usage:
   go-fiap-client fetch [flags] URL (POINT_ID | POINTSET_ID)
 
 Flags:
-  -d, --debug           set output log level to debug
-      --from string     filter query from datetime string=<Datetime in RFC 3339 format>
-  -h, --help            help for fetch
-  -o, --output string   specify output file path. string=<filepath>
-  -s, --select string   fiap select option. string=<max|min|none> (default "max")
-      --until string    filter query until datetime string=<Datetime in RFC 3339 format>
+      --aggregate string    aggregate values in each interval. string=<mean|min|max|sum|count|first|last|delta>
+      --cache-dir string    cache fetched values in the directory and fetch only the missing tail (select none only). string=<dirpath>
+      --cache-refresh       discard the cached values of the point before fetching
+  -d, --debug               set output log level to debug
+      --from string         filter query from datetime string=<Datetime in RFC 3339 format, date, unix time or time expression such as -24h, now-7d, today>
+  -h, --help                help for fetch
+      --interval string     interval of aggregation. string=<Duration such as 15m, 1h or 1d>
+  -o, --output string       specify output file path. string=<filepath>
+      --record string       record FIAP exchanges to cassette file. string=<filepath>
+      --replay string       replay FIAP exchanges from cassette file instead of connecting to URL. string=<filepath>
+  -s, --select string       fiap select option. string=<max|min|none> (default "max")
+      --tz string           time zone of output and of from/until without offset. string=<Local|UTC|Time zone name such as Asia/Tokyo>
+      --until string        filter query until datetime string=<Datetime in RFC 3339 format, date, unix time or time expression such as -24h, now-7d, today>
+      --value-eq string     filter query by value equal to the string (select is none unless --select is specified). string=<Value>
+      --value-gt string     filter query by value greater than the string. string=<Value>
+      --value-gteq string   filter query by value greater than or equal to the string. string=<Value>
+      --value-lt string     filter query by value less than the string. string=<Value>
+      --value-lteq string   filter query by value less than or equal to the string. string=<Value>
+      --value-neq string    filter query by value not equal to the string. string=<Value>
 `
 		expectedErrOut := ""
 
@@ -914,7 +1040,7 @@ Flags:
 		mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, false, false
 		mockClient.results.pointSets = map[string](model.ProcessedPointSet){}
 		mockClient.results.points = map[string]([]model.Value){
-			"test_id": {
+			"http://test.url/test_id": {
 				{Time: time.Date(2004, 4, 30, 12, 15, 3, 0, tokyoTz), Value: "100"},
 				{Time: time.Date(2004, 5, 2, 9, 0, 15, 0, time.UTC), Value: "200"},
 				{Time: time.Date(2004, 12, 1, 0, 0, 0, 0, newYorkTz), Value: "300"},
@@ -926,12 +1052,25 @@ Flags:
   go-fiap-client fetch [flags] URL (POINT_ID | POINTSET_ID)
 
 Flags:
-  -d, --debug           set output log level to debug
-      --from string     filter query from datetime string=<Datetime in RFC 3339 format>
-  -h, --help            help for fetch
-  -o, --output string   specify output file path. string=<filepath>
-  -s, --select string   fiap select option. string=<max|min|none> (default "max")
-      --until string    filter query until datetime string=<Datetime in RFC 3339 format>
+      --aggregate string    aggregate values in each interval. string=<mean|min|max|sum|count|first|last|delta>
+      --cache-dir string    cache fetched values in the directory and fetch only the missing tail (select none only). string=<dirpath>
+      --cache-refresh       discard the cached values of the point before fetching
+  -d, --debug               set output log level to debug
+      --from string         filter query from datetime string=<Datetime in RFC 3339 format, date, unix time or time expression such as -24h, now-7d, today>
+  -h, --help                help for fetch
+      --interval string     interval of aggregation. string=<Duration such as 15m, 1h or 1d>
+  -o, --output string       specify output file path. string=<filepath>
+      --record string       record FIAP exchanges to cassette file. string=<filepath>
+      --replay string       replay FIAP exchanges from cassette file instead of connecting to URL. string=<filepath>
+  -s, --select string       fiap select option. string=<max|min|none> (default "max")
+      --tz string           time zone of output and of from/until without offset. string=<Local|UTC|Time zone name such as Asia/Tokyo>
+      --until string        filter query until datetime string=<Datetime in RFC 3339 format, date, unix time or time expression such as -24h, now-7d, today>
+      --value-eq string     filter query by value equal to the string (select is none unless --select is specified). string=<Value>
+      --value-gt string     filter query by value greater than the string. string=<Value>
+      --value-gteq string   filter query by value greater than or equal to the string. string=<Value>
+      --value-lt string     filter query by value less than the string. string=<Value>
+      --value-lteq string   filter query by value less than or equal to the string. string=<Value>
+      --value-neq string    filter query by value not equal to the string. string=<Value>
 
 `
 
@@ -941,7 +1080,7 @@ Flags:
 			expectedError := "select type allows only max, min, or none"
 
 			t.Run("Short", func(t *testing.T) {
-				os.Args = []string{"go-fiap-client", "fetch", "-s", "aaaaa", "http://test.url", "test_id"}
+				os.Args = []string{"go-fiap-client", "fetch", "-s", "aaaaa", "http://test.url", "http://test.url/test_id"}
 
 				resetActualValues()
 				if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
@@ -957,7 +1096,7 @@ Flags:
 				}
 			})
 			t.Run("Long", func(t *testing.T) {
-				os.Args = []string{"go-fiap-client", "fetch", "--select", "aaaaa", "http://test.url", "test_id"}
+				os.Args = []string{"go-fiap-client", "fetch", "--select", "aaaaa", "http://test.url", "http://test.url/test_id"}
 
 				resetActualValues()
 				if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
@@ -974,11 +1113,11 @@ Flags:
 			})
 		})
 		t.Run("InvalidFrom", func(t *testing.T) {
-			expectedError := "from allows only datetime in RFC3339 format"
+			expectedError := "from allows only datetime, date, unix time or time expression"
 
 			t.Run("Format", func(t *testing.T) {
-				os.Args = []string{"go-fiap-client", "fetch", "--from", "2012/01/01 00:00:00 +0900", "http://test.url", "test_id"}
-				expectedErrOut := `Error: from allows only datetime in RFC3339 format: parsing time "2012/01/01 00:00:00 +0900" as "2006-01-02T15:04:05Z07:00": cannot parse "/01/01 00:00:00 +0900" as "-"
+				os.Args = []string{"go-fiap-client", "fetch", "--from", "2012/01/01 00:00:00 +0900", "http://test.url", "http://test.url/test_id"}
+				expectedErrOut := `Error: from allows only datetime, date, unix time or time expression: parsing time "2012/01/01 00:00:00 +0900" as "2006-01-02T15:04:05Z07:00": cannot parse "/01/01 00:00:00 +0900" as "-"
 `
 
 				resetActualValues()
@@ -995,8 +1134,8 @@ Flags:
 				}
 			})
 			t.Run("Date", func(t *testing.T) {
-				os.Args = []string{"go-fiap-client", "fetch", "--from", "2012-02-30T23:59:59+09:00", "http://test.url", "test_id"}
-				expectedErrOut := `Error: from allows only datetime in RFC3339 format: parsing time "2012-02-30T23:59:59+09:00": day out of range
+				os.Args = []string{"go-fiap-client", "fetch", "--from", "2012-02-30T23:59:59+09:00", "http://test.url", "http://test.url/test_id"}
+				expectedErrOut := `Error: from allows only datetime, date, unix time or time expression: parsing time "2012-02-30T23:59:59+09:00": day out of range
 `
 
 				resetActualValues()
@@ -1014,11 +1153,11 @@ Flags:
 			})
 		})
 		t.Run("InvalidUntil", func(t *testing.T) {
-			expectedError := "until allows only datetime in RFC3339 format"
+			expectedError := "until allows only datetime, date, unix time or time expression"
 
 			t.Run("Format", func(t *testing.T) {
-				os.Args = []string{"go-fiap-client", "fetch", "--until", "Dec 31, 2012 11:59:59 PM JST", "http://test.url", "test_id"}
-				expectedErrOut := `Error: until allows only datetime in RFC3339 format: parsing time "Dec 31, 2012 11:59:59 PM JST" as "2006-01-02T15:04:05Z07:00": cannot parse "Dec 31, 2012 11:59:59 PM JST" as "2006"
+				os.Args = []string{"go-fiap-client", "fetch", "--until", "Dec 31, 2012 11:59:59 PM JST", "http://test.url", "http://test.url/test_id"}
+				expectedErrOut := `Error: until allows only datetime, date, unix time or time expression: invalid time expression 'Dec 31, 2012 11:59:59 PM JST'
 `
 
 				resetActualValues()
@@ -1035,8 +1174,8 @@ Flags:
 				}
 			})
 			t.Run("Date", func(t *testing.T) {
-				os.Args = []string{"go-fiap-client", "fetch", "--until", "2012-02-29T24:00:00+09:00", "http://test.url", "test_id"}
-				expectedErrOut := `Error: until allows only datetime in RFC3339 format: parsing time "2012-02-29T24:00:00+09:00": hour out of range
+				os.Args = []string{"go-fiap-client", "fetch", "--until", "2012-02-29T24:00:00+09:00", "http://test.url", "http://test.url/test_id"}
+				expectedErrOut := `Error: until allows only datetime, date, unix time or time expression: parsing time "2012-02-29T24:00:00+09:00": hour out of range
 `
 
 				resetActualValues()
@@ -1053,6 +1192,60 @@ Flags:
 				}
 			})
 		})
+		t.Run("InvalidTz", func(t *testing.T) {
+			os.Args = []string{"go-fiap-client", "fetch", "--tz", "Mars/Olympus_Mons", "http://test.url", "http://test.url/test_id"}
+			expectedError := "unknown time zone 'Mars/Olympus_Mons'"
+
+			resetActualValues()
+			if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+				t.Error("expected to fail command but succeed")
+			} else if !strings.Contains(err.Error(), expectedError) {
+				t.Error("expected tz argument error but not")
+			}
+			if mockOut.String() != expectedOut {
+				t.Error("assertion error of stdout")
+			}
+		})
+		t.Run("InvalidAggregate", func(t *testing.T) {
+			t.Run("Type", func(t *testing.T) {
+				os.Args = []string{"go-fiap-client", "fetch", "--aggregate", "median", "--interval", "1h", "http://test.url", "http://test.url/test_id"}
+				expectedErrOut := `Error: unknown aggregation 'median', allows only mean, min, max, sum, count, first, last, delta
+`
+				expectedError := "unknown aggregation"
+
+				resetActualValues()
+				if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+					t.Error("expected to fail command but succeed")
+				} else if !strings.Contains(err.Error(), expectedError) {
+					t.Error("expected aggregate argument error but not")
+				}
+				if mockOut.String() != expectedOut {
+					t.Error("assertion error of stdout")
+				}
+				if mockErrOut.String() != expectedErrOut {
+					t.Error("assertion error of stderr")
+				}
+			})
+			t.Run("WithoutInterval", func(t *testing.T) {
+				os.Args = []string{"go-fiap-client", "fetch", "--aggregate", "mean", "http://test.url", "http://test.url/test_id"}
+				expectedErrOut := `Error: aggregate and interval must be specified together
+`
+				expectedError := "aggregate and interval must be specified together"
+
+				resetActualValues()
+				if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+					t.Error("expected to fail command but succeed")
+				} else if !strings.Contains(err.Error(), expectedError) {
+					t.Error("expected aggregate argument error but not")
+				}
+				if mockOut.String() != expectedOut {
+					t.Error("assertion error of stdout")
+				}
+				if mockErrOut.String() != expectedErrOut {
+					t.Error("assertion error of stderr")
+				}
+			})
+		})
 		t.Run("FewArguments", func(t *testing.T) {
 			os.Args = []string{"go-fiap-client", "fetch", "http://test.url"}
 			expectedErrOut := `Error: too few arguments
@@ -1073,7 +1266,7 @@ Flags:
 			}
 		})
 		t.Run("ManyArguments", func(t *testing.T) {
-			os.Args = []string{"go-fiap-client", "fetch", "http://test.url", "test_id", "extra"}
+			os.Args = []string{"go-fiap-client", "fetch", "http://test.url", "http://test.url/test_id", "extra"}
 			expectedErrOut := `Error: too many arguments
 `
 			expectedError := "too many arguments"
@@ -1091,18 +1284,39 @@ Flags:
 				t.Error("assertion error of stderr")
 			}
 		})
+		t.Run("InvalidKeys", func(t *testing.T) {
+			os.Args = []string{"go-fiap-client", "fetch", "--from", "2024-01-02T00:00:00Z", "--until", "2024-01-01T00:00:00Z", "http://test.url", "test id"}
+
+			resetActualValues()
+			if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+				t.Error("expected to fail command but succeed")
+			} else {
+				if !strings.Contains(err.Error(), "keys range is inverted") {
+					t.Error("expected inverted range error but not")
+				}
+				if !strings.Contains(err.Error(), "keys.ID is not a valid URI") {
+					t.Error("expected invalid id error but not")
+				}
+				if !errors.Is(err, fiap.ErrInvalidKey) {
+					t.Error("expected fiap.ErrInvalidKey but not")
+				}
+			}
+			if mockClient.actualArguments.connectionURL != "" {
+				t.Error("expected not to fetch but fetched")
+			}
+		})
 		t.Run("Multiple", func(t *testing.T) {
 			expectedSelectError := "select type allows only max, min, or none"
-			expectedFromError := "from allows only datetime in RFC3339 format"
-			expectedUntilError := "until allows only datetime in RFC3339 format"
+			expectedFromError := "from allows only datetime, date, unix time or time expression"
+			expectedUntilError := "until allows only datetime, date, unix time or time expression"
 			expectedFewError := "too few arguments"
 			expectedManyError := "too many arguments"
 
 			t.Run("Short", func(t *testing.T) {
-				os.Args = []string{"go-fiap-client", "fetch", "-s", "aaaaa", "--from", "bbbbb", "--until", "ccccc", "http://test.url", "test_id", "extra"}
+				os.Args = []string{"go-fiap-client", "fetch", "-s", "aaaaa", "--from", "bbbbb", "--until", "ccccc", "http://test.url", "http://test.url/test_id", "extra"}
 				expectedErrOut := `Error: select type allows only max, min, or none
-from allows only datetime in RFC3339 format: parsing time "bbbbb" as "2006-01-02T15:04:05Z07:00": cannot parse "bbbbb" as "2006"
-until allows only datetime in RFC3339 format: parsing time "ccccc" as "2006-01-02T15:04:05Z07:00": cannot parse "ccccc" as "2006"
+from allows only datetime, date, unix time or time expression: invalid time expression 'bbbbb', unknown anchor 'bbbbb'
+until allows only datetime, date, unix time or time expression: invalid time expression 'ccccc', unknown anchor 'ccccc'
 too many arguments
 `
 
@@ -1128,8 +1342,8 @@ too many arguments
 			t.Run("Long", func(t *testing.T) {
 				os.Args = []string{"go-fiap-client", "fetch", "--select", "aaaaa", "--from", "bbbbb", "--until", "ccccc", "http://test.url"}
 				expectedErrOut := `Error: select type allows only max, min, or none
-from allows only datetime in RFC3339 format: parsing time "bbbbb" as "2006-01-02T15:04:05Z07:00": cannot parse "bbbbb" as "2006"
-until allows only datetime in RFC3339 format: parsing time "ccccc" as "2006-01-02T15:04:05Z07:00": cannot parse "ccccc" as "2006"
+from allows only datetime, date, unix time or time expression: invalid time expression 'bbbbb', unknown anchor 'bbbbb'
+until allows only datetime, date, unix time or time expression: invalid time expression 'ccccc', unknown anchor 'ccccc'
 too few arguments
 `
 
@@ -1160,7 +1374,7 @@ too few arguments
 			mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = true, false, false
 			mockClient.results.pointSets = map[string](model.ProcessedPointSet){}
 			mockClient.results.points = map[string]([]model.Value){
-				"test_id": []model.Value{
+				"http://test.url/test_id": []model.Value{
 					{Time: time.Date(2004, 4, 30, 12, 15, 3, 0, tokyoTz), Value: "100"},
 					{Time: time.Date(2004, 5, 2, 9, 0, 15, 0, time.UTC), Value: "200"},
 					{Time: time.Date(2004, 12, 1, 0, 0, 0, 0, newYorkTz), Value: "300"},
@@ -1168,7 +1382,7 @@ too few arguments
 			}
 			mockClient.results.fiapErr = nil
 
-			os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "test_id"}
+			os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "http://test.url/test_id"}
 			expectedOut := ""
 			expectedErrOut := `Error: cannnot open file './test/file.ext': test file create error
 `
@@ -1191,7 +1405,7 @@ too few arguments
 			mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, false, false
 			mockClient.results.pointSets = map[string](model.ProcessedPointSet){}
 			mockClient.results.points = map[string]([]model.Value){
-				"test_id": []model.Value{
+				"http://test.url/test_id": []model.Value{
 					{Time: time.Date(2004, 4, 30, 12, 15, 3, 0, tokyoTz), Value: "100"},
 					{Time: time.Date(2004, 5, 2, 9, 0, 15, 0, time.UTC), Value: "200"},
 					{Time: time.Date(2004, 12, 1, 0, 0, 0, 0, newYorkTz), Value: "300"},
@@ -1205,7 +1419,7 @@ too few arguments
 			t.Run("FetchLatest", func(t *testing.T) {
 				mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = true, false, false
 
-				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "test_id"}
+				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "http://test.url/test_id"}
 				expectedErrOut := `Error: failed to fetch from http://test.url: test FetchLatest error
 `
 				expectedError := "failed to fetch from http://test.url"
@@ -1238,7 +1452,7 @@ too few arguments
 			t.Run("FetchOldest", func(t *testing.T) {
 				mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = false, true, false
 
-				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "-s", "min", "http://test.url", "test_id"}
+				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "-s", "min", "http://test.url", "http://test.url/test_id"}
 				expectedErrOut := `Error: failed to fetch from http://test.url: test FetchOldest error
 `
 				expectedError := "failed to fetch from http://test.url"
@@ -1271,7 +1485,7 @@ too few arguments
 			t.Run("FetchDateRange", func(t *testing.T) {
 				mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = false, false, true
 
-				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "-s", "none", "http://test.url", "test_id"}
+				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "-s", "none", "http://test.url", "http://test.url/test_id"}
 				expectedErrOut := `Error: failed to fetch from http://test.url: test FetchDateRange error
 `
 				expectedError := "failed to fetch from http://test.url"
@@ -1305,16 +1519,16 @@ too few arguments
 		t.Run("FiapError", func(t *testing.T) {
 			mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, false, false
 			mockClient.results.pointSets = map[string]model.ProcessedPointSet{
-				"test_id": {
-					PointSetID: []string{"test_id_1", "test_id_2", "test_id_3"},
-					PointID:    []string{"test_id_4", "test_id_5"},
+				"http://test.url/test_id": {
+					PointSetID: []string{"http://test.url/test_id_1", "http://test.url/test_id_2", "http://test.url/test_id_3"},
+					PointID:    []string{"http://test.url/test_id_4", "http://test.url/test_id_5"},
 				},
 			}
 			mockClient.results.points = map[string]([]model.Value){}
 			mockClient.results.fiapErr = &model.Error{Type: "test_type", Value: "test_value"}
 
 			expectedOut := ""
-			expectedFileOut := `{"point_sets":{"test_id":{"point_set_id":["test_id_1","test_id_2","test_id_3"],"point_id":["test_id_4","test_id_5"]}}}`
+			expectedFileOut := `{"point_sets":{"http://test.url/test_id":{"point_set_id":["http://test.url/test_id_1","http://test.url/test_id_2","http://test.url/test_id_3"],"point_id":["http://test.url/test_id_4","http://test.url/test_id_5"]}}}`
 			expectedErrOut := `Error: fiap error: type test_type, value test_value
 `
 			expectedError := "fiap error: type test_type, value test_value"
@@ -1322,7 +1536,7 @@ too few arguments
 			t.Run("FetchLatest", func(t *testing.T) {
 				mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = false, true, true
 
-				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "test_id"}
+				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "http://test.url/test_id"}
 
 				resetActualValues()
 				if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
@@ -1350,7 +1564,7 @@ too few arguments
 				} else {
 					if len(mockClient.actualArguments.ids) != 1 {
 						t.Error("assertion error of id")
-					} else if mockClient.actualArguments.ids[0] != "test_id" {
+					} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 						t.Error("assertion error of id")
 					}
 				}
@@ -1370,7 +1584,7 @@ too few arguments
 			t.Run("FetchOldest", func(t *testing.T) {
 				mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = true, false, true
 
-				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "-s", "min", "http://test.url", "test_id"}
+				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "-s", "min", "http://test.url", "http://test.url/test_id"}
 
 				resetActualValues()
 				if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
@@ -1398,7 +1612,7 @@ too few arguments
 				} else {
 					if len(mockClient.actualArguments.ids) != 1 {
 						t.Error("assertion error of id")
-					} else if mockClient.actualArguments.ids[0] != "test_id" {
+					} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 						t.Error("assertion error of id")
 					}
 				}
@@ -1418,7 +1632,7 @@ too few arguments
 			t.Run("FetchDateRange", func(t *testing.T) {
 				mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = true, true, false
 
-				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "-s", "none", "http://test.url", "test_id"}
+				os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "-s", "none", "http://test.url", "http://test.url/test_id"}
 
 				resetActualValues()
 				if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
@@ -1446,7 +1660,7 @@ too few arguments
 				} else {
 					if len(mockClient.actualArguments.ids) != 1 {
 						t.Error("assertion error of id")
-					} else if mockClient.actualArguments.ids[0] != "test_id" {
+					} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 						t.Error("assertion error of id")
 					}
 				}
@@ -1468,15 +1682,15 @@ too few arguments
 			mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = false, true, true
 			mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, true, false
 			mockClient.results.pointSets = map[string]model.ProcessedPointSet{
-				"test_id": {
-					PointSetID: []string{"test_id_1", "test_id_2", "test_id_3"},
-					PointID:    []string{"test_id_4", "test_id_5"},
+				"http://test.url/test_id": {
+					PointSetID: []string{"http://test.url/test_id_1", "http://test.url/test_id_2", "http://test.url/test_id_3"},
+					PointID:    []string{"http://test.url/test_id_4", "http://test.url/test_id_5"},
 				},
 			}
 			mockClient.results.points = map[string]([]model.Value){}
 			mockClient.results.fiapErr = nil
 
-			os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "test_id"}
+			os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "http://test.url/test_id"}
 			expectedOut := ""
 			expectedFileOut := ""
 			expectedErrOut := `Error: failed to write file './test/file.ext': test file write error
@@ -1509,7 +1723,7 @@ too few arguments
 			} else {
 				if len(mockClient.actualArguments.ids) != 1 {
 					t.Error("assertion error of id")
-				} else if mockClient.actualArguments.ids[0] != "test_id" {
+				} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 					t.Error("assertion error of id")
 				}
 			}
@@ -1530,17 +1744,17 @@ too few arguments
 			mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = false, true, true
 			mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, false, true
 			mockClient.results.pointSets = map[string]model.ProcessedPointSet{
-				"test_id": {
-					PointSetID: []string{"test_id_1", "test_id_2", "test_id_3"},
-					PointID:    []string{"test_id_4", "test_id_5"},
+				"http://test.url/test_id": {
+					PointSetID: []string{"http://test.url/test_id_1", "http://test.url/test_id_2", "http://test.url/test_id_3"},
+					PointID:    []string{"http://test.url/test_id_4", "http://test.url/test_id_5"},
 				},
 			}
 			mockClient.results.points = map[string]([]model.Value){}
 			mockClient.results.fiapErr = nil
 
-			os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "test_id"}
+			os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "http://test.url/test_id"}
 			expectedOut := ""
-			expectedFileOut := `{"point_sets":{"test_id":{"point_set_id":["test_id_1","test_id_2","test_id_3"],"point_id":["test_id_4","test_id_5"]}}}`
+			expectedFileOut := `{"point_sets":{"http://test.url/test_id":{"point_set_id":["http://test.url/test_id_1","http://test.url/test_id_2","http://test.url/test_id_3"],"point_id":["http://test.url/test_id_4","http://test.url/test_id_5"]}}}`
 			expectedErrOut := `Error: failed to close file './test/file.ext': test file close error
 `
 			expectedError := "failed to close file './test/file.ext'"
@@ -1571,7 +1785,7 @@ too few arguments
 			} else {
 				if len(mockClient.actualArguments.ids) != 1 {
 					t.Error("assertion error of id")
-				} else if mockClient.actualArguments.ids[0] != "test_id" {
+				} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 					t.Error("assertion error of id")
 				}
 			}
@@ -1594,15 +1808,15 @@ too few arguments
 			mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = false, true, true
 			mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, false, false
 			mockClient.results.pointSets = map[string]model.ProcessedPointSet{
-				"test_id": {
-					PointSetID: []string{"test_id_1", "test_id_2", "test_id_3"},
-					PointID:    []string{"test_id_4", "test_id_5"},
+				"http://test.url/test_id": {
+					PointSetID: []string{"http://test.url/test_id_1", "http://test.url/test_id_2", "http://test.url/test_id_3"},
+					PointID:    []string{"http://test.url/test_id_4", "http://test.url/test_id_5"},
 				},
 			}
 			mockClient.results.points = map[string]([]model.Value){}
 			mockClient.results.fiapErr = nil
 
-			os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "test_id"}
+			os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "http://test.url/test_id"}
 			expectedOut := ""
 			expectedFileOut := ""
 			expectedErrOut := `Error: failed to format output to json: test json marshal error
@@ -1635,7 +1849,7 @@ too few arguments
 			} else {
 				if len(mockClient.actualArguments.ids) != 1 {
 					t.Error("assertion error of id")
-				} else if mockClient.actualArguments.ids[0] != "test_id" {
+				} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 					t.Error("assertion error of id")
 				}
 			}
@@ -1658,15 +1872,15 @@ too few arguments
 			mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = false, true, true
 			mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, true, true
 			mockClient.results.pointSets = map[string]model.ProcessedPointSet{
-				"test_id": {
-					PointSetID: []string{"test_id_1", "test_id_2", "test_id_3"},
-					PointID:    []string{"test_id_4", "test_id_5"},
+				"http://test.url/test_id": {
+					PointSetID: []string{"http://test.url/test_id_1", "http://test.url/test_id_2", "http://test.url/test_id_3"},
+					PointID:    []string{"http://test.url/test_id_4", "http://test.url/test_id_5"},
 				},
 			}
 			mockClient.results.points = map[string]([]model.Value){}
 			mockClient.results.fiapErr = &model.Error{Type: "test_type", Value: "test_value"}
 
-			os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "test_id"}
+			os.Args = []string{"go-fiap-client", "fetch", "-o", "./test/file.ext", "http://test.url", "http://test.url/test_id"}
 			expectedOut := ""
 			expectedFileOut := ""
 			expectedErrOut := `Error: fiap error: type test_type, value test_value
@@ -1707,7 +1921,7 @@ failed to close file './test/file.ext': test file close error
 			} else {
 				if len(mockClient.actualArguments.ids) != 1 {
 					t.Error("assertion error of id")
-				} else if mockClient.actualArguments.ids[0] != "test_id" {
+				} else if mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
 					t.Error("assertion error of id")
 				}
 			}
@@ -1726,3 +1940,272 @@ failed to close file './test/file.ext': test file close error
 		})
 	})
 }
+
+func TestFetchCommandCassette(t *testing.T) {
+	mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = false, false, false
+	mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, false, false
+	mockClient.results.pointSets = map[string](model.ProcessedPointSet){}
+	mockClient.results.points = map[string]([]model.Value){}
+	mockClient.results.fiapErr = nil
+
+	t.Run("Replay", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "session.json")
+		if err := (&cassette.Cassette{}).Save(path); err != nil {
+			t.Fatal(err)
+		}
+		os.Args = []string{"go-fiap-client", "fetch", "--replay", path, "http://test.url", "http://test.url/test_id"}
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+			t.Errorf("failed to run command: %v", err)
+		}
+		if mockClient.HTTPClient == nil {
+			t.Fatal("assertion error of http client")
+		}
+		if _, ok := mockClient.HTTPClient.Transport.(*cassette.Replayer); !ok {
+			t.Error("assertion error of transport")
+		}
+	})
+	t.Run("Record", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "session.json")
+		os.Args = []string{"go-fiap-client", "fetch", "--record", path, "http://test.url", "http://test.url/test_id"}
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+			t.Errorf("failed to run command: %v", err)
+		}
+		if mockClient.HTTPClient == nil {
+			t.Fatal("assertion error of http client")
+		}
+		if _, ok := mockClient.HTTPClient.Transport.(*cassette.Recorder); !ok {
+			t.Error("assertion error of transport")
+		}
+		if _, err := cassette.Load(path); err != nil {
+			t.Errorf("assertion error of cassette file: %v", err)
+		}
+	})
+	t.Run("MissingCassette", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing.json")
+		os.Args = []string{"go-fiap-client", "fetch", "--replay", path, "http://test.url", "http://test.url/test_id"}
+		expectedErrOut := "Error: cannot load cassette '" + path + "'"
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+			t.Error("expected to fail command but succeed")
+		}
+		if !strings.HasPrefix(mockErrOut.String(), expectedErrOut) {
+			t.Errorf("assertion error of stderr: %s", mockErrOut.String())
+		}
+	})
+	t.Run("RecordAndReplay", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "fetch", "--record", "a.json", "--replay", "b.json", "http://test.url", "http://test.url/test_id"}
+		expectedErrOut := "Error: record and replay cannot be specified together\n"
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+			t.Error("expected to fail command but succeed")
+		}
+		if !strings.HasPrefix(mockErrOut.String(), expectedErrOut) {
+			t.Errorf("assertion error of stderr: %s", mockErrOut.String())
+		}
+	})
+}
+
+func TestFetchCommandValue(t *testing.T) {
+	mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange, mockClient.failByIdsWithKey = false, false, false, false
+	mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, false, false
+	mockClient.results.pointSets = map[string](model.ProcessedPointSet){}
+	mockClient.results.points = map[string]([]model.Value){}
+	mockClient.results.fiapErr = nil
+
+	t.Run("Range", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "fetch", "--value-gteq", "20", "--value-lt", "30", "http://test.url", "http://test.url/test_id"}
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+			t.Errorf("failed to run command: %v", err)
+		}
+		if mockClient.actualArguments.connectionURL != "http://test.url" {
+			t.Error("assertion error of connection url")
+		}
+		if v := mockClient.actualArguments.value; v == nil {
+			t.Error("assertion error of value")
+		} else if tools.StringToString(v.Gteq) != "20" || tools.StringToString(v.Lt) != "30" || v.Eq != nil || v.Neq != nil || v.Gt != nil || v.Lteq != nil {
+			t.Error("assertion error of value")
+		}
+		if mockClient.actualArguments.selectType != model.SelectTypeMaximum {
+			t.Error("assertion error of select")
+		}
+		if len(mockClient.actualArguments.ids) != 1 || mockClient.actualArguments.ids[0] != "http://test.url/test_id" {
+			t.Error("assertion error of id")
+		}
+	})
+	t.Run("Equal", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "fetch", "--value-eq", "ON", "http://test.url", "http://test.url/test_id"}
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+			t.Errorf("failed to run command: %v", err)
+		}
+		if v := mockClient.actualArguments.value; v == nil || tools.StringToString(v.Eq) != "ON" {
+			t.Error("assertion error of value")
+		}
+		if mockClient.actualArguments.selectType != model.SelectTypeNone {
+			t.Error("assertion error of select")
+		}
+	})
+	t.Run("EqualWithSelect", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "fetch", "--value-eq", "ON", "-s", "max", "http://test.url", "http://test.url/test_id"}
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+			t.Error("expected to fail command but succeed")
+		} else if !strings.Contains(err.Error(), `keys.MinMaxIndicator "maximum" is combined with keys.Value.Eq`) {
+			t.Error("expected select with value eq error but not")
+		}
+		if mockClient.actualArguments.connectionURL != "" {
+			t.Error("expected not to fetch but fetched")
+		}
+	})
+	t.Run("WithFrom", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "fetch", "--value-neq", "0", "--from", "2012-01-01T00:00:00Z", "http://test.url", "http://test.url/test_id"}
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+			t.Error("expected to fail command but succeed")
+		} else if !strings.Contains(err.Error(), "value conditions cannot be combined with from or until") {
+			t.Error("expected value with from error but not")
+		}
+		if mockClient.actualArguments.connectionURL != "" {
+			t.Error("expected not to fetch but fetched")
+		}
+	})
+	t.Run("Empty", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "fetch", "--value-gt=", "http://test.url", "http://test.url/test_id"}
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+			t.Error("expected to fail command but succeed")
+		} else if !strings.Contains(err.Error(), "keys.Value.Gt is empty") {
+			t.Error("expected empty value error but not")
+		}
+	})
+	t.Run("FetchError", func(t *testing.T) {
+		mockClient.failByIdsWithKey = true
+		defer func() { mockClient.failByIdsWithKey = false }()
+		os.Args = []string{"go-fiap-client", "fetch", "--value-gt", "0", "http://test.url", "http://test.url/test_id"}
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+			t.Error("expected to fail command but succeed")
+		} else if !strings.Contains(err.Error(), "test FetchByIdsWithKey error") {
+			t.Error("expected fetch error but not")
+		}
+	})
+}
+
+func TestFetchCommandSubSecond(t *testing.T) {
+	mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange, mockClient.failByIdsWithKey = false, false, false, false
+	mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, false, false
+	mockClient.results.pointSets = map[string](model.ProcessedPointSet){}
+	mockClient.results.points = map[string]([]model.Value){
+		"http://test.url/test_id": {{Time: time.Date(2012, 1, 1, 0, 0, 0, 150000000, time.UTC), Value: "1"}},
+	}
+	mockClient.results.fiapErr = nil
+
+	os.Args = []string{"go-fiap-client", "fetch", "-s", "none", "--from", "2012-01-01T00:00:00.1Z", "--until", "1325376000.25", "http://test.url", "http://test.url/test_id"}
+	expectedFrom := time.Date(2012, 1, 1, 0, 0, 0, 100000000, time.UTC)
+	expectedUntil := time.Date(2012, 1, 1, 0, 0, 0, 250000000, time.UTC)
+	expectedOut := `{"points":{"http://test.url/test_id":[{"time":"2012-01-01T00:00:00.15Z","value":"1"}]}}` + "\n"
+
+	resetActualValues()
+	if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+		t.Errorf("failed to run command: %v", err)
+	}
+	if mockClient.TimeLayout != time.RFC3339Nano {
+		t.Error("assertion error of time layout")
+	}
+	if mockClient.actualArguments.fromDate == nil || !mockClient.actualArguments.fromDate.Equal(expectedFrom) {
+		t.Error("assertion error of from date")
+	}
+	if mockClient.actualArguments.untilDate == nil || !mockClient.actualArguments.untilDate.Equal(expectedUntil) {
+		t.Error("assertion error of until date")
+	}
+	if mockOut.String() != expectedOut {
+		t.Errorf("assertion error of stdout: %s", mockOut.String())
+	}
+}
+
+func TestFetchCommandCache(t *testing.T) {
+	mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange, mockClient.failByIdsWithKey = false, false, false, false
+	mockFile.failCreateFile, mockFile.failWriteFile, mockFile.failCloseFile = false, false, false
+	mockClient.results.pointSets = map[string](model.ProcessedPointSet){}
+	mockClient.results.points = map[string]([]model.Value){
+		"http://test.url/test_id": {{Time: time.Date(2012, 1, 1, 12, 0, 0, 0, time.UTC), Value: "1"}},
+	}
+	mockClient.results.fiapErr = nil
+
+	dir := t.TempDir()
+	args := []string{"go-fiap-client", "fetch", "-s", "none", "--tz", "UTC", "--cache-dir", dir, "--from", "2012-01-01T00:00:00Z", "--until", "2012-01-02T00:00:00Z", "http://test.url", "http://test.url/test_id"}
+	expectedOut := `{"points":{"http://test.url/test_id":[{"time":"2012-01-01T12:00:00Z","value":"1"}]}}` + "\n"
+
+	t.Run("First", func(t *testing.T) {
+		os.Args = args
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+			t.Errorf("failed to run command: %v", err)
+		}
+		if mockClient.actualArguments.connectionURL != "http://test.url" {
+			t.Error("assertion error of connection url")
+		}
+		if mockOut.String() != expectedOut {
+			t.Errorf("assertion error of stdout: %s", mockOut.String())
+		}
+	})
+	t.Run("Cached", func(t *testing.T) {
+		os.Args = args
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+			t.Errorf("failed to run command: %v", err)
+		}
+		if mockClient.actualArguments.connectionURL != "" {
+			t.Error("expected not to fetch but fetched")
+		}
+		if mockOut.String() != expectedOut {
+			t.Errorf("assertion error of stdout: %s", mockOut.String())
+		}
+	})
+	t.Run("Refresh", func(t *testing.T) {
+		os.Args = append(append([]string{}, args[:len(args)-2]...), "--cache-refresh", "http://test.url", "http://test.url/test_id")
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+			t.Errorf("failed to run command: %v", err)
+		}
+		if mockClient.actualArguments.connectionURL != "http://test.url" {
+			t.Error("expected to fetch but not")
+		}
+	})
+	t.Run("WithSelect", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "fetch", "--cache-dir", dir, "http://test.url", "http://test.url/test_id"}
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+			t.Error("expected to fail command but succeed")
+		} else if !strings.Contains(err.Error(), "cache-dir can be used only with select none and without value conditions") {
+			t.Error("expected cache-dir with select error but not")
+		}
+	})
+	t.Run("RefreshWithoutDir", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "fetch", "-s", "none", "--cache-refresh", "http://test.url", "http://test.url/test_id"}
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err == nil {
+			t.Error("expected to fail command but succeed")
+		} else if !strings.Contains(err.Error(), "cache-refresh requires cache-dir") {
+			t.Error("expected cache-refresh without cache-dir error but not")
+		}
+	})
+}
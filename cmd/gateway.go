@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/series"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+)
+
+// gatewayCacheSize はgatewayがキャッシュするレスポンスの数の上限
+const gatewayCacheSize = 1000
+
+// defaultGatewayTimeout はgatewayがFIAPサーバとの1回の通信を待つ時間の初期値
+const defaultGatewayTimeout = 30 * time.Second
+
+func newGatewayCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	var (
+		debug          bool
+		addr           string
+		corsOrigins    []string
+		cacheTTLString string
+		timeoutString  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "gateway [flags] URL",
+		Short: "Run HTTP JSON API gateway to FIAP server",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			argumentErrors := make([]error, 0, 3)
+
+			var cacheTTL time.Duration
+			if cacheTTLString != "" {
+				if d, err := series.ParseInterval(cacheTTLString); err == nil {
+					cacheTTL = d
+				} else {
+					argumentErrors = append(argumentErrors, errors.Wrap(err, "cache-ttl allows only duration"))
+				}
+			}
+			timeout := defaultGatewayTimeout
+			if timeoutString != "" {
+				if d, err := series.ParseInterval(timeoutString); err == nil {
+					timeout = d
+				} else {
+					argumentErrors = append(argumentErrors, errors.Wrap(err, "timeout allows only duration"))
+				}
+			}
+			if len(args) < 1 {
+				argumentErrors = append(argumentErrors, errors.New("too few arguments"))
+			} else if len(args) > 1 {
+				argumentErrors = append(argumentErrors, errors.New("too many arguments"))
+			} else if !strings.HasPrefix(args[0], "http://") && !strings.HasPrefix(args[0], "https://") {
+				argumentErrors = append(argumentErrors, errors.Newf("invalid URL: %s", args[0]))
+			}
+
+			if len(argumentErrors) > 0 {
+				return errors.Join(argumentErrors...)
+			}
+			cmd.SilenceUsage = true
+
+			logLevel := slog.LevelInfo
+			if debug {
+				logLevel = slog.LevelDebug
+			}
+			logger := slog.New(tools.NewLogHandler(logLevel))
+
+			srv := &http.Server{
+				Addr:              addr,
+				Handler:           newGateway(args[0], corsOrigins, cacheTTL, logger, append(clientOptions(debug), fiap.WithHTTPClient(&http.Client{Timeout: timeout}))...),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			logger.Info("gateway started", "addr", addr, "url", args[0])
+			if err := listenAndServe(ctx, srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return errors.Wrap(err, "server error")
+			}
+			logger.Info("gateway stopped")
+			return nil
+		},
+	}
+
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+
+	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "set output log level to debug")
+	cmd.Flags().StringVar(&addr, "addr", ":8080", "address to listen on. string=<host:port>")
+	cmd.Flags().StringSliceVar(&corsOrigins, "cors-origin", nil, "origins allowed to access the API by CORS. string=<Origin such as https://example.com, or * for any origin>")
+	cmd.Flags().StringVar(&cacheTTLString, "cache-ttl", "", "cache successful responses for the duration. string=<Duration such as 30s, 5m or 1h>")
+	cmd.Flags().StringVar(&timeoutString, "timeout", "", "timeout of each request to the FIAP server (default 30s). string=<Duration such as 30s, 5m or 1h>")
+
+	return cmd
+}
+
+// gateway はHTTPのJSON APIへのリクエストをFetchClientの呼び出しに変換するhttp.Handler
+type gateway struct {
+	connectionURL string
+	corsOrigins   []string
+	cacheTTL      time.Duration
+	logger        *slog.Logger
+	opts          []fiap.Option
+
+	mu    sync.Mutex
+	cache map[string]*gatewayCacheEntry
+}
+
+// gatewayCacheEntry はキャッシュしたレスポンスのbodyと有効期限
+type gatewayCacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// gatewayError はgatewayのエラーレスポンスの形式
+type gatewayError struct {
+	Error string `json:"error"`
+}
+
+func newGateway(connectionURL string, corsOrigins []string, cacheTTL time.Duration, logger *slog.Logger, opts ...fiap.Option) *gateway {
+	return &gateway{
+		connectionURL: connectionURL,
+		corsOrigins:   corsOrigins,
+		cacheTTL:      cacheTTL,
+		logger:        logger,
+		opts:          opts,
+		cache:         make(map[string]*gatewayCacheEntry),
+	}
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.setCORSHeaders(w, r)
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodOptions:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+		g.writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	if body, ok := g.cached(r.URL.RequestURI()); ok {
+		w.Header().Set("X-Cache", "HIT")
+		g.writeJSON(w, body)
+		return
+	}
+
+	// IDには/が含まれるため、エスケープされたパスから取り出す
+	path := r.URL.EscapedPath()
+	var (
+		body []byte
+		err  error
+	)
+	switch {
+	case strings.HasPrefix(path, "/points/"):
+		body, err = g.fetchPoint(r.Context(), strings.TrimPrefix(path, "/points/"), r.URL.Query())
+	case strings.HasPrefix(path, "/pointsets/"):
+		body, err = g.fetchPointSet(r.Context(), strings.TrimPrefix(path, "/pointsets/"))
+	default:
+		g.writeError(w, http.StatusNotFound, errors.Newf("not found: %s", r.URL.Path))
+		return
+	}
+	if err != nil {
+		status := gatewayStatus(err)
+		g.logger.Debug("gateway request failed", "path", r.URL.Path, "status", status, "error", err)
+		g.writeError(w, status, err)
+		return
+	}
+
+	g.store(r.URL.RequestURI(), body)
+	w.Header().Set("X-Cache", "MISS")
+	g.writeJSON(w, body)
+}
+
+// fetchPoint はqueryのfrom、until、select、tz、aggregate、intervalを解釈し、pointの時系列データを取得する
+func (g *gateway) fetchPoint(ctx context.Context, escapedID string, query url.Values) ([]byte, error) {
+	id, err := url.PathUnescape(escapedID)
+	if err != nil {
+		return nil, markBadRequest(errors.Wrap(err, "invalid id"))
+	}
+	argumentErrors := make([]error, 0, 5)
+
+	selectType := model.SelectTypeMaximum
+	if s := query.Get("select"); s != "" {
+		if st, err := parseSelect(s); err == nil {
+			selectType = st
+		} else {
+			argumentErrors = append(argumentErrors, err)
+		}
+	}
+	location, err := parseLocation(query.Get("tz"))
+	if err != nil {
+		argumentErrors = append(argumentErrors, err)
+	}
+	var fromDate, untilDate *time.Time
+	if s := query.Get("from"); s != "" {
+		if dt, err := tools.ParseTime(s, timeNow(), location); err == nil {
+			fromDate = &dt
+		} else {
+			argumentErrors = append(argumentErrors, errors.Wrap(err, "from allows only datetime, date, unix time or time expression"))
+		}
+	}
+	if s := query.Get("until"); s != "" {
+		if dt, err := tools.ParseTime(s, timeNow(), location); err == nil {
+			untilDate = &dt
+		} else {
+			argumentErrors = append(argumentErrors, errors.Wrap(err, "until allows only datetime, date, unix time or time expression"))
+		}
+	}
+	resample, err := parseResample(query.Get("aggregate"), query.Get("interval"), location)
+	if err != nil {
+		argumentErrors = append(argumentErrors, err)
+	}
+	if err := validateKeys([]string{id}, fromDate, untilDate, nil, selectType); err != nil {
+		argumentErrors = append(argumentErrors, err)
+	}
+	if len(argumentErrors) > 0 {
+		return nil, markBadRequest(errors.Join(argumentErrors...))
+	}
+
+	return g.fetch(ctx, id, fromDate, untilDate, selectType, resample, location)
+}
+
+// fetchPointSet はpointSetの子のpointSetとpointのIDを取得する
+func (g *gateway) fetchPointSet(ctx context.Context, escapedID string) ([]byte, error) {
+	id, err := url.PathUnescape(escapedID)
+	if err != nil {
+		return nil, markBadRequest(errors.Wrap(err, "invalid id"))
+	}
+	if err := validateKeys([]string{id}, nil, nil, nil, model.SelectTypeNone); err != nil {
+		return nil, markBadRequest(err)
+	}
+	return g.fetch(ctx, id, nil, nil, model.SelectTypeNone, nil, nil)
+}
+
+// fetch はexecuteFetchを呼び出す。ctxはリクエストのcontextで、クライアントが切断すると取得を中断する。FIAPサーバがerrorを返した場合はエラーとして扱う
+func (g *gateway) fetch(ctx context.Context, id string, fromDate, untilDate *time.Time, selectType model.SelectType, resample *series.ResampleOption, location *time.Location) ([]byte, error) {
+	body, fiapErr, err := executeFetch(ctx, g.connectionURL, id, fromDate, untilDate, nil, selectType, resample, location, nil, g.opts...)
+	if fiapErr != nil {
+		return nil, fiapErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// setCORSHeaders はリクエストのOriginが許可されている場合にCORSのヘッダを設定する
+func (g *gateway) setCORSHeaders(w http.ResponseWriter, r *http.Request) {
+	if len(g.corsOrigins) == 0 {
+		return
+	}
+	w.Header().Add("Vary", "Origin")
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+	for _, allowed := range g.corsOrigins {
+		if allowed == "*" || allowed == origin {
+			w.Header().Set("Access-Control-Allow-Origin", allowed)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			return
+		}
+	}
+}
+
+// cached はkeyに対応する有効期限内のレスポンスを返す
+func (g *gateway) cached(key string) ([]byte, bool) {
+	if g.cacheTTL <= 0 {
+		return nil, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	entry, ok := g.cache[key]
+	if !ok || timeNow().After(entry.expires) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// store はレスポンスをキャッシュする。上限に達している場合は期限切れのものを削除し、それでも空きがなければキャッシュしない
+func (g *gateway) store(key string, body []byte) {
+	if g.cacheTTL <= 0 {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := timeNow()
+	if len(g.cache) >= gatewayCacheSize {
+		for k, entry := range g.cache {
+			if now.After(entry.expires) {
+				delete(g.cache, k)
+			}
+		}
+		if len(g.cache) >= gatewayCacheSize {
+			return
+		}
+	}
+	g.cache[key] = &gatewayCacheEntry{body: body, expires: now.Add(g.cacheTTL)}
+}
+
+// writeJSON は取得結果をステータスコード200で返す
+func (g *gateway) writeJSON(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	if g.cacheTTL > 0 {
+		w.Header().Set("Cache-Control", "max-age="+strconv.Itoa(int(g.cacheTTL.Seconds())))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// writeError はエラーを{"error": "..."}の形式で返す
+func (g *gateway) writeError(w http.ResponseWriter, status int, err error) {
+	body, _ := json.Marshal(gatewayError{Error: err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// errBadRequest はgatewayへのリクエストの内容が不正であることを表す
+var errBadRequest = errors.New("bad request")
+
+// markBadRequest はerrをステータスコード400で返すエラーとして印を付ける
+func markBadRequest(err error) error {
+	return errors.Mark(err, errBadRequest)
+}
+
+// gatewayStatus はエラーに対応するHTTPのステータスコードを返す
+func gatewayStatus(err error) int {
+	switch {
+	case errors.Is(err, errBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, fiap.ErrPointNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusBadGateway
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/fiaptest"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+// gatewayGet はgatewayにリクエストを送信し、レスポンスとbodyを返す
+func gatewayGet(t *testing.T, g *gateway, method string, target string, origin string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	res := rec.Result()
+	body, _ := io.ReadAll(res.Body)
+	return res, string(body)
+}
+
+func TestGateway(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mockClient.failLatest, mockClient.failOldest, mockClient.failDateRange = false, false, false
+	mockClient.results.pointSets = map[string](model.ProcessedPointSet){}
+	mockClient.results.points = map[string]([]model.Value){
+		"http://xxxxxxxx/tokyo/building1/Room101/": {
+			{Time: time.Date(2012, 2, 2, 16, 34, 5, 0, time.UTC), Value: "30"},
+		},
+	}
+	mockClient.results.fiapErr = nil
+
+	t.Run("Points", func(t *testing.T) {
+		g := newGateway("http://test.url", nil, 0, logger)
+		expectedBody := `{"points":{"http://xxxxxxxx/tokyo/building1/Room101/":[{"time":"2012-02-02T16:34:05Z","value":"30"}]}}`
+		expectedFrom := time.Date(2012, 2, 2, 0, 0, 0, 0, time.UTC)
+
+		resetActualValues()
+		res, body := gatewayGet(t, g, http.MethodGet, "/points/http%3A%2F%2Fxxxxxxxx%2Ftokyo%2Fbuilding1%2FRoom101%2F?select=none&from=2012-02-02T00:00:00Z", "")
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("assertion error of status code: %d", res.StatusCode)
+		}
+		if res.Header.Get("Content-Type") != "application/json" {
+			t.Error("assertion error of content type")
+		}
+		if body != expectedBody {
+			t.Errorf("assertion error of body: %s", body)
+		}
+		if mockClient.actualArguments.connectionURL != "http://test.url" {
+			t.Error("assertion error of connection url")
+		}
+		if len(mockClient.actualArguments.ids) != 1 || mockClient.actualArguments.ids[0] != "http://xxxxxxxx/tokyo/building1/Room101/" {
+			t.Error("assertion error of ids")
+		}
+		if mockClient.actualArguments.fromDate == nil || !mockClient.actualArguments.fromDate.Equal(expectedFrom) {
+			t.Error("assertion error of from date")
+		}
+		if res.Header.Get("Cache-Control") != "" {
+			t.Error("assertion error of cache control")
+		}
+	})
+	t.Run("PointSets", func(t *testing.T) {
+		g := newGateway("http://test.url", nil, 0, logger)
+
+		resetActualValues()
+		res, _ := gatewayGet(t, g, http.MethodGet, "/pointsets/http%3A%2F%2Ftest.url%2Fbuilding1/", "")
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("assertion error of status code: %d", res.StatusCode)
+		}
+		if len(mockClient.actualArguments.ids) != 1 || mockClient.actualArguments.ids[0] != "http://test.url/building1/" {
+			t.Error("assertion error of ids")
+		}
+	})
+	t.Run("BadRequest", func(t *testing.T) {
+		g := newGateway("http://test.url", nil, 0, logger)
+		expectedBody := `{"error":"select type allows only max, min, or none"}`
+
+		res, body := gatewayGet(t, g, http.MethodGet, "/points/http%3A%2F%2Ftest.url%2Fid1?select=latest", "")
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("assertion error of status code: %d", res.StatusCode)
+		}
+		if body != expectedBody {
+			t.Errorf("assertion error of body: %s", body)
+		}
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		g := newGateway("http://test.url", nil, 0, logger)
+
+		res, _ := gatewayGet(t, g, http.MethodGet, "/values/id1", "")
+		if res.StatusCode != http.StatusNotFound {
+			t.Errorf("assertion error of status code: %d", res.StatusCode)
+		}
+	})
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		g := newGateway("http://test.url", nil, 0, logger)
+
+		res, _ := gatewayGet(t, g, http.MethodPost, "/points/http%3A%2F%2Ftest.url%2Fid1", "")
+		if res.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("assertion error of status code: %d", res.StatusCode)
+		}
+	})
+	t.Run("PointNotFound", func(t *testing.T) {
+		mockClient.results.fiapErr = &model.Error{Type: "POINT_NOT_FOUND", Value: "id1 is not found"}
+		defer func() { mockClient.results.fiapErr = nil }()
+		g := newGateway("http://test.url", nil, 0, logger)
+		expectedBody := `{"error":"fiap error: type POINT_NOT_FOUND, value id1 is not found"}`
+
+		res, body := gatewayGet(t, g, http.MethodGet, "/points/http%3A%2F%2Ftest.url%2Fid1", "")
+		if res.StatusCode != http.StatusNotFound {
+			t.Errorf("assertion error of status code: %d", res.StatusCode)
+		}
+		if body != expectedBody {
+			t.Errorf("assertion error of body: %s", body)
+		}
+	})
+	t.Run("BadGateway", func(t *testing.T) {
+		mockClient.failLatest = true
+		defer func() { mockClient.failLatest = false }()
+		g := newGateway("http://test.url", nil, 0, logger)
+
+		res, body := gatewayGet(t, g, http.MethodGet, "/points/http%3A%2F%2Ftest.url%2Fid1", "")
+		if res.StatusCode != http.StatusBadGateway {
+			t.Errorf("assertion error of status code: %d", res.StatusCode)
+		}
+		if !strings.Contains(body, "test FetchLatest error") {
+			t.Errorf("assertion error of body: %s", body)
+		}
+	})
+	t.Run("CORS", func(t *testing.T) {
+		g := newGateway("http://test.url", []string{"https://example.com"}, 0, logger)
+
+		res, _ := gatewayGet(t, g, http.MethodOptions, "/points/http%3A%2F%2Ftest.url%2Fid1", "https://example.com")
+		if res.StatusCode != http.StatusNoContent {
+			t.Errorf("assertion error of status code: %d", res.StatusCode)
+		}
+		if res.Header.Get("Access-Control-Allow-Origin") != "https://example.com" {
+			t.Error("assertion error of allowed origin")
+		}
+		if res.Header.Get("Access-Control-Allow-Methods") != "GET, HEAD, OPTIONS" {
+			t.Error("assertion error of allowed methods")
+		}
+
+		res, _ = gatewayGet(t, g, http.MethodGet, "/points/http%3A%2F%2Ftest.url%2Fid1", "https://other.example.com")
+		if res.Header.Get("Access-Control-Allow-Origin") != "" {
+			t.Error("assertion error of disallowed origin")
+		}
+		if res.Header.Get("Vary") != "Origin" {
+			t.Error("assertion error of vary")
+		}
+	})
+	t.Run("Cache", func(t *testing.T) {
+		originalTimeNow := timeNow
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		timeNow = func() time.Time { return now }
+		defer func() { timeNow = originalTimeNow }()
+		g := newGateway("http://test.url", nil, time.Minute, logger)
+
+		res, first := gatewayGet(t, g, http.MethodGet, "/points/http%3A%2F%2Ftest.url%2Fid1", "")
+		if res.Header.Get("X-Cache") != "MISS" {
+			t.Error("assertion error of first response")
+		}
+		if res.Header.Get("Cache-Control") != "max-age=60" {
+			t.Error("assertion error of cache control")
+		}
+		// キャッシュが有効な間は上流へ問い合わせない
+		mockClient.failLatest = true
+		defer func() { mockClient.failLatest = false }()
+		res, second := gatewayGet(t, g, http.MethodGet, "/points/http%3A%2F%2Ftest.url%2Fid1", "")
+		if res.Header.Get("X-Cache") != "HIT" || second != first {
+			t.Error("assertion error of cached response")
+		}
+
+		now = now.Add(2 * time.Minute)
+		res, _ = gatewayGet(t, g, http.MethodGet, "/points/http%3A%2F%2Ftest.url%2Fid1", "")
+		if res.StatusCode != http.StatusBadGateway {
+			t.Error("assertion error of expired cache")
+		}
+	})
+}
+
+func TestGatewayCanceled(t *testing.T) {
+	createFetchClient = originalCreateFetchClient
+	defer func() { createFetchClient = mockCreateFetchClient }()
+	server := fiaptest.NewServer()
+	defer server.Close()
+	server.AddPoint("http://test.url/id1", model.Value{Time: time.Date(2012, 2, 2, 16, 34, 5, 0, time.UTC), Value: "30"})
+	server.SetLatency(500 * time.Millisecond)
+	g := newGateway(server.URL, nil, 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// リクエストのcontextがキャンセルされた場合は、FIAPサーバの応答を待たずに中断する
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/points/http%3A%2F%2Ftest.url%2Fid1", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	g.ServeHTTP(rec, req)
+
+	if elapsed := time.Since(start); elapsed >= 400*time.Millisecond {
+		t.Errorf("expected to cancel fetch but waited %s", elapsed)
+	}
+	if rec.Code == http.StatusOK {
+		t.Error("expected to fail request but succeed")
+	}
+}
+
+func TestGatewayCommandRun(t *testing.T) {
+	defer func() { listenAndServe = originalListenAndServe }()
+
+	t.Run("Success", func(t *testing.T) {
+		var actualServer *http.Server
+		listenAndServe = func(ctx context.Context, srv *http.Server) error {
+			actualServer = srv
+			return nil
+		}
+		os.Args = []string{"go-fiap-client", "gateway", "--addr", "127.0.0.1:18081", "--cors-origin", "https://a.example.com,https://b.example.com", "--cache-ttl", "5m", "--timeout", "5s", "http://test.url"}
+
+		resetActualValues()
+		if err := newRootCmd(mockOut, mockErrOut).Execute(); err != nil {
+			t.Errorf("failed to run command: %v", err)
+		}
+		if actualServer == nil || actualServer.Addr != "127.0.0.1:18081" {
+			t.Fatal("assertion error of addr")
+		}
+		g, ok := actualServer.Handler.(*gateway)
+		if !ok {
+			t.Fatal("assertion error of handler")
+		}
+		if g.connectionURL != "http://test.url" {
+			t.Error("assertion error of connection url")
+		}
+		if len(g.corsOrigins) != 2 || g.corsOrigins[1] != "https://b.example.com" {
+			t.Error("assertion error of cors origins")
+		}
+		if g.cacheTTL != 5*time.Minute {
+			t.Error("assertion error of cache ttl")
+		}
+		if client := fiap.NewFetchClient(g.connectionURL, g.opts...); client.HTTPClient == nil || client.HTTPClient.Timeout != 5*time.Second {
+			t.Error("assertion error of timeout")
+		}
+	})
+	t.Run("ArgumentError", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "gateway", "--cache-ttl", "soon", "--timeout", "never", "test.url"}
+		expectedErrOut := `Error: cache-ttl allows only duration`
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err == nil {
+			t.Error("expected to fail command but succeed")
+		}
+		if !strings.HasPrefix(mockErrOut.String(), expectedErrOut) {
+			t.Errorf("assertion error of stderr: %s", mockErrOut.String())
+		}
+		if !strings.Contains(mockErrOut.String(), "timeout allows only duration") {
+			t.Error("assertion error of invalid timeout")
+		}
+		if !strings.Contains(mockErrOut.String(), "invalid URL: test.url") {
+			t.Error("assertion error of invalid url")
+		}
+	})
+}
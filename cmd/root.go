@@ -4,11 +4,33 @@ import (
 	"io"
 	"os"
 
+	"github.com/cockroachdb/errors"
+
 	"github.com/spf13/cobra"
 )
 
 var libVersion = "1.0.0"
 
+const (
+	exitCodeError       = 1
+	exitCodeCheckFailed = 2
+	exitCodeUnknown     = 3
+)
+
+// exitError は0と1以外の終了コードでプロセスを終了させるためのエラー
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
 func newRootCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 	version := false
 
@@ -31,6 +53,10 @@ func newRootCmd(out io.Writer, errOut io.Writer) *cobra.Command {
 	cmd.SetHelpCommand(&cobra.Command{Hidden: true})
 	cmd.CompletionOptions.DisableDefaultCmd = true
 	cmd.AddCommand(newFetchCmd(out, errOut))
+	cmd.AddCommand(newCheckCmd(out, errOut))
+	cmd.AddCommand(newConformanceCmd(out, errOut))
+	cmd.AddCommand(newGatewayCmd(out, errOut))
+	cmd.AddCommand(newServeCmd(out, errOut))
 
 	cmd.Flags().BoolVarP(&version, "version", "v", false, "print version of go-fiap-client")
 
@@ -47,3 +73,22 @@ main.goでこの関数を呼び出し、コマンドラインの処理を行い
 func Execute() error {
 	return newRootCmd(os.Stdout, os.Stderr).Execute()
 }
+
+/*
+ExitCode returns the exit code of the process for the error returned by Execute.
+
+ExitCodeは、Executeが返したエラーに対応するプロセスの終了コードを返します。
+
+エラーがない場合は0、checkコマンドまたはconformanceコマンドの検査に失敗した場合は2(NagiosのCRITICALに相当)、
+checkコマンドでFIAPサーバからの取得に失敗し検査を実行できなかった場合は3(NagiosのUNKNOWNに相当)、その他のエラーの場合は1を返します。
+*/
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return exitCodeError
+}
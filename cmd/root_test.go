@@ -3,6 +3,8 @@ package cmd
 import (
 	"os"
 	"testing"
+
+	"github.com/cockroachdb/errors"
 )
 
 func TestRootCommandRun(t *testing.T) {
@@ -17,7 +19,11 @@ Usage:
   go-fiap-client [command]
 
 Available Commands:
+  check       Check fetched points for gaps and stale data
+  conformance Check FIAP server conformance of FETCH queries
   fetch       Run FIAP fetch method once
+  gateway     Run HTTP JSON API gateway to FIAP server
+  serve       Run FIAP storage server answering fetch and write
 
 Flags:
   -h, --help      help for go-fiap-client
@@ -104,3 +110,15 @@ Use "go-fiap-client [command] --help" for more information about a command.
 		})
 	})
 }
+
+func TestExitCode(t *testing.T) {
+	if ExitCode(nil) != 0 {
+		t.Error("assertion error of exit code without error")
+	}
+	if ExitCode(errors.New("test error")) != 1 {
+		t.Error("assertion error of exit code with error")
+	}
+	if ExitCode(errors.Wrap(&exitError{code: exitCodeCheckFailed, err: errors.New("test error")}, "wrapped")) != 2 {
+		t.Error("assertion error of exit code with exitError")
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/server"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/storage"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/cobra"
+)
+
+const serveShutdownTimeout = 10 * time.Second
+
+// listenAndServe はsrvを起動し、ctxが終了したら停止する。テストで置き換えられるよう変数にしている
+var listenAndServe func(ctx context.Context, srv *http.Server) error = func(ctx context.Context, srv *http.Server) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+func newServeCmd(out io.Writer, errOut io.Writer) *cobra.Command {
+	var (
+		debug             bool
+		addr              string
+		dataPath          string
+		maxAcceptableSize uint
+		maxRequestSize    int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve [flags]",
+		Short: "Run FIAP storage server answering fetch and write",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			cmd.SilenceUsage = true
+
+			var store storage.Storage = storage.NewMemory()
+			if dataPath != "" {
+				file, openErr := storage.NewFile(dataPath)
+				if openErr != nil {
+					return errors.Wrap(openErr, "failed to open storage")
+				}
+				defer func() {
+					if closeErr := file.Close(); closeErr != nil && err == nil {
+						err = errors.Wrap(closeErr, "failed to close storage")
+					}
+				}()
+				store = file
+			}
+			logLevel := slog.LevelInfo
+			if debug {
+				logLevel = slog.LevelDebug
+			}
+			logger := slog.New(tools.NewLogHandler(logLevel))
+
+			srv := &http.Server{
+				Addr:              addr,
+				Handler:           server.NewServer(store, server.WithMaxAcceptableSize(maxAcceptableSize), server.WithMaxRequestSize(maxRequestSize), server.WithLogger(logger)),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			logger.Info("FIAP storage server started", "addr", addr, "data", dataPath)
+			if err := listenAndServe(ctx, srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return errors.Wrap(err, "server error")
+			}
+			logger.Info("FIAP storage server stopped")
+			return nil
+		},
+	}
+
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+
+	cmd.Flags().BoolVarP(&debug, "debug", "d", false, "set output log level to debug")
+	cmd.Flags().StringVar(&addr, "addr", ":8080", "address to listen on. string=<host:port>")
+	cmd.Flags().StringVar(&dataPath, "data", "", "JSON file to store points and pointSets. keeps data only in memory if omitted. string=<File path>")
+	cmd.Flags().UintVar(&maxAcceptableSize, "max-acceptable-size", server.DefaultMaxAcceptableSize, "maximum number of values returned at once. 0 means no limit")
+	cmd.Flags().Int64Var(&maxRequestSize, "max-request-size", server.DefaultMaxRequestSize, "maximum size of a request body in bytes. 0 means no limit")
+
+	return cmd
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/server"
+	"github.com/cockroachdb/errors"
+)
+
+var originalListenAndServe = listenAndServe
+
+// writeRQ はRoom101に1つのvalueを書き込むdataRQ
+const writeRQ = `<?xml version="1.0" encoding="utf-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/">
+	<soapenv:Body>
+		<ns2:dataRQ xmlns:ns2="http://soap.fiap.org/">
+			<transport xmlns="http://gutp.jp/fiap/2009/11/">
+				<body>
+					<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+						<value time="2012-02-02T16:34:00+09:00">30</value>
+					</point>
+				</body>
+			</transport>
+		</ns2:dataRQ>
+	</soapenv:Body>
+</soapenv:Envelope>`
+
+func TestServeCommandRun(t *testing.T) {
+	defer func() { listenAndServe = originalListenAndServe }()
+
+	t.Run("Success", func(t *testing.T) {
+		dataPath := filepath.Join(t.TempDir(), "fiap.json")
+		var actualServer *http.Server
+		// 起動したサーバに対して書き込みと取得を行い、終了する
+		listenAndServe = func(ctx context.Context, srv *http.Server) error {
+			actualServer = srv
+			ts := httptest.NewServer(srv.Handler)
+			defer ts.Close()
+			res, err := http.Post(ts.URL, "text/xml", strings.NewReader(writeRQ))
+			if err != nil {
+				return err
+			}
+			res.Body.Close()
+			_, points, _, err := fiap.NewFetchClient(ts.URL).FetchLatest(nil, nil, "http://xxxxxxxx/tokyo/building1/Room101/")
+			if err != nil {
+				return err
+			}
+			if len(points["http://xxxxxxxx/tokyo/building1/Room101/"]) != 1 {
+				return errors.New("written value is not fetched")
+			}
+			return http.ErrServerClosed
+		}
+		os.Args = []string{"go-fiap-client", "serve", "--addr", "127.0.0.1:18080", "--data", dataPath, "--max-acceptable-size", "10", "--max-request-size", "4096"}
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err != nil {
+			t.Errorf("failed to run command: %v", err)
+		}
+		if actualServer == nil || actualServer.Addr != "127.0.0.1:18080" {
+			t.Fatal("assertion error of addr")
+		}
+		if s, ok := actualServer.Handler.(*server.Server); !ok || s.MaxAcceptableSize != 10 {
+			t.Error("assertion error of max-acceptable-size")
+		} else if s.MaxRequestSize != 4096 {
+			t.Error("assertion error of max-request-size")
+		}
+		if data, err := os.ReadFile(dataPath); err != nil || !strings.Contains(string(data), "2012-02-02T16:34:00+09:00") {
+			t.Error("assertion error of data file")
+		}
+	})
+	t.Run("Stop", func(t *testing.T) {
+		// 停止の処理を確認するため、実際に起動してすぐにキャンセルする
+		listenAndServe = originalListenAndServe
+		os.Args = []string{"go-fiap-client", "serve", "--addr", "127.0.0.1:0"}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).ExecuteContext(ctx)
+		if err != nil {
+			t.Errorf("failed to run command: %v", err)
+		}
+	})
+	t.Run("InvalidData", func(t *testing.T) {
+		dataPath := filepath.Join(t.TempDir(), "fiap.json")
+		if err := os.WriteFile(dataPath, []byte("{"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Args = []string{"go-fiap-client", "serve", "--data", dataPath}
+		expectedErrOut := "Error: failed to open storage: failed to parse " + dataPath
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err == nil {
+			t.Error("expected to fail command but succeed")
+		}
+		if !strings.HasPrefix(mockErrOut.String(), expectedErrOut) {
+			t.Error("assertion error of stderr")
+		}
+	})
+	t.Run("TooManyArguments", func(t *testing.T) {
+		os.Args = []string{"go-fiap-client", "serve", "extra"}
+
+		resetActualValues()
+		err := newRootCmd(mockOut, mockErrOut).Execute()
+		if err == nil {
+			t.Error("expected to fail command but succeed")
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+var timeNow func() time.Time = time.Now
+
+// parseLocation は--tzで指定されたタイムゾーン名を解釈する。空文字の場合はnilを返す
+func parseLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unknown time zone '%s'", name)
+	}
+	return loc, nil
+}
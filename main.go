@@ -8,8 +8,5 @@ import (
 
 func main() {
 	err := cmd.Execute()
-	if err != nil {
-		os.Exit(1)
-	}
-	os.Exit(0)
+	os.Exit(cmd.ExitCode(err))
 }
@@ -0,0 +1,383 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+/*
+DefaultSettleDelay is the default time after which values are assumed to be stored in the FIAP server.
+
+DefaultSettleDelayは、FIAPサーバにvalueが揃うまでの時間の初期値です。
+*/
+const DefaultSettleDelay = 10 * time.Minute
+
+/*
+Cache is a Fetcher that caches the results of FetchDateRange in files.
+
+Cacheは、FetchDateRangeの結果をファイルにキャッシュするFetcherです。Newで作成してください。
+複数のgoroutineから同時に呼び出すことができます。同じディレクトリを複数のプロセスで同時に使用することはできません。
+*/
+type Cache struct {
+	fiap.Fetcher
+
+	mu          sync.Mutex
+	dir         string
+	maxAge      time.Duration
+	settleDelay time.Duration
+	location    *time.Location
+	now         func() time.Time
+}
+
+/*
+Option is a functional option for New.
+
+Optionは、Newに渡す設定です。
+*/
+type Option func(*Cache)
+
+/*
+WithMaxAge sets how long the cached values of a point are used.
+
+WithMaxAgeは、pointのvalueを最初に保存してからキャッシュを使用する期間を設定します。
+期間を過ぎたキャッシュは削除し、FIAPサーバからすべて取得し直します。0の場合は期限を設けません。
+*/
+func WithMaxAge(d time.Duration) Option {
+	return func(c *Cache) {
+		c.maxAge = d
+	}
+}
+
+/*
+WithSettleDelay sets how long it takes until the values of a time are stored in the FIAP server.
+
+WithSettleDelayは、ある時刻のvalueがFIAPサーバに揃うまでの時間を設定します。指定しない場合はDefaultSettleDelayです。
+untilDateが現在時刻よりこの時間以上前の場合のみ、untilDateまでのvalueが揃ったものとしてキャッシュします。
+それ以外の場合は、取得した最後のvalueの時刻(現在時刻よりこの時間前の時刻を超えない)までをキャッシュし、次回はその時刻以降を取得し直します。
+*/
+func WithSettleDelay(d time.Duration) Option {
+	return func(c *Cache) {
+		c.settleDelay = d
+	}
+}
+
+/*
+WithLocation sets the location used to normalize the times of the cached values.
+
+WithLocationは、キャッシュから返すvalueの時刻を揃えるタイムゾーンを設定します。nilの場合は保存したときのオフセットのまま返します。
+*/
+func WithLocation(location *time.Location) Option {
+	return func(c *Cache) {
+		c.location = location
+	}
+}
+
+// entry はpointごとにファイルに保存するキャッシュの内容。From(nilの場合は最初)からUntilまでのvalueがすべて揃っていることを表す
+type entry struct {
+	ID        string        `json:"id"`
+	From      *time.Time    `json:"from,omitempty"`
+	Until     *time.Time    `json:"until,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	Values    []model.Value `json:"values"`
+}
+
+/*
+New returns a Cache that wraps the fetcher and saves values in dir.
+
+Newは、fetcherを包み、dirにvalueを保存するCacheを返します。dirが存在しない場合は作成します。
+
+errの発生条件
+ - fetcherがnilの場合
+ - dirが空文字の場合、またはdirを作成できない場合
+*/
+func New(fetcher fiap.Fetcher, dir string, opts ...Option) (*Cache, error) {
+	if fetcher == nil {
+		return nil, errors.New("fetcher is nil")
+	}
+	if dir == "" {
+		return nil, errors.New("cache directory is empty")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create cache directory %s", dir)
+	}
+	c := &Cache{Fetcher: fetcher, dir: dir, settleDelay: DefaultSettleDelay, now: time.Now}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+/*
+FetchDateRange fetches values from fromDate to untilDate, using the cached values.
+
+FetchDateRangeは、fiap.FetcherのFetchDateRangeと同様にfromDate以降、untilDate以前のデータを取得します。
+
+各IDについて、保存済みの範囲がfromDateからuntilDateまでを含む場合は、FIAPサーバに問い合わせずにキャッシュから返します。
+保存済みの範囲がfromDateを含まない場合は、fromDateから保存済みの範囲の開始時刻までをFIAPサーバから取得し、保存済みのvalueと合わせて保存します。
+保存済みの範囲がuntilDateを含まない場合は、保存済みの範囲の終了時刻以降のみをFIAPサーバから取得して保存します。
+保存済みの範囲の終了時刻は、untilDateが現在時刻よりWithSettleDelayの時間以上前の場合はuntilDate、
+それ以外の場合は取得した最後のvalueの時刻と、現在時刻よりWithSettleDelayの時間前の時刻のうち早い方とします。
+
+IDがpointSetの場合と、FIAPサーバがerrorを返した場合はキャッシュしません。
+fiapErrまたはerrを返す場合、pointSetsとpointsはnilです。
+
+errの発生条件
+ - 包んだFetcherのFetchDateRangeでエラーが発生した場合
+ - キャッシュのファイルを読み書きできない場合
+*/
+func (c *Cache) FetchDateRange(fromDate *time.Time, untilDate *time.Time, ids ...string) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pointSets = make(map[string](model.ProcessedPointSet))
+	points = make(map[string]([]model.Value))
+	for _, id := range ids {
+		ps, values, isPoint, fiapErr, err := c.fetchDateRange(fromDate, untilDate, id)
+		if err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "failed to fetch %s", id)
+		}
+		if fiapErr != nil {
+			return nil, nil, fiapErr, nil
+		}
+		for psID, p := range ps {
+			pointSets[psID] = p
+		}
+		if isPoint {
+			points[id] = values
+		}
+	}
+	return pointSets, points, nil, nil
+}
+
+// fetchDateRange は1つのIDについてキャッシュを使用してデータを取得する。IDがpointでない場合はisPointが偽になる
+func (c *Cache) fetchDateRange(fromDate, untilDate *time.Time, id string) (pointSets map[string](model.ProcessedPointSet), values []model.Value, isPoint bool, fiapErr *model.Error, err error) {
+	e, err := c.load(id)
+	if err != nil {
+		return nil, nil, false, nil, err
+	}
+	now := c.now()
+	if e == nil {
+		// fromDateからuntilDateまでをFIAPサーバから取得する
+		pointSets, fetched, isPoint, fiapErr, err := c.fetchPoint(fromDate, untilDate, id)
+		if !isPoint || fiapErr != nil || err != nil {
+			return pointSets, nil, false, fiapErr, err
+		}
+		e = &entry{ID: id, From: fromDate, CreatedAt: now}
+		e.merge(fromDate, untilDate, fetched)
+		c.advance(e, untilDate, now)
+		if err := c.save(e); err != nil {
+			return nil, nil, false, nil, err
+		}
+		return pointSets, c.filter(e.Values, fromDate, untilDate), true, nil, nil
+	}
+
+	var fetched []model.Value
+	updated := false
+	if !e.covers(fromDate) {
+		// 保存済みの範囲より前をFIAPサーバから取得し、保存済みのvalueと合わせる
+		pointSets, fetched, isPoint, fiapErr, err = c.fetchPoint(fromDate, e.From, id)
+		if !isPoint || fiapErr != nil || err != nil {
+			return pointSets, nil, false, fiapErr, err
+		}
+		e.merge(fromDate, e.From, fetched)
+		e.From = fromDate
+		updated = true
+	}
+	if untilDate == nil || e.Until == nil || untilDate.After(*e.Until) {
+		// 保存済みの範囲の終了時刻以降をFIAPサーバから取得する
+		gteq := e.From
+		if e.Until != nil {
+			gteq = e.Until
+		}
+		pointSets, fetched, isPoint, fiapErr, err = c.fetchPoint(gteq, untilDate, id)
+		if !isPoint || fiapErr != nil || err != nil {
+			return pointSets, nil, false, fiapErr, err
+		}
+		e.merge(gteq, untilDate, fetched)
+		c.advance(e, untilDate, now)
+		updated = true
+	}
+	if updated {
+		if err := c.save(e); err != nil {
+			return nil, nil, false, nil, err
+		}
+	}
+	return pointSets, c.filter(e.Values, fromDate, untilDate), true, nil, nil
+}
+
+// fetchPoint は包んだFetcherでgteq以降、lteq以前のデータを取得する。IDがpointでない場合はisPointが偽になる
+func (c *Cache) fetchPoint(gteq, lteq *time.Time, id string) (pointSets map[string](model.ProcessedPointSet), values []model.Value, isPoint bool, fiapErr *model.Error, err error) {
+	pointSets, points, fiapErr, err := c.Fetcher.FetchDateRange(gteq, lteq, id)
+	if err != nil || fiapErr != nil {
+		return nil, nil, false, fiapErr, err
+	}
+	values, isPoint = points[id]
+	return pointSets, values, isPoint, nil, nil
+}
+
+// advance は保存済みの範囲の終了時刻を進める。untilDateがsettleDelayより前の場合はuntilDate、
+// それ以外の場合は最後のvalueの時刻とsettleDelayより前の時刻のうち早い方とし、遅れて書き込まれたvalueを次回に取得できるようにする
+func (c *Cache) advance(e *entry, untilDate *time.Time, now time.Time) {
+	settled := now.Add(-c.settleDelay)
+	if untilDate != nil && !untilDate.After(settled) {
+		e.Until = untilDate
+		return
+	}
+	if len(e.Values) == 0 {
+		return
+	}
+	last := e.Values[len(e.Values)-1].Time
+	if last.After(settled) {
+		last = settled
+	}
+	if e.From != nil && last.Before(*e.From) {
+		// 揃った範囲がない場合は、次回も開始時刻から取得する
+		return
+	}
+	if e.Until == nil || last.After(*e.Until) {
+		e.Until = &last
+	}
+}
+
+// covers はfromDate以降の範囲が保存済みの範囲から始まるかどうかを返す
+func (e *entry) covers(fromDate *time.Time) bool {
+	if e.From == nil {
+		return true
+	}
+	return fromDate != nil && !fromDate.Before(*e.From)
+}
+
+// merge はgteq以降、lteq以前の保存済みのvalueを、FIAPサーバから取得したvaluesで置き換え、時刻順に並べる
+func (e *entry) merge(gteq, lteq *time.Time, values []model.Value) {
+	merged := make([]model.Value, 0, len(e.Values)+len(values))
+	for _, v := range e.Values {
+		if (gteq != nil && v.Time.Before(*gteq)) || (lteq != nil && v.Time.After(*lteq)) {
+			merged = append(merged, v)
+		}
+	}
+	merged = append(merged, values...)
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Time.Before(merged[j].Time) })
+	e.Values = merged
+}
+
+// filter はvaluesのうちfromDate以降、untilDate以前のものを返す。c.locationが設定されている場合は時刻をそのタイムゾーンに揃える
+func (c *Cache) filter(values []model.Value, fromDate, untilDate *time.Time) []model.Value {
+	result := make([]model.Value, 0, len(values))
+	for _, v := range values {
+		if (fromDate != nil && v.Time.Before(*fromDate)) || (untilDate != nil && v.Time.After(*untilDate)) {
+			continue
+		}
+		if c.location != nil {
+			v.Time = v.Time.In(c.location)
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+/*
+Invalidate removes the cached values of the points.
+
+Invalidateは、指定されたIDのpointのキャッシュを削除します。キャッシュが存在しないIDは無視します。
+
+errの発生条件
+ - キャッシュのファイルを削除できない場合
+*/
+func (c *Cache) Invalidate(ids ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		if err := os.Remove(c.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errors.Wrapf(err, "failed to invalidate cache of %s", id)
+		}
+	}
+	return nil
+}
+
+/*
+Clear removes all cached values in the directory.
+
+Clearは、ディレクトリに保存したすべてのキャッシュを削除します。
+
+errの発生条件
+ - キャッシュのファイルを削除できない場合
+*/
+func (c *Cache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	paths, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return errors.Wrap(err, "failed to list cache files")
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errors.Wrapf(err, "failed to remove cache file %s", path)
+		}
+	}
+	return nil
+}
+
+// path はIDのキャッシュを保存するファイルのパスを返す。IDにはファイル名に使用できない文字が含まれるため、ハッシュ値を使用する
+func (c *Cache) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load はIDのキャッシュを読み込む。キャッシュが存在しない場合と有効期間を過ぎた場合はnilを返す
+func (c *Cache) load(id string) (*entry, error) {
+	path := c.path(id)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read cache file %s", path)
+	}
+	e := &entry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse cache file %s", path)
+	}
+	if e.ID != id {
+		return nil, errors.Newf("cache file %s is for another id %s", path, e.ID)
+	}
+	if c.maxAge > 0 && c.now().Sub(e.CreatedAt) > c.maxAge {
+		return nil, nil
+	}
+	return e, nil
+}
+
+// save はキャッシュをファイルに保存する。一時ファイルに書き込んでから置き換えるため、保存の途中でプロセスが終了しても壊れない
+func (c *Cache) save(e *entry) error {
+	path := c.path(e.ID)
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode cache of %s", e.ID)
+	}
+	tmp, err := os.CreateTemp(c.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "failed to save cache file %s", path)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "failed to save cache file %s", path)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "failed to save cache file %s", path)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrapf(err, "failed to save cache file %s", path)
+	}
+	return nil
+}
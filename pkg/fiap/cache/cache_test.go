@@ -0,0 +1,338 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/fiaptest"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+const (
+	building1 = "http://xxxxxxxx/tokyo/building1/"
+	room101   = "http://xxxxxxxx/tokyo/building1/Room101/"
+)
+
+var base = time.Date(2012, 2, 2, 16, 34, 0, 0, time.UTC)
+
+// minutes はbaseからn分後の時刻を返す
+func minutes(n int) time.Time {
+	return base.Add(time.Duration(n) * time.Minute)
+}
+
+// newTestCache はRoom101に1分間隔のn個のvalueを持つServerと、そのServerから取得するCacheを返す。現在時刻はbaseの1日後とする
+func newTestCache(t *testing.T, n int, opts ...Option) (*fiaptest.Server, *Cache) {
+	s := fiaptest.NewServer()
+	t.Cleanup(s.Close)
+	addValues(s, 0, n)
+	s.AddPointSet(building1, nil, []string{room101})
+
+	c, err := New(fiap.NewFetchClient(s.URL, fiap.WithLocation(time.UTC)), filepath.Join(t.TempDir(), "cache"), opts...)
+	require.NoError(t, err)
+	c.now = func() time.Time { return base.Add(24 * time.Hour) }
+	return s, c
+}
+
+// addValues はRoom101にstart分後からn個のvalueを追加する。valueは分数の文字列とする
+func addValues(s *fiaptest.Server, start, n int) {
+	for i := start; i < start+n; i++ {
+		s.AddPoint(room101, model.Value{Time: minutes(i), Value: strconv.Itoa(i)})
+	}
+}
+
+// valuesOf はvalueの値の一覧を返す
+func valuesOf(values []model.Value) []string {
+	result := []string{}
+	for _, v := range values {
+		result = append(result, v.Value)
+	}
+	return result
+}
+
+// lastKey は最後に送信したクエリのkeyを返す
+func lastKey(t *testing.T, s *fiaptest.Server) model.Key {
+	t.Helper()
+	queries := s.Queries()
+	require.NotEmpty(t, queries)
+	require.Len(t, queries[len(queries)-1].Key, 1)
+	return queries[len(queries)-1].Key[0]
+}
+
+func TestCacheFetchDateRange(t *testing.T) {
+	t.Run("closed range is served from cache", func(t *testing.T) {
+		s, c := newTestCache(t, 5)
+		from, until := minutes(1), minutes(3)
+
+		// テスト対象の関数を実行
+		_, points, fiapErr, err := c.FetchDateRange(&from, &until, room101)
+
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		assert.Equal(t, []string{"1", "2", "3"}, valuesOf(points[room101]))
+		assert.Len(t, s.Queries(), 1)
+
+		// 保存済みの範囲に含まれる範囲はFIAPサーバに問い合わせない
+		narrowFrom := minutes(2)
+		_, points, _, err = c.FetchDateRange(&narrowFrom, &until, room101)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2", "3"}, valuesOf(points[room101]))
+		assert.Len(t, s.Queries(), 1)
+	})
+
+	t.Run("open range fetches only the tail", func(t *testing.T) {
+		s, c := newTestCache(t, 3)
+		from := minutes(0)
+
+		// テスト対象の関数を実行
+		_, points, _, err := c.FetchDateRange(&from, nil, room101)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"0", "1", "2"}, valuesOf(points[room101]))
+
+		addValues(s, 3, 2)
+		_, points, _, err = c.FetchDateRange(&from, nil, room101)
+
+		require.NoError(t, err)
+		assert.Len(t, s.Queries(), 2)
+		// 2回目は前回の最後のvalueの時刻以降のみを問い合わせる
+		assert.Equal(t, minutes(2).Format(time.RFC3339), lastKey(t, s).Gteq)
+		assert.Equal(t, []string{"0", "1", "2", "3", "4"}, valuesOf(points[room101]))
+	})
+
+	t.Run("until beyond the cached range fetches the tail", func(t *testing.T) {
+		s, c := newTestCache(t, 5)
+		from, until := minutes(0), minutes(2)
+		_, _, _, err := c.FetchDateRange(&from, &until, room101)
+		require.NoError(t, err)
+
+		// テスト対象の関数を実行
+		later := minutes(4)
+		_, points, _, err := c.FetchDateRange(&from, &later, room101)
+
+		require.NoError(t, err)
+		assert.Len(t, s.Queries(), 2)
+		assert.Equal(t, minutes(2).Format(time.RFC3339), lastKey(t, s).Gteq)
+		assert.Len(t, points[room101], 5)
+	})
+
+	t.Run("from before the cached range merges with the cache", func(t *testing.T) {
+		s, c := newTestCache(t, 5)
+		from, until := minutes(2), minutes(4)
+		_, _, _, err := c.FetchDateRange(&from, &until, room101)
+		require.NoError(t, err)
+
+		// テスト対象の関数を実行
+		_, points, _, err := c.FetchDateRange(nil, &until, room101)
+
+		require.NoError(t, err)
+		assert.Len(t, s.Queries(), 2)
+		// 保存済みの範囲の開始時刻までのみを問い合わせる
+		assert.Empty(t, lastKey(t, s).Gteq)
+		assert.Equal(t, minutes(2).Format(time.RFC3339), lastKey(t, s).Lteq)
+		assert.Equal(t, []string{"0", "1", "2", "3", "4"}, valuesOf(points[room101]))
+
+		// 合わせた範囲はFIAPサーバに問い合わせない
+		_, points, _, err = c.FetchDateRange(&from, &until, room101)
+
+		require.NoError(t, err)
+		assert.Len(t, s.Queries(), 2)
+		assert.Equal(t, []string{"2", "3", "4"}, valuesOf(points[room101]))
+	})
+
+	t.Run("settled until is cached", func(t *testing.T) {
+		s, c := newTestCache(t, 5)
+		from, until := minutes(0), minutes(10)
+
+		// テスト対象の関数を実行
+		for i := 0; i < 2; i++ {
+			_, points, _, err := c.FetchDateRange(&from, &until, room101)
+
+			require.NoError(t, err)
+			assert.Len(t, points[room101], 5)
+		}
+		assert.Len(t, s.Queries(), 1)
+	})
+
+	t.Run("values stored late within the settle delay are fetched again", func(t *testing.T) {
+		s, c := newTestCache(t, 5, WithSettleDelay(3*time.Minute))
+		c.now = func() time.Time { return minutes(5) }
+		from, until := minutes(0), minutes(10)
+		_, _, _, err := c.FetchDateRange(&from, &until, room101)
+		require.NoError(t, err)
+
+		// テスト対象の関数を実行
+		s.AddPoint(room101, model.Value{Time: minutes(3).Add(30 * time.Second), Value: "late"})
+		_, points, _, err := c.FetchDateRange(&from, &until, room101)
+
+		require.NoError(t, err)
+		assert.Len(t, s.Queries(), 2)
+		// 最後のvalueの時刻ではなく、現在時刻よりsettleDelay前の時刻以降を問い合わせる
+		assert.Equal(t, minutes(2).Format(time.RFC3339), lastKey(t, s).Gteq)
+		assert.Equal(t, []string{"0", "1", "2", "3", "late", "4"}, valuesOf(points[room101]))
+	})
+
+	t.Run("values within the settle delay only", func(t *testing.T) {
+		s, c := newTestCache(t, 5, WithSettleDelay(48*time.Hour))
+		from, until := minutes(0), minutes(10)
+		_, _, _, err := c.FetchDateRange(&from, &until, room101)
+		require.NoError(t, err)
+
+		// テスト対象の関数を実行
+		_, points, _, err := c.FetchDateRange(&from, &until, room101)
+
+		require.NoError(t, err)
+		assert.Len(t, s.Queries(), 2)
+		// 揃った範囲がないため、開始時刻から問い合わせる
+		assert.Equal(t, minutes(0).Format(time.RFC3339), lastKey(t, s).Gteq)
+		assert.Len(t, points[room101], 5)
+	})
+
+	t.Run("pointSet is not cached", func(t *testing.T) {
+		s, c := newTestCache(t, 1)
+		until := minutes(1)
+
+		for i := 0; i < 2; i++ {
+			// テスト対象の関数を実行
+			pointSets, points, _, err := c.FetchDateRange(nil, &until, building1)
+
+			require.NoError(t, err)
+			assert.Equal(t, model.ProcessedPointSet{PointSetID: []string{}, PointID: []string{room101}}, pointSets[building1])
+			assert.Empty(t, points)
+		}
+		assert.Len(t, s.Queries(), 2)
+	})
+
+	t.Run("fiap error is not cached", func(t *testing.T) {
+		s, c := newTestCache(t, 1)
+		unknown := "http://xxxxxxxx/tokyo/building1/Room999/"
+
+		// テスト対象の関数を実行
+		pointSets, points, fiapErr, err := c.FetchDateRange(nil, nil, room101, unknown)
+
+		require.NoError(t, err)
+		require.NotNil(t, fiapErr)
+		assert.Equal(t, "POINT_NOT_FOUND", fiapErr.Type)
+		assert.Nil(t, pointSets)
+		assert.Nil(t, points)
+		s.AddPoint(unknown, model.Value{Time: minutes(0), Value: "0"})
+		_, points, fiapErr, err = c.FetchDateRange(nil, nil, unknown)
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		assert.Len(t, points[unknown], 1)
+	})
+
+	t.Run("location", func(t *testing.T) {
+		tokyoTz := time.FixedZone("Asia/Tokyo", 9*60*60)
+		_, c := newTestCache(t, 1, WithLocation(tokyoTz))
+		until := minutes(1)
+		_, _, _, err := c.FetchDateRange(nil, &until, room101)
+		require.NoError(t, err)
+
+		// テスト対象の関数を実行
+		_, points, _, err := c.FetchDateRange(nil, &until, room101)
+
+		require.NoError(t, err)
+		require.Len(t, points[room101], 1)
+		assert.Equal(t, tokyoTz, points[room101][0].Time.Location())
+		assert.True(t, minutes(0).Equal(points[room101][0].Time))
+	})
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	until := minutes(5)
+
+	t.Run("Invalidate", func(t *testing.T) {
+		s, c := newTestCache(t, 2)
+		_, _, _, err := c.FetchDateRange(nil, &until, room101)
+		require.NoError(t, err)
+
+		// テスト対象の関数を実行
+		require.NoError(t, c.Invalidate(room101, "http://xxxxxxxx/tokyo/building1/Room999/"))
+
+		_, _, _, err = c.FetchDateRange(nil, &until, room101)
+		require.NoError(t, err)
+		assert.Len(t, s.Queries(), 2)
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		s, c := newTestCache(t, 2)
+		_, _, _, err := c.FetchDateRange(nil, &until, room101)
+		require.NoError(t, err)
+
+		// テスト対象の関数を実行
+		require.NoError(t, c.Clear())
+
+		files, err := os.ReadDir(c.dir)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+		_, _, _, err = c.FetchDateRange(nil, &until, room101)
+		require.NoError(t, err)
+		assert.Len(t, s.Queries(), 2)
+	})
+
+	t.Run("WithMaxAge", func(t *testing.T) {
+		s, c := newTestCache(t, 2, WithMaxAge(time.Hour))
+		_, _, _, err := c.FetchDateRange(nil, &until, room101)
+		require.NoError(t, err)
+
+		// 有効期間内はキャッシュを使用する
+		c.now = func() time.Time { return base.Add(24*time.Hour + 30*time.Minute) }
+		_, _, _, err = c.FetchDateRange(nil, &until, room101)
+		require.NoError(t, err)
+		assert.Len(t, s.Queries(), 1)
+
+		// テスト対象の関数を実行
+		c.now = func() time.Time { return base.Add(26 * time.Hour) }
+		_, _, _, err = c.FetchDateRange(nil, &until, room101)
+
+		require.NoError(t, err)
+		assert.Len(t, s.Queries(), 2)
+	})
+}
+
+func TestNewError(t *testing.T) {
+	s := fiaptest.NewServer()
+	t.Cleanup(s.Close)
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		fetcher  fiap.Fetcher
+		dir      string
+		expected string
+	}{
+		{name: "nil fetcher", fetcher: nil, dir: t.TempDir(), expected: "fetcher is nil"},
+		{name: "empty dir", fetcher: fiap.NewFetchClient(s.URL), dir: "", expected: "cache directory is empty"},
+		{name: "dir is a file", fetcher: fiap.NewFetchClient(s.URL), dir: filepath.Join(file, "cache"), expected: "failed to create cache directory"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// テスト対象の関数を実行
+			_, err := New(tc.fetcher, tc.dir)
+
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestCacheBrokenFile(t *testing.T) {
+	_, c := newTestCache(t, 1)
+	require.NoError(t, os.WriteFile(c.path(room101), []byte("{"), 0o644))
+
+	// テスト対象の関数を実行
+	_, _, _, err := c.FetchDateRange(nil, testutil.TimeToTimep(minutes(1)), room101)
+
+	assert.ErrorContains(t, err, "failed to parse cache file")
+}
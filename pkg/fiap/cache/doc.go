@@ -0,0 +1,21 @@
+/*
+Package cache keeps fetched time series in local files and fetches only the missing tail from the FIAP server.
+
+cacheパッケージは、FIAPサーバから取得した時系列データをpointごとにローカルのファイルに保存し、
+保存済みの範囲はファイルから返し、不足している末尾の範囲のみをFIAPサーバから取得するFetcherを提供します。
+
+Cacheは、FetchDateRangeのみをキャッシュします。その他のメソッドは、包んだFetcherをそのまま呼び出します。
+キャッシュは、ファイルに保存したvalueがすべて揃っている時刻の範囲(開始時刻と終了時刻)を記録します。
+終了時刻は、untilに過去の時刻を指定した場合はuntil、それ以外の場合は取得した最後のvalueの時刻です。
+続けて取得する場合は、終了時刻以降(gteq)のみをFIAPサーバに問い合わせ、終了時刻以降の保存済みのvalueを置き換えます。
+
+	c, err := cache.New(fiap.NewFetchClient(url), "/var/cache/fiap")
+	if err != nil {
+		return err
+	}
+	// 1回目はFIAPサーバからすべて取得し、2回目以降は前回の最後のvalue以降のみを取得する
+	_, points, fiapErr, err := c.FetchDateRange(&fromDate, nil, id)
+
+キャッシュを使用しない場合は、InvalidateまたはClearで保存したvalueを削除するか、WithMaxAgeで保存してからの有効期間を設定します。
+*/
+package cache
@@ -0,0 +1,303 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/globusdigital/soap"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+/*
+ErrNoInteraction is returned by Replayer when no recorded exchange matches the request.
+
+ErrNoInteractionは、送信するqueryRQに一致するやりとりが記録されていないことを表します。
+*/
+var ErrNoInteraction = errors.New("no recorded interaction matches the request")
+
+/*
+Cassette is a set of recorded FIAP exchanges.
+
+Cassetteは、記録したFIAPのやりとりの集まりです。JSONとしてファイルに保存します。
+*/
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+/*
+Interaction is a recorded pair of a request and its response.
+
+Interactionは、記録した1組のリクエストとレスポンスです。
+RequestとResponse.Bodyは、送受信したXMLをそのまま保持します。
+*/
+type Interaction struct {
+	URL      string   `json:"url"`
+	Request  string   `json:"request"`
+	Response Response `json:"response"`
+}
+
+/*
+Response is a recorded HTTP response.
+
+Responseは、記録したHTTPのレスポンスです。
+*/
+type Response struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+/*
+Load reads a cassette from the file.
+
+Loadは、ファイルからカセットを読み込みます。
+
+errの発生条件
+ - ファイルを読み込めない場合
+ - ファイルの内容がカセットのJSONとして解釈できない場合
+*/
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read cassette %s", path)
+	}
+	c := &Cassette{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse cassette %s", path)
+	}
+	return c, nil
+}
+
+/*
+Save writes the cassette to the file.
+
+Saveは、カセットをファイルに保存します。ファイルが存在する場合は上書きします。
+*/
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cassette")
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write cassette %s", path)
+	}
+	return nil
+}
+
+/*
+Recorder is an http.RoundTripper that records every exchange.
+
+Recorderは、送受信したすべてのやりとりを記録するhttp.RoundTripperです。NewRecorderで作成してください。
+複数のgoroutineから同時に使用できます。レスポンスを受信できなかったリクエストは記録しません。
+*/
+type Recorder struct {
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+/*
+NewRecorder returns a Recorder that sends requests with next.
+
+NewRecorderは、nextでリクエストを送信するRecorderを返します。nextがnilの場合はhttp.DefaultTransportを使用します。
+*/
+func NewRecorder(next http.RoundTripper) *Recorder {
+	return &Recorder{next: next}
+}
+
+/*
+RoundTrip implements http.RoundTripper.
+
+RoundTripは、http.RoundTripperインターフェースの実装です。
+*/
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := r.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read request")
+	}
+	res, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := readBody(&res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		URL:     req.URL.String(),
+		Request: string(reqBody),
+		Response: Response{
+			StatusCode:  res.StatusCode,
+			ContentType: res.Header.Get("Content-Type"),
+			Body:        string(resBody),
+		},
+	})
+	return res, nil
+}
+
+/*
+Cassette returns a copy of the exchanges recorded so far.
+
+Cassetteは、これまでに記録したやりとりのコピーを返します。
+*/
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]Interaction{}, r.cassette.Interactions...)}
+}
+
+/*
+Replayer is an http.RoundTripper that answers requests from a cassette.
+
+Replayerは、カセットに記録したレスポンスを返すhttp.RoundTripperです。NewReplayerで作成してください。
+
+送信するqueryRQとクエリのidを除いて一致するやりとりを探します。一致するやりとりが複数ある場合は記録した順に返し、
+すべて返した後は最後のやりとりを繰り返し返します。一致するやりとりがない場合は、ErrNoInteractionを返します。
+*/
+type Replayer struct {
+	mu           sync.Mutex
+	interactions map[string][]Interaction
+	used         map[string]int
+}
+
+/*
+NewReplayer returns a Replayer for the cassette.
+
+NewReplayerは、cassetteを再生するReplayerを返します。
+
+errの発生条件
+ - 記録されたリクエストがqueryRQとして解釈できない場合
+*/
+func NewReplayer(c *Cassette) (*Replayer, error) {
+	r := &Replayer{interactions: make(map[string][]Interaction), used: make(map[string]int)}
+	for i, interaction := range c.Interactions {
+		key, err := matchKey([]byte(interaction.Request))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid request in interaction %d", i)
+		}
+		r.interactions[key] = append(r.interactions[key], interaction)
+	}
+	return r, nil
+}
+
+/*
+RoundTrip implements http.RoundTripper.
+
+RoundTripは、http.RoundTripperインターフェースの実装です。
+*/
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read request")
+	}
+	key, err := matchKey(reqBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse request")
+	}
+
+	r.mu.Lock()
+	interactions := r.interactions[key]
+	i := r.used[key]
+	if i < len(interactions)-1 {
+		r.used[key] = i + 1
+	}
+	r.mu.Unlock()
+	if len(interactions) == 0 {
+		return nil, errors.WithDetailf(ErrNoInteraction, "request: %s", reqBody)
+	}
+
+	recorded := interactions[i].Response
+	header := make(http.Header)
+	if recorded.ContentType != "" {
+		header.Set("Content-Type", recorded.ContentType)
+	}
+	return &http.Response{
+		Status:        http.StatusText(recorded.StatusCode),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+/*
+WithRecorder sets the FetchClient to record exchanges with the recorder.
+
+WithRecorderは、FetchClientがrecorderでやりとりを記録するように設定します。FetchClientのHTTPClientを置き換えます。
+*/
+func WithRecorder(recorder *Recorder) fiap.Option {
+	return fiap.WithHTTPClient(&http.Client{Transport: recorder})
+}
+
+/*
+WithReplay sets the FetchClient to answer requests from the cassette instead of the FIAP server.
+
+WithReplayは、FetchClientがFIAPサーバに接続せず、cassetteに記録したレスポンスを使用するように設定します。
+FetchClientのHTTPClientを置き換えます。カセットが不正な場合は、すべてのリクエストがエラーになります。
+*/
+func WithReplay(c *Cassette) fiap.Option {
+	replayer, err := NewReplayer(c)
+	if err != nil {
+		return fiap.WithHTTPClient(&http.Client{Transport: failingTransport{err}})
+	}
+	return fiap.WithHTTPClient(&http.Client{Transport: replayer})
+}
+
+// failingTransport はすべてのリクエストをerrで失敗させる
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// readBody はbodyをすべて読み込み、同じ内容を再び読めるように置き換える
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, err
+}
+
+// matchKey はqueryRQをクエリのidを除いて文字列化し、やりとりを探すためのキーにする
+func matchKey(request []byte) (string, error) {
+	queryRQ := &model.QueryRQ{}
+	if err := xml.Unmarshal(request, &soap.Envelope{Body: soap.Body{Content: queryRQ}}); err != nil {
+		return "", err
+	}
+	if queryRQ.Transport == nil || queryRQ.Transport.Header == nil || queryRQ.Transport.Header.Query == nil {
+		return "", errors.New("query is not found")
+	}
+	query := *queryRQ.Transport.Header.Query
+	query.Id = ""
+	key, err := xml.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
@@ -0,0 +1,74 @@
+package cassette
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/fiaptest"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+const room101 = "http://xxxxxxxx/tokyo/building1/Room101/"
+
+func TestRecordAndReplay(t *testing.T) {
+	server := fiaptest.NewServer()
+	base := time.Date(2012, 2, 2, 16, 34, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		server.AddPoint(room101, model.Value{Time: base.Add(time.Duration(i) * time.Minute), Value: "v"})
+	}
+	connectionURL := server.URL
+	keys := []model.UserInputKey{{ID: room101}}
+	option := &model.FetchOption{AcceptableSize: 2}
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	// 記録
+	recorder := NewRecorder(nil)
+	_, expected, fiapErr, err := fiap.NewFetchClient(connectionURL, WithRecorder(recorder)).Fetch(keys, option)
+	require.NoError(t, err)
+	require.Nil(t, fiapErr)
+	require.Len(t, expected[room101], 5)
+	require.Len(t, recorder.Cassette().Interactions, 3)
+	require.NoError(t, recorder.Cassette().Save(path))
+	server.Close()
+
+	c, err := Load(path)
+	require.NoError(t, err)
+
+	t.Run("replay cursor chain", func(t *testing.T) {
+		// テスト対象の関数を実行
+		_, actual, fiapErr, err := fiap.NewFetchClient(connectionURL, WithReplay(c)).Fetch(keys, option)
+
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("no interaction", func(t *testing.T) {
+		// テスト対象の関数を実行
+		_, _, _, err := fiap.NewFetchClient(connectionURL, WithReplay(c)).Fetch(keys, &model.FetchOption{AcceptableSize: 3})
+
+		assert.ErrorIs(t, err, ErrNoInteraction)
+		assert.ErrorIs(t, err, fiap.ErrTransport)
+	})
+
+	t.Run("invalid cassette", func(t *testing.T) {
+		invalid := &Cassette{Interactions: []Interaction{{Request: "not xml"}}}
+
+		_, err := NewReplayer(invalid)
+		assert.ErrorContains(t, err, "invalid request in interaction 0")
+
+		_, _, _, err = fiap.NewFetchClient(connectionURL, WithReplay(invalid)).Fetch(keys, option)
+		assert.ErrorIs(t, err, fiap.ErrTransport)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+
+	assert.ErrorContains(t, err, "failed to read cassette")
+}
@@ -0,0 +1,21 @@
+/*
+Package cassette records FIAP exchanges to a file and replays them without the FIAP server.
+
+cassetteパッケージは、FIAPサーバとのやりとりをファイル(カセット)に記録し、FIAPサーバに接続せずに再生する機能を提供します。
+
+Recorderは、送信したqueryRQと受信したqueryRSをすべて記録するhttp.RoundTripperです。cursorで続けて取得したやりとりも記録します。
+Replayerは、記録したやりとりから送信するqueryRQに一致するものを探して、queryRSを返すhttp.RoundTripperです。
+queryRQはクエリのidを除いて比較するため、同じkeysとoptionで取得すれば記録したときと同じ結果が得られます。
+
+	// 記録
+	recorder := cassette.NewRecorder(nil)
+	fetchClient := fiap.NewFetchClient(url, cassette.WithRecorder(recorder))
+	fetchClient.Fetch(keys, nil)
+	recorder.Cassette().Save("session.json")
+
+	// 再生
+	c, err := cassette.Load("session.json")
+	fetchClient := fiap.NewFetchClient(url, cassette.WithReplay(c))
+	fetchClient.Fetch(keys, nil)
+*/
+package cassette
@@ -0,0 +1,349 @@
+package conformance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+/*
+DefaultAcceptableSize is the acceptableSize used by the pagination checks when Target.AcceptableSize is 0.
+
+DefaultAcceptableSizeは、Target.AcceptableSizeが0の場合に、acceptableSizeとページングの検査で使用するacceptableSizeです。
+*/
+const DefaultAcceptableSize uint = 2
+
+/*
+Status is a type for the result of a check.
+
+Statusは、検査の結果を表す型です。この型の値を指定する場合は、StatusPassなどの定数を使用してください。
+*/
+type Status string
+
+const (
+	// StatusPass は検査に合格したことを表す
+	StatusPass Status = "pass"
+	// StatusFail は検査に不合格だったことを表す
+	StatusFail Status = "fail"
+	// StatusSkip は時系列データの数が足りないなどの理由で検査しなかったことを表す
+	StatusSkip Status = "skip"
+)
+
+/*
+Result is the result of a check.
+
+Resultは、1つの検査の結果です。Messageは、不合格または検査しなかった理由、合格した場合は確認した内容です。
+*/
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+/*
+Report is the result of Run.
+
+Reportは、Runの結果です。Resultsは検査を実行した順に並んでいます。
+*/
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+/*
+OK reports whether no check failed.
+
+OKは、不合格の検査がない場合にtrueを返します。検査しなかったものは不合格に含めません。
+*/
+func (r Report) OK() bool {
+	return len(r.Failed()) == 0
+}
+
+/*
+Failed returns the results of the failed checks.
+
+Failedは、不合格だった検査の結果を返します。
+*/
+func (r Report) Failed() []Result {
+	failed := make([]Result, 0)
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+/*
+Target specifies the points used by Run.
+
+Targetは、Runで検査に使用するpointなどを指定する型です。
+
+PointIDは、時系列データが登録されているpointのIDです。範囲やページングを検査するため、異なる時刻の値が3つ以上あることを推奨します。
+
+PointSetIDは、子のpointSetまたはpointを持つpointSetのIDです。空の場合はpointSetの検査を行いません。
+
+UnknownPointIDは、FIAPサーバに存在しないpointのIDです。空の場合はPointIDの末尾に文字列を付け加えたIDを使用します。
+
+AcceptableSizeは、acceptableSizeとページングの検査で使用するacceptableSizeです。0の場合はDefaultAcceptableSizeを使用します。
+*/
+type Target struct {
+	PointID        string
+	PointSetID     string
+	UnknownPointID string
+	AcceptableSize uint
+}
+
+/*
+Run checks the FIAP server with the fetcher and returns the report.
+
+Runは、fetcherでFIAPサーバに問い合わせて、次の検査を順に実行した結果を返します。
+ - select max: selectがmaximumのkeyで、最新の値が1つだけ返ること
+ - select min: selectがminimumのkeyで、最古の値が1つだけ返ること
+ - range inclusive: gteqとlteqの境界と同じ時刻の値が含まれること
+ - range exclusive: gtとltの境界と同じ時刻の値が含まれないこと
+ - acceptable size: 1回の応答の値がacceptableSize以下で、残りがある場合のみcursorが返ること
+ - cursor pagination: cursorで続けて取得した値が、まとめて取得した値と一致すること
+ - pointset listing: pointSetの子のpointSetまたはpointが返ること
+ - unknown point: 存在しないpointに対してPOINT_NOT_FOUNDのerrorが返ること
+
+各検査は、target.PointIDのすべての値を取得した結果と比較して判定します。
+
+errの発生条件
+ - target.PointIDが空の場合
+ - target.PointIDのすべての値を取得できない場合、または値が1つもない場合
+*/
+func Run(fetcher fiap.Fetcher, target Target) (Report, error) {
+	if target.PointID == "" {
+		return Report{}, errors.New("point id is empty")
+	}
+	_, points, fiapErr, err := fetcher.Fetch([]model.UserInputKey{{ID: target.PointID}}, nil)
+	if err != nil {
+		return Report{}, errors.Wrapf(err, "failed to fetch all values of %s", target.PointID)
+	}
+	if fiapErr != nil {
+		return Report{}, errors.Wrapf(fiap.NewFIAPError(fiapErr), "failed to fetch all values of %s", target.PointID)
+	}
+	all := sortValues(points[target.PointID])
+	if len(all) == 0 {
+		return Report{}, errors.Newf("point %s has no values", target.PointID)
+	}
+
+	c := &checker{fetcher: fetcher, target: target, all: all}
+	if c.target.AcceptableSize == 0 {
+		c.target.AcceptableSize = DefaultAcceptableSize
+	}
+	if c.target.UnknownPointID == "" {
+		c.target.UnknownPointID = strings.TrimSuffix(target.PointID, "/") + "/go-fiap-client-conformance-unknown-point"
+	}
+	checks := []struct {
+		name string
+		run  func() (Status, string)
+	}{
+		{"select max", c.selectMax},
+		{"select min", c.selectMin},
+		{"range inclusive", c.rangeInclusive},
+		{"range exclusive", c.rangeExclusive},
+		{"acceptable size", c.acceptableSize},
+		{"cursor pagination", c.cursorPagination},
+		{"pointset listing", c.pointSetListing},
+		{"unknown point", c.unknownPoint},
+	}
+	report := Report{Results: make([]Result, 0, len(checks))}
+	for _, check := range checks {
+		status, message := check.run()
+		report.Results = append(report.Results, Result{Name: check.name, Status: status, Message: message})
+	}
+	return report, nil
+}
+
+// checker は検査に必要な情報を保持する。allはtarget.PointIDのすべての値を時刻順に並べたもの
+type checker struct {
+	fetcher fiap.Fetcher
+	target  Target
+	all     []model.Value
+}
+
+func (c *checker) selectMax() (Status, string) {
+	return c.selectOne(model.SelectTypeMaximum, c.all[len(c.all)-1].Time)
+}
+
+func (c *checker) selectMin() (Status, string) {
+	return c.selectOne(model.SelectTypeMinimum, c.all[0].Time)
+}
+
+// selectOne はselectを指定したkeyで、expectedの時刻の値が1つだけ返ることを検査する
+func (c *checker) selectOne(selectType model.SelectType, expected time.Time) (Status, string) {
+	values, message := c.fetchValues(model.UserInputKey{ID: c.target.PointID, MinMaxIndicator: selectType})
+	if message != "" {
+		return StatusFail, message
+	}
+	if len(values) != 1 {
+		return StatusFail, fmt.Sprintf("expected 1 value, got %d", len(values))
+	}
+	if !values[0].Time.Equal(expected) {
+		return StatusFail, fmt.Sprintf("expected value at %s, got %s", formatTime(expected), formatTime(values[0].Time))
+	}
+	return StatusPass, fmt.Sprintf("value at %s", formatTime(expected))
+}
+
+func (c *checker) rangeInclusive() (Status, string) {
+	from, until := c.all[0].Time, c.all[len(c.all)-1].Time
+	values, message := c.fetchValues(model.UserInputKey{ID: c.target.PointID, Gteq: &from, Lteq: &until})
+	if message != "" {
+		return StatusFail, message
+	}
+	return compareValues(c.all, values)
+}
+
+func (c *checker) rangeExclusive() (Status, string) {
+	from, until := c.all[0].Time, c.all[len(c.all)-1].Time
+	if !from.Before(until) {
+		return StatusSkip, "needs values at two or more times"
+	}
+	values, message := c.fetchValues(model.UserInputKey{ID: c.target.PointID, Gt: &from, Lt: &until})
+	if message != "" {
+		return StatusFail, message
+	}
+	expected := make([]model.Value, 0, len(c.all))
+	for _, v := range c.all {
+		if v.Time.After(from) && v.Time.Before(until) {
+			expected = append(expected, v)
+		}
+	}
+	return compareValues(expected, values)
+}
+
+func (c *checker) acceptableSize() (Status, string) {
+	size := c.target.AcceptableSize
+	_, points, cursor, fiapErr, err := c.fetcher.FetchOnce([]model.UserInputKey{{ID: c.target.PointID}}, &model.FetchOnceOption{AcceptableSize: size})
+	if message := failureMessage(fiapErr, err); message != "" {
+		return StatusFail, message
+	}
+	count := len(points[c.target.PointID])
+	switch {
+	case uint(count) > size:
+		return StatusFail, fmt.Sprintf("got %d values with acceptableSize %d", count, size)
+	case uint(len(c.all)) > size && cursor == "":
+		return StatusFail, fmt.Sprintf("no cursor returned although %d values exceed acceptableSize %d", len(c.all), size)
+	case uint(len(c.all)) <= size && cursor != "":
+		return StatusFail, fmt.Sprintf("cursor returned although %d values fit in acceptableSize %d", len(c.all), size)
+	}
+	return StatusPass, fmt.Sprintf("%d values with acceptableSize %d", count, size)
+}
+
+func (c *checker) cursorPagination() (Status, string) {
+	size := c.target.AcceptableSize
+	if uint(len(c.all)) <= size {
+		return StatusSkip, fmt.Sprintf("needs more than %d values", size)
+	}
+	keys := []model.UserInputKey{{ID: c.target.PointID}}
+	values := make([]model.Value, 0, len(c.all))
+	cursor := ""
+	// 応答ごとに少なくとも1つの値が返るため、値の数より多くの応答は必要ない
+	for pages := 1; ; pages++ {
+		if pages > len(c.all)+1 {
+			return StatusFail, fmt.Sprintf("cursor chain did not end after %d pages", pages-1)
+		}
+		_, points, next, fiapErr, err := c.fetcher.FetchOnce(keys, &model.FetchOnceOption{AcceptableSize: size, Cursor: cursor})
+		if message := failureMessage(fiapErr, err); message != "" {
+			return StatusFail, fmt.Sprintf("page %d: %s", pages, message)
+		}
+		values = append(values, points[c.target.PointID]...)
+		if next == "" {
+			status, message := compareValues(c.all, values)
+			if status == StatusPass {
+				message = fmt.Sprintf("%s in %d pages", message, pages)
+			}
+			return status, message
+		}
+		cursor = next
+	}
+}
+
+func (c *checker) pointSetListing() (Status, string) {
+	if c.target.PointSetID == "" {
+		return StatusSkip, "no pointSet specified"
+	}
+	pointSets, _, fiapErr, err := c.fetcher.Fetch([]model.UserInputKey{{ID: c.target.PointSetID}}, nil)
+	if message := failureMessage(fiapErr, err); message != "" {
+		return StatusFail, message
+	}
+	pointSet, ok := pointSets[c.target.PointSetID]
+	if !ok {
+		return StatusFail, fmt.Sprintf("pointSet %s is not returned", c.target.PointSetID)
+	}
+	if len(pointSet.PointSetID) == 0 && len(pointSet.PointID) == 0 {
+		return StatusFail, fmt.Sprintf("pointSet %s has no children", c.target.PointSetID)
+	}
+	return StatusPass, fmt.Sprintf("%d pointSets and %d points", len(pointSet.PointSetID), len(pointSet.PointID))
+}
+
+func (c *checker) unknownPoint() (Status, string) {
+	_, _, fiapErr, err := c.fetcher.Fetch([]model.UserInputKey{{ID: c.target.UnknownPointID}}, nil)
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	if fiapErr == nil {
+		return StatusFail, fmt.Sprintf("no error returned for %s", c.target.UnknownPointID)
+	}
+	if fiapErr.Type != "POINT_NOT_FOUND" {
+		return StatusFail, fmt.Sprintf("expected POINT_NOT_FOUND, got %s", fiapErr.Type)
+	}
+	return StatusPass, "POINT_NOT_FOUND"
+}
+
+// fetchValues はkeyでtarget.PointIDの値を取得する。失敗した場合は理由を返す
+func (c *checker) fetchValues(key model.UserInputKey) ([]model.Value, string) {
+	_, points, fiapErr, err := c.fetcher.Fetch([]model.UserInputKey{key}, nil)
+	if message := failureMessage(fiapErr, err); message != "" {
+		return nil, message
+	}
+	return points[c.target.PointID], ""
+}
+
+// failureMessage はFetcherのメソッドが返したエラーを検査結果のメッセージに変換する。エラーがない場合は空文字列を返す
+func failureMessage(fiapErr *model.Error, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if fiapErr != nil {
+		return fiap.NewFIAPError(fiapErr).Error()
+	}
+	return ""
+}
+
+// compareValues はactualが順序を除いてexpectedと一致するかを判定する
+func compareValues(expected []model.Value, actual []model.Value) (Status, string) {
+	if len(actual) != len(expected) {
+		return StatusFail, fmt.Sprintf("expected %d values, got %d", len(expected), len(actual))
+	}
+	actual = sortValues(actual)
+	for i := range expected {
+		if !actual[i].Time.Equal(expected[i].Time) || actual[i].Value != expected[i].Value {
+			return StatusFail, fmt.Sprintf("expected %q at %s, got %q at %s", expected[i].Value, formatTime(expected[i].Time), actual[i].Value, formatTime(actual[i].Time))
+		}
+	}
+	return StatusPass, fmt.Sprintf("%d values", len(expected))
+}
+
+// sortValues はvaluesを時刻と値の順に並べ替えたコピーを返す
+func sortValues(values []model.Value) []model.Value {
+	sorted := append([]model.Value{}, values...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Time.Equal(sorted[j].Time) {
+			return sorted[i].Time.Before(sorted[j].Time)
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+	return sorted
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
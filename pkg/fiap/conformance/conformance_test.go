@@ -0,0 +1,152 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/fiaptest"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+const (
+	building1 = "http://xxxxxxxx/tokyo/building1/"
+	room101   = "http://xxxxxxxx/tokyo/building1/Room101/"
+	room102   = "http://xxxxxxxx/tokyo/building1/Room102/"
+)
+
+// newTestServer はRoom101に1分間隔の5つのvalue、Room102に1つのvalueを持つServerを返す
+func newTestServer(t *testing.T) *fiaptest.Server {
+	base := time.Date(2012, 2, 2, 16, 34, 0, 0, time.UTC)
+	s := fiaptest.NewServer()
+	t.Cleanup(s.Close)
+	for i := 0; i < 5; i++ {
+		s.AddPoint(room101, model.Value{Time: base.Add(time.Duration(i) * time.Minute), Value: string(rune('0' + i))})
+	}
+	s.AddPoint(room102, model.Value{Time: base, Value: "0"})
+	s.AddPointSet(building1, nil, []string{room101, room102})
+	return s
+}
+
+// inclusiveFetcher はgtとltをgteqとlteqに置き換えて問い合わせる、仕様に従わないFetcher
+type inclusiveFetcher struct {
+	fiap.Fetcher
+}
+
+func (f inclusiveFetcher) Fetch(keys []model.UserInputKey, option *model.FetchOption) (map[string](model.ProcessedPointSet), map[string]([]model.Value), *model.Error, error) {
+	for i := range keys {
+		if keys[i].Gt != nil {
+			keys[i].Gteq, keys[i].Gt = keys[i].Gt, nil
+		}
+		if keys[i].Lt != nil {
+			keys[i].Lteq, keys[i].Lt = keys[i].Lt, nil
+		}
+	}
+	return f.Fetcher.Fetch(keys, option)
+}
+
+// statuses は検査の名前と結果の対応を返す
+func statuses(report Report) map[string]Status {
+	result := make(map[string]Status, len(report.Results))
+	for _, r := range report.Results {
+		result[r.Name] = r.Status
+	}
+	return result
+}
+
+func TestRun(t *testing.T) {
+	s := newTestServer(t)
+
+	// テストケースを定義
+	tests := []struct {
+		name     string
+		fetcher  fiap.Fetcher
+		target   Target
+		expected map[string]Status
+	}{
+		{
+			name:    "conformant server",
+			fetcher: fiap.NewFetchClient(s.URL),
+			target:  Target{PointID: room101, PointSetID: building1},
+			expected: map[string]Status{
+				"select max":        StatusPass,
+				"select min":        StatusPass,
+				"range inclusive":   StatusPass,
+				"range exclusive":   StatusPass,
+				"acceptable size":   StatusPass,
+				"cursor pagination": StatusPass,
+				"pointset listing":  StatusPass,
+				"unknown point":     StatusPass,
+			},
+		},
+		{
+			name:    "single value",
+			fetcher: fiap.NewFetchClient(s.URL),
+			target:  Target{PointID: room102},
+			expected: map[string]Status{
+				"select max":        StatusPass,
+				"select min":        StatusPass,
+				"range inclusive":   StatusPass,
+				"range exclusive":   StatusSkip,
+				"acceptable size":   StatusPass,
+				"cursor pagination": StatusSkip,
+				"pointset listing":  StatusSkip,
+				"unknown point":     StatusPass,
+			},
+		},
+		{
+			name:    "inclusive boundaries for gt and lt",
+			fetcher: inclusiveFetcher{fiap.NewFetchClient(s.URL)},
+			target:  Target{PointID: room101, UnknownPointID: room102, AcceptableSize: 10},
+			expected: map[string]Status{
+				"select max":        StatusPass,
+				"select min":        StatusPass,
+				"range inclusive":   StatusPass,
+				"range exclusive":   StatusFail,
+				"acceptable size":   StatusPass,
+				"cursor pagination": StatusSkip,
+				"pointset listing":  StatusSkip,
+				"unknown point":     StatusFail,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// テスト対象の関数を実行
+			report, err := Run(tt.fetcher, tt.target)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, statuses(report))
+			assert.Equal(t, len(report.Failed()) == 0, report.OK())
+		})
+	}
+}
+
+func TestRunError(t *testing.T) {
+	s := newTestServer(t)
+	s.AddPoint("http://xxxxxxxx/tokyo/building1/Room103/")
+
+	// テストケースを定義
+	tests := []struct {
+		name     string
+		target   Target
+		expected string
+	}{
+		{name: "empty point id", target: Target{}, expected: "point id is empty"},
+		{name: "unknown point", target: Target{PointID: "http://xxxxxxxx/tokyo/building1/Room999/"}, expected: "POINT_NOT_FOUND"},
+		{name: "no values", target: Target{PointID: "http://xxxxxxxx/tokyo/building1/Room103/"}, expected: "has no values"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// テスト対象の関数を実行
+			_, err := Run(fiap.NewFetchClient(s.URL), tt.target)
+
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+/*
+Package conformance checks whether a FIAP server answers FETCH queries as the FIAP specification requires.
+
+conformanceパッケージは、FIAPサーバがFIAPの仕様どおりにFETCHのクエリに応答するかを検査する機能を提供します。
+
+Runは、指定したpointの時系列データをFetcherで取得し、select、範囲の境界、acceptableSize、cursorによるページング、
+pointSetの子の一覧、存在しないpointに対するerrorについて検査した結果を返します。
+ストレージ製品の評価など、接続先のFIAPサーバの実装を確認する用途を想定しています。
+
+	report, err := conformance.Run(fiap.NewFetchClient(url), conformance.Target{PointID: id})
+	for _, result := range report.Results {
+		fmt.Println(result.Status, result.Name, result.Message)
+	}
+*/
+package conformance
@@ -0,0 +1,58 @@
+package fiap
+
+import (
+	"sort"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/cockroachdb/errors"
+)
+
+// deduplicatePoints はpolicyに従ってpointsの各時系列データを時刻順に並べ替え、重複を取り除く
+func deduplicatePoints(points map[string]([]model.Value), policy model.DeduplicationPolicy) error {
+	switch policy {
+	case model.DeduplicationNone:
+		return nil
+	case model.DeduplicationKeepFirst, model.DeduplicationKeepLast, model.DeduplicationError:
+	default:
+		return errors.Newf("unknown deduplication policy: %s", policy)
+	}
+
+	for id, values := range points {
+		deduplicated, err := deduplicateValues(values, policy)
+		if err != nil {
+			return errors.Wrapf(err, "failed to deduplicate point '%s'", id)
+		}
+		points[id] = deduplicated
+	}
+	return nil
+}
+
+// deduplicateValues は受信順を保ったまま時刻順に並べ替え、同じ時刻のデータをpolicyに従って1つにまとめる
+func deduplicateValues(values []model.Value, policy model.DeduplicationPolicy) ([]model.Value, error) {
+	sorted := make([]model.Value, len(values))
+	copy(sorted, values)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	result := make([]model.Value, 0, len(sorted))
+	for _, v := range sorted {
+		last := len(result) - 1
+		if last < 0 || !result[last].Time.Equal(v.Time) {
+			result = append(result, v)
+			continue
+		}
+		if result[last].Value == v.Value {
+			continue
+		}
+		// 同じ時刻で値が異なる場合
+		switch policy {
+		case model.DeduplicationKeepLast:
+			result[last] = v
+		case model.DeduplicationError:
+			return nil, errors.Newf("conflicting values at %s: '%s' and '%s'", v.Time.Format(time.RFC3339Nano), result[last].Value, v.Value)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,261 @@
+package fiap
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+func TestFetchOnceDeduplication(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	f := &FetchClient{ConnectionURL: connectionURL}
+	jst := time.FixedZone("", 9*60*60)
+
+	// 同じpointが繰り返し返され、時刻順が乱れていて、重複と競合を含むレスポンス
+	body := `
+	<body>
+		<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+			<value time="2012-02-02T16:36:05.000+09:00">32</value>
+			<value time="2012-02-02T16:34:05.000+09:00">30</value>
+		</point>
+		<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+			<value time="2012-02-02T16:34:05.000+09:00">30</value>
+			<value time="2012-02-02T16:35:05.000+09:00">31</value>
+			<value time="2012-02-02T16:36:05.000+09:00">33</value>
+		</point>
+	</body>
+	`
+
+	// テストケースを定義
+	testCases := []struct {
+		name           string
+		policy         model.DeduplicationPolicy
+		expectedValues []model.Value
+		expectError    bool
+	}{
+		{
+			name:   "none",
+			policy: model.DeduplicationNone,
+			expectedValues: []model.Value{
+				{Time: time.Date(2012, 2, 2, 16, 36, 5, 0, jst), Value: "32"},
+				{Time: time.Date(2012, 2, 2, 16, 34, 5, 0, jst), Value: "30"},
+				{Time: time.Date(2012, 2, 2, 16, 34, 5, 0, jst), Value: "30"},
+				{Time: time.Date(2012, 2, 2, 16, 35, 5, 0, jst), Value: "31"},
+				{Time: time.Date(2012, 2, 2, 16, 36, 5, 0, jst), Value: "33"},
+			},
+		},
+		{
+			name:   "keep first",
+			policy: model.DeduplicationKeepFirst,
+			expectedValues: []model.Value{
+				{Time: time.Date(2012, 2, 2, 16, 34, 5, 0, jst), Value: "30"},
+				{Time: time.Date(2012, 2, 2, 16, 35, 5, 0, jst), Value: "31"},
+				{Time: time.Date(2012, 2, 2, 16, 36, 5, 0, jst), Value: "32"},
+			},
+		},
+		{
+			name:   "keep last",
+			policy: model.DeduplicationKeepLast,
+			expectedValues: []model.Value{
+				{Time: time.Date(2012, 2, 2, 16, 34, 5, 0, jst), Value: "30"},
+				{Time: time.Date(2012, 2, 2, 16, 35, 5, 0, jst), Value: "31"},
+				{Time: time.Date(2012, 2, 2, 16, 36, 5, 0, jst), Value: "33"},
+			},
+		},
+		{
+			name:        "error",
+			policy:      model.DeduplicationError,
+			expectError: true,
+		},
+		{
+			name:        "unknown policy",
+			policy:      "keep_middle",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// mockの有効化
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			// 下記URLにPOSTしたときの挙動を定義
+			httpmock.RegisterResponder("POST", connectionURL, testutil.CustomBodyResponder(body))
+
+			// テスト対象の関数を実行
+			_, points, _, _, err := f.FetchOnce(
+				[]model.UserInputKey{
+					{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+				},
+				&model.FetchOnceOption{Deduplication: tc.policy},
+			)
+
+			if tc.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, points)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, map[string][]model.Value{
+				"http://xxxxxxxx/tokyo/building1/Room101/": tc.expectedValues,
+			}, points)
+		})
+	}
+}
+
+func TestFetchDeduplicationAcrossPages(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	f := &FetchClient{ConnectionURL: connectionURL}
+	jst := time.FixedZone("", 9*60*60)
+
+	// 1ページ目と2ページ目で範囲が重複しているレスポンス
+	firstPage := testutil.CustomHeaderBodyResponder(`
+	<header>
+		<OK/>
+		<query id="e3264a29-b4a6-41dd-a6bb-cbf57b76e571" type="storage" cursor="a93f7094-4fd1-8e9a-749c-08e222bb0afb">
+			<key id="http://xxxxxxxx/tokyo/building1/Room101/" attrName="time"/>
+		</query>
+	</header>
+	<body>
+		<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+			<value time="2012-02-02T16:35:05.000+09:00">31</value>
+			<value time="2012-02-02T16:34:05.000+09:00">30</value>
+		</point>
+	</body>
+	`)
+	secondPage := testutil.CustomHeaderBodyResponder(`
+	<header>
+		<OK/>
+		<query id="e3264a29-b4a6-41dd-a6bb-cbf57b76e571" type="storage">
+			<key id="http://xxxxxxxx/tokyo/building1/Room101/" attrName="time"/>
+		</query>
+	</header>
+	<body>
+		<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+			<value time="2012-02-02T16:35:05.000+09:00">31</value>
+			<value time="2012-02-02T16:36:05.000+09:00">32</value>
+		</point>
+	</body>
+	`)
+
+	// mockの有効化
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	// リクエストにcursorが含まれているかどうかでレスポンスを切り替える
+	httpmock.RegisterResponder("POST", connectionURL, func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Contains(body, []byte("cursor")) {
+			return secondPage(req)
+		}
+		return firstPage(req)
+	})
+
+	// テスト対象の関数を実行
+	_, points, fiapErr, err := f.Fetch([]model.UserInputKey{
+		{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+	}, &model.FetchOption{Deduplication: model.DeduplicationError})
+
+	assert.NoError(t, err)
+	assert.Nil(t, fiapErr)
+	assert.Equal(t, map[string][]model.Value{
+		"http://xxxxxxxx/tokyo/building1/Room101/": {
+			{Time: time.Date(2012, 2, 2, 16, 34, 5, 0, jst), Value: "30"},
+			{Time: time.Date(2012, 2, 2, 16, 35, 5, 0, jst), Value: "31"},
+			{Time: time.Date(2012, 2, 2, 16, 36, 5, 0, jst), Value: "32"},
+		},
+	}, points)
+	assert.Equal(t, 2, httpmock.GetTotalCallCount())
+}
+
+func TestFetchDeduplicationBeforeFIAPError(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	f := &FetchClient{ConnectionURL: connectionURL}
+	jst := time.FixedZone("", 9*60*60)
+
+	// 1ページ目は重複して逆順のvalueを返し、2ページ目はerrorを返す
+	firstPage := testutil.CustomHeaderBodyResponder(`
+	<header>
+		<OK/>
+		<query id="e3264a29-b4a6-41dd-a6bb-cbf57b76e571" type="storage" cursor="a93f7094-4fd1-8e9a-749c-08e222bb0afb">
+			<key id="http://xxxxxxxx/tokyo/building1/Room101/" attrName="time"/>
+		</query>
+	</header>
+	<body>
+		<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+			<value time="2012-02-02T16:35:05.000+09:00">31</value>
+			<value time="2012-02-02T16:34:05.000+09:00">30</value>
+			<value time="2012-02-02T16:35:05.000+09:00">32</value>
+		</point>
+	</body>
+	`)
+	secondPage := testutil.CustomHeaderBodyResponder(`
+	<header>
+		<error type="INVALID_CURSOR">The cursor is expired.</error>
+	</header>
+	`)
+
+	// mockの有効化
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	// リクエストにcursorが含まれているかどうかでレスポンスを切り替える
+	httpmock.RegisterResponder("POST", connectionURL, func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Contains(body, []byte("cursor")) {
+			return secondPage(req)
+		}
+		return firstPage(req)
+	})
+
+	// テスト対象の関数を実行
+	_, points, fiapErr, err := f.Fetch([]model.UserInputKey{
+		{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+	}, &model.FetchOption{Deduplication: model.DeduplicationKeepLast})
+
+	assert.NoError(t, err)
+	require.NotNil(t, fiapErr)
+	assert.Equal(t, "INVALID_CURSOR", fiapErr.Type)
+	// errorを受信するまでに取得したvalueも、並べ替えて重複を取り除く
+	assert.Equal(t, map[string][]model.Value{
+		"http://xxxxxxxx/tokyo/building1/Room101/": {
+			{Time: time.Date(2012, 2, 2, 16, 34, 5, 0, jst), Value: "30"},
+			{Time: time.Date(2012, 2, 2, 16, 35, 5, 0, jst), Value: "32"},
+		},
+	}, points)
+}
+
+func TestFetchNilOption(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	f := &FetchClient{ConnectionURL: connectionURL}
+
+	// mockの有効化
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", connectionURL, testutil.CustomBodyResponder(`<body></body>`))
+
+	// optionにnilを指定してもpanicしないことを確認する
+	_, points, _, err := f.Fetch([]model.UserInputKey{
+		{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]model.Value{}, points)
+}
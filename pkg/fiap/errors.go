@@ -0,0 +1,277 @@
+package fiap
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+/*
+Sentinel errors returned by the client. Use errors.Is to check the kind of failure.
+
+クライアントが返すエラーの種類を表すエラーです。errors.Isで失敗の種類を判定できます。
+*/
+var (
+	// ErrInvalidURL は接続先のURLが http:// または https:// で始まっていないことを表す
+	ErrInvalidURL = errors.New("invalid url")
+	// ErrEmptyKeys はkeysまたはidsが空であることを表す
+	ErrEmptyKeys = errors.New("empty keys")
+	// ErrEmptyID はkeyのIDが空であることを表す
+	ErrEmptyID = errors.New("empty id")
+	// ErrInvalidKey はkeyがFIAPの仕様の制約を満たしていないことを表す。詳細はValidateKeysを参照
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrInvalidQuery はQueryBuilderで組み立てたクエリの条件が矛盾していることを表す
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrTransport はレスポンスを受信できなかったことを表す。接続の失敗やタイムアウトなど
+	ErrTransport = errors.New("transport error")
+	// ErrHTTPStatus はHTTPのステータスコードが2xxでないことを表す。詳細はHTTPStatusErrorで取得できる
+	ErrHTTPStatus = errors.New("unexpected http status")
+	// ErrSOAPFault はSOAP Faultを受信したことを表す。詳細はSOAPFaultErrorで取得できる
+	ErrSOAPFault = errors.New("soap fault")
+	// ErrMalformedResponse はレスポンスがFIAPのqueryRSとして解釈できないことを表す
+	ErrMalformedResponse = errors.New("malformed response")
+	// ErrFIAP はFIAPサーバがerrorを返したことを表す。詳細はFIAPErrorで取得できる
+	ErrFIAP = errors.New("fiap error")
+	// ErrPointNotFound はFIAPサーバがPOINT_NOT_FOUNDのerrorを返したことを表す
+	ErrPointNotFound = errors.New("fiap error: POINT_NOT_FOUND")
+	// ErrQueryNotSupported はFIAPサーバがQUERY_NOT_SUPPORTEDのerrorを返したことを表す
+	ErrQueryNotSupported = errors.New("fiap error: QUERY_NOT_SUPPORTED")
+)
+
+// fiapErrorTypes はFIAPのerrorのtypeと、それを表すエラーの対応
+var fiapErrorTypes = map[string]error{
+	"POINT_NOT_FOUND":     ErrPointNotFound,
+	"QUERY_NOT_SUPPORTED": ErrQueryNotSupported,
+}
+
+/*
+FIAPError is an error returned by a FIAP server in the <error> element.
+
+FIAPErrorは、FIAPサーバが<error>タグで返したエラーです。
+
+errors.Is(err, ErrFIAP)はすべてのFIAPErrorで真になります。
+errors.Is(err, ErrPointNotFound)のように、typeに対応するエラーとも比較できます。
+*/
+type FIAPError struct {
+	Type    string
+	Message string
+}
+
+func (e *FIAPError) Error() string {
+	return fmt.Sprintf("fiap error: type %s, value %s", e.Type, e.Message)
+}
+
+/*
+Is reports whether the target is ErrFIAP or the error corresponding to the type.
+
+Isは、targetがErrFIAPまたはtypeに対応するエラーの場合に真を返します。
+*/
+func (e *FIAPError) Is(target error) bool {
+	if target == ErrFIAP {
+		return true
+	}
+	typeErr, ok := fiapErrorTypes[e.Type]
+	return ok && typeErr == target
+}
+
+/*
+NewFIAPError converts fiapErr returned by the fetch methods into an error.
+
+NewFIAPErrorは、取得メソッドが返したfiapErrをerrorに変換します。fiapErrがnilの場合はnilを返します。
+*/
+func NewFIAPError(fiapErr *model.Error) error {
+	if fiapErr == nil {
+		return nil
+	}
+	return &FIAPError{Type: fiapErr.Type, Message: fiapErr.Value}
+}
+
+/*
+HTTPStatusError is an error representing a response with a non-2xx HTTP status.
+
+HTTPStatusErrorは、HTTPのステータスコードが2xxでないレスポンスを表すエラーです。
+errors.Is(err, ErrHTTPStatus)で判定できます。
+*/
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("http status: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+/*
+Is reports whether the target is ErrHTTPStatus.
+
+Isは、targetがErrHTTPStatusの場合に真を返します。
+*/
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrHTTPStatus
+}
+
+/*
+SOAPFaultError is an error representing a SOAP 1.1 or SOAP 1.2 Fault.
+
+SOAPFaultErrorは、SOAP 1.1またはSOAP 1.2のFaultを表すエラーです。errors.Is(err, ErrSOAPFault)で判定できます。
+
+ - Version: SOAPのバージョン。"1.1"または"1.2"
+ - Code: SOAP 1.1のfaultcode、SOAP 1.2のCode/Value
+ - Subcode: SOAP 1.2のCode/Subcode/Value。SOAP 1.1では""
+ - String: SOAP 1.1のfaultstring、SOAP 1.2のReason/Text
+ - Actor: SOAP 1.1のfaultactor、SOAP 1.2のNode
+ - Detail: SOAP 1.1のdetail、SOAP 1.2のDetailの内容のXML
+*/
+type SOAPFaultError struct {
+	Version string
+	Code    string
+	Subcode string
+	String  string
+	Actor   string
+	Detail  string
+}
+
+func (e *SOAPFaultError) Error() string {
+	code := e.Code
+	if e.Subcode != "" {
+		code += "/" + e.Subcode
+	}
+	msg := fmt.Sprintf("soap fault: code %s, string %s", code, e.String)
+	if e.Detail != "" {
+		msg += ", detail " + bodySnippet([]byte(e.Detail))
+	}
+	return msg
+}
+
+/*
+Is reports whether the target is ErrSOAPFault.
+
+Isは、targetがErrSOAPFaultの場合に真を返します。
+*/
+func (e *SOAPFaultError) Is(target error) bool {
+	return target == ErrSOAPFault
+}
+
+/*
+MalformedResponseError is an error representing a response which is not a FIAP queryRS.
+
+MalformedResponseErrorは、FIAPのqueryRSとして解釈できないレスポンスを表すエラーです。
+errors.Is(err, ErrMalformedResponse)で判定できます。
+
+レスポンスがSOAPのメッセージでない場合、Bodyにはレスポンスの先頭を切り詰めた内容が格納されます。
+*/
+type MalformedResponseError struct {
+	Body string
+}
+
+func (e *MalformedResponseError) Error() string {
+	if e.Body == "" {
+		return "malformed response"
+	}
+	return "malformed response, body: " + e.Body
+}
+
+/*
+Is reports whether the target is ErrMalformedResponse.
+
+Isは、targetがErrMalformedResponseの場合に真を返します。
+*/
+func (e *MalformedResponseError) Is(target error) bool {
+	return target == ErrMalformedResponse
+}
+
+// markedError はerrors.Isとerrors.Asで判定できるエラーをerrに結びつける。detailはメッセージに付け加える
+type markedError struct {
+	err    error
+	detail string
+	marks  []error
+}
+
+// markError はメッセージを変えずに、errにmarksを結びつける
+func markError(err error, marks ...error) error {
+	return &markedError{err: err, marks: marks}
+}
+
+func (e *markedError) Error() string {
+	if e.detail == "" {
+		return e.err.Error()
+	}
+	return e.err.Error() + ": " + e.detail
+}
+
+func (e *markedError) Unwrap() []error {
+	return append([]error{e.err}, e.marks...)
+}
+
+// isSuccessStatus はステータスコードが2xxの場合に真を返す
+func isSuccessStatus(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// errHook はHookが返したエラーを表す。通信のエラーとは区別する
+var errHook = errors.New("hook error")
+
+// classifyCallError はSOAP通信のエラーに失敗の種類を結びつける。
+// httpResponseとbodyは受信したレスポンスとその内容で、受信できなかった場合はnil
+func classifyCallError(err error, httpResponse *http.Response, body []byte) error {
+	if errors.Is(err, errHook) {
+		return err
+	}
+	if httpResponse == nil {
+		return markError(err, ErrTransport)
+	}
+	// soapパッケージのエラーはレスポンス全体を含むため、受信した内容から作成し直す
+	switch {
+	case parseSOAPFault(body) != nil:
+		err = errors.New("received SOAP fault")
+	case !isSOAPEnvelope(body):
+		err = errors.New("response is not a SOAP message")
+	}
+	return responseError(err, httpResponse, body)
+}
+
+// responseError はqueryRSとして解釈できないレスポンスの内容から失敗の詳細を作成し、errに結びつける
+func responseError(err error, httpResponse *http.Response, body []byte) error {
+	marked := &markedError{err: err}
+	if fault := parseSOAPFault(body); fault != nil {
+		marked.marks = append(marked.marks, fault)
+		marked.detail = fault.Error()
+	} else if len(body) > 0 && !isSOAPEnvelope(body) {
+		malformed := &MalformedResponseError{Body: bodySnippet(body)}
+		marked.marks = append(marked.marks, malformed)
+		marked.detail = malformed.Error()
+	} else {
+		marked.marks = append(marked.marks, &MalformedResponseError{})
+	}
+	if httpResponse != nil && !isSuccessStatus(httpResponse.StatusCode) {
+		marked.marks = append(marked.marks, &HTTPStatusError{StatusCode: httpResponse.StatusCode})
+	}
+	return marked
+}
+
+// responseBody はSOAP通信の終了後に保持したレスポンスの内容を返す
+func responseBody(httpResponse *http.Response) []byte {
+	if httpResponse == nil || httpResponse.Body == nil {
+		return nil
+	}
+	body, _ := io.ReadAll(httpResponse.Body)
+	httpResponse.Body = io.NopCloser(bytes.NewReader(body))
+	return body
+}
+
+// errorType はメトリクスのerror.typeに設定する失敗の種類を返す
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, ErrSOAPFault):
+		return errorTypeSOAPFault
+	case errors.Is(err, ErrMalformedResponse):
+		return errorTypeMalformedResponse
+	case errors.Is(err, ErrTransport):
+		return errorTypeTransport
+	}
+	return errorTypeOther
+}
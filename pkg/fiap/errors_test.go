@@ -0,0 +1,215 @@
+package fiap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+func TestFIAPError(t *testing.T) {
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		fiapErr  *model.Error
+		is       []error
+		isNot    []error
+		expected string
+	}{
+		{
+			name:     "POINT_NOT_FOUND",
+			fiapErr:  &model.Error{Type: "POINT_NOT_FOUND", Value: "point is not found"},
+			is:       []error{ErrFIAP, ErrPointNotFound},
+			isNot:    []error{ErrQueryNotSupported, ErrMalformedResponse},
+			expected: "fiap error: type POINT_NOT_FOUND, value point is not found",
+		},
+		{
+			name:     "QUERY_NOT_SUPPORTED",
+			fiapErr:  &model.Error{Type: "QUERY_NOT_SUPPORTED", Value: "trap is not supported"},
+			is:       []error{ErrFIAP, ErrQueryNotSupported},
+			isNot:    []error{ErrPointNotFound},
+			expected: "fiap error: type QUERY_NOT_SUPPORTED, value trap is not supported",
+		},
+		{
+			name:     "unknown type",
+			fiapErr:  &model.Error{Type: "UNKNOWN", Value: "unknown"},
+			is:       []error{ErrFIAP},
+			isNot:    []error{ErrPointNotFound, ErrQueryNotSupported},
+			expected: "fiap error: type UNKNOWN, value unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewFIAPError(tc.fiapErr)
+
+			assert.EqualError(t, err, tc.expected)
+			for _, target := range tc.is {
+				assert.ErrorIs(t, err, target)
+			}
+			for _, target := range tc.isNot {
+				assert.NotErrorIs(t, err, target)
+			}
+			var fiapError *FIAPError
+			assert.True(t, errors.As(err, &fiapError))
+			assert.Equal(t, tc.fiapErr.Type, fiapError.Type)
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		assert.NoError(t, NewFIAPError(nil))
+	})
+}
+
+func TestFetchOnceErrorKinds(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	keys := []model.UserInputKey{{ID: "http://xxxxxxxx/tokyo/building1/Room101/"}}
+
+	// テストケースを定義
+	testCases := []struct {
+		name           string
+		connectionURL  string
+		keys           []model.UserInputKey
+		responder      httpmock.Responder
+		is             []error
+		isNot          []error
+		wantStatusCode int
+	}{
+		{
+			name:          "invalid url",
+			connectionURL: "ftp://example.com",
+			keys:          keys,
+			is:            []error{ErrInvalidURL},
+			isNot:         []error{ErrEmptyKeys, ErrTransport},
+		},
+		{
+			name:          "empty keys",
+			connectionURL: connectionURL,
+			keys:          []model.UserInputKey{},
+			is:            []error{ErrEmptyKeys},
+		},
+		{
+			name:          "empty id",
+			connectionURL: connectionURL,
+			keys:          []model.UserInputKey{{ID: ""}},
+			is:            []error{ErrEmptyID},
+		},
+		{
+			name:          "transport",
+			connectionURL: connectionURL,
+			keys:          keys,
+			responder:     httpmock.NewErrorResponder(errors.New("connection refused")),
+			is:            []error{ErrTransport},
+			isNot:         []error{ErrHTTPStatus, ErrMalformedResponse},
+		},
+		{
+			name:           "http status with soap body",
+			connectionURL:  connectionURL,
+			keys:           keys,
+			responder:      testutil.CustomTransportStatusCodeResponder("", 503),
+			is:             []error{ErrHTTPStatus, ErrMalformedResponse},
+			isNot:          []error{ErrTransport, ErrSOAPFault},
+			wantStatusCode: 503,
+		},
+		{
+			name:           "http status with html body",
+			connectionURL:  connectionURL,
+			keys:           keys,
+			responder:      httpmock.NewStringResponder(500, "<html><body>Internal Server Error</body></html>"),
+			is:             []error{ErrHTTPStatus, ErrMalformedResponse},
+			wantStatusCode: 500,
+		},
+		{
+			name:          "malformed response",
+			connectionURL: connectionURL,
+			keys:          keys,
+			responder:     testutil.CustomTransportResponder(""),
+			is:            []error{ErrMalformedResponse},
+			isNot:         []error{ErrHTTPStatus},
+		},
+		{
+			name:          "soap fault",
+			connectionURL: connectionURL,
+			keys:          keys,
+			responder: httpmock.NewStringResponder(500, `<?xml version='1.0' encoding='utf-8'?>
+			<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/">
+				<soapenv:Body>
+					<soapenv:Fault>
+						<faultcode>soapenv:Server</faultcode>
+						<faultstring>internal error</faultstring>
+					</soapenv:Fault>
+				</soapenv:Body>
+			</soapenv:Envelope>`),
+			is:             []error{ErrSOAPFault, ErrHTTPStatus},
+			isNot:          []error{ErrMalformedResponse, ErrTransport},
+			wantStatusCode: 500,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewFetchClient(tc.connectionURL)
+
+			// mockの有効化
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			if tc.responder != nil {
+				httpmock.RegisterResponder("POST", tc.connectionURL, tc.responder)
+			}
+
+			// テスト対象の関数を実行
+			_, _, _, _, err := f.FetchOnce(tc.keys, nil)
+
+			assert.Error(t, err)
+			for _, target := range tc.is {
+				assert.ErrorIs(t, err, target)
+			}
+			for _, target := range tc.isNot {
+				assert.NotErrorIs(t, err, target)
+			}
+			if tc.wantStatusCode != 0 {
+				var statusErr *HTTPStatusError
+				if assert.True(t, errors.As(err, &statusErr)) {
+					assert.Equal(t, tc.wantStatusCode, statusErr.StatusCode)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchByIdsWithKeyEmptyIDs(t *testing.T) {
+	f := NewFetchClient(defaultConnectionURL)
+
+	_, _, _, err := f.FetchByIdsWithKey(model.UserInputKeyNoID{})
+
+	assert.ErrorIs(t, err, ErrEmptyKeys)
+}
+
+func TestHookErrorIsNotClassified(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	f := NewFetchClient(connectionURL, WithHooks(HookFuncs{
+		AfterReceiveFunc: func(ctx context.Context, res *SOAPResponse) error {
+			return assert.AnError
+		},
+	}))
+
+	// mockの有効化
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", connectionURL, httpmock.NewStringResponder(http.StatusOK, ""))
+
+	// テスト対象の関数を実行
+	_, _, _, _, err := f.FetchOnce([]model.UserInputKey{{ID: "http://xxxxxxxx/tokyo/building1/Room101/"}}, nil)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NotErrorIs(t, err, ErrTransport)
+	assert.NotErrorIs(t, err, ErrMalformedResponse)
+}
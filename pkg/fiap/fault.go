@@ -0,0 +1,161 @@
+package fiap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxCapturedBodySize はエラーの詳細を作成するために保持するレスポンスの最大バイト数
+const maxCapturedBodySize = 64 * 1024
+
+// maxBodySnippetSize はエラーメッセージに含めるレスポンスの最大バイト数
+const maxBodySnippetSize = 512
+
+// SOAPのエンベロープの名前空間
+const (
+	soap11EnvelopeNamespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12EnvelopeNamespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// capturingDoFn は受信したレスポンスをresに保持するようにdoFnを包む。
+// レスポンスのBodyは先頭からlimitバイトまで(limitが負の場合はすべて)保持し、SOAP通信の終了後に読み直せるようにする
+func capturingDoFn(doFn func(*http.Request) (*http.Response, error), res **http.Response, body *bytes.Buffer, limit int) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		r, err := doFn(req)
+		if err == nil {
+			*res = r
+			if r.Body != nil {
+				r.Body = &capturingReadCloser{ReadCloser: r.Body, buf: body, limit: limit}
+			}
+		}
+		return r, err
+	}
+}
+
+// capturingReadCloser は読み込んだ内容をlimitバイトまで(limitが負の場合はすべて)bufに書き込む
+type capturingReadCloser struct {
+	io.ReadCloser
+	buf   *bytes.Buffer
+	limit int
+}
+
+func (c *capturingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if c.limit < 0 {
+		c.buf.Write(p[:n])
+	} else if rest := c.limit - c.buf.Len(); rest > 0 {
+		c.buf.Write(p[:min(n, rest)])
+	}
+	return n, err
+}
+
+// soapFault はSOAP 1.1とSOAP 1.2のFaultを解釈するための構造体
+type soapFault struct {
+	// SOAP 1.1
+	FaultCode   string   `xml:"faultcode"`
+	FaultString string   `xml:"faultstring"`
+	FaultActor  string   `xml:"faultactor"`
+	Detail11    innerXML `xml:"detail"`
+
+	// SOAP 1.2
+	Code struct {
+		Value   string `xml:"Value"`
+		Subcode struct {
+			Value string `xml:"Value"`
+		} `xml:"Subcode"`
+	} `xml:"Code"`
+	Reason struct {
+		Text []string `xml:"Text"`
+	} `xml:"Reason"`
+	Node     string   `xml:"Node"`
+	Detail12 innerXML `xml:"Detail"`
+}
+
+type innerXML struct {
+	Inner string `xml:",innerxml"`
+}
+
+// parseSOAPFault はbodyからSOAP Faultを探して解釈する。Faultが含まれていない場合はnilを返す
+func parseSOAPFault(body []byte) *SOAPFaultError {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil
+		}
+		se, ok := token.(xml.StartElement)
+		if !ok || se.Name.Local != "Fault" {
+			continue
+		}
+		if se.Name.Space != soap11EnvelopeNamespace && se.Name.Space != soap12EnvelopeNamespace {
+			continue
+		}
+		fault := &soapFault{}
+		if err := d.DecodeElement(fault, &se); err != nil {
+			return nil
+		}
+		if se.Name.Space == soap12EnvelopeNamespace {
+			return &SOAPFaultError{
+				Version: "1.2",
+				Code:    strings.TrimSpace(fault.Code.Value),
+				Subcode: strings.TrimSpace(fault.Code.Subcode.Value),
+				String:  strings.TrimSpace(strings.Join(fault.Reason.Text, " ")),
+				Actor:   strings.TrimSpace(fault.Node),
+				Detail:  strings.TrimSpace(fault.Detail12.Inner),
+			}
+		}
+		return &SOAPFaultError{
+			Version: "1.1",
+			Code:    strings.TrimSpace(fault.FaultCode),
+			String:  strings.TrimSpace(fault.FaultString),
+			Actor:   strings.TrimSpace(fault.FaultActor),
+			Detail:  strings.TrimSpace(fault.Detail11.Inner),
+		}
+	}
+}
+
+// isSOAPEnvelope はbodyの最初の要素がSOAPのエンベロープの場合に真を返す
+func isSOAPEnvelope(body []byte) bool {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return false
+		}
+		if se, ok := token.(xml.StartElement); ok {
+			return se.Name.Local == "Envelope" &&
+				(se.Name.Space == soap11EnvelopeNamespace || se.Name.Space == soap12EnvelopeNamespace)
+		}
+	}
+}
+
+// bodySnippet はエラーメッセージに含めるため、bodyを1行にまとめてmaxBodySnippetSizeバイトまでに切り詰める
+func bodySnippet(body []byte) string {
+	truncated := len(body) > maxBodySnippetSize
+	if truncated {
+		body = body[:maxBodySnippetSize]
+		// 途中で切れたマルチバイト文字を取り除く
+		if start := lastRuneStart(body); !utf8.FullRune(body[start:]) {
+			body = body[:start]
+		}
+	}
+	snippet := strings.Join(strings.Fields(strings.ToValidUTF8(string(body), "�")), " ")
+	if truncated {
+		snippet += "..."
+	}
+	return snippet
+}
+
+// lastRuneStart はbodyの最後の文字の開始位置を返す
+func lastRuneStart(body []byte) int {
+	for i := len(body) - 1; i >= 0; i-- {
+		if utf8.RuneStart(body[i]) {
+			return i
+		}
+	}
+	return 0
+}
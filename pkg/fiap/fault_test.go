@@ -0,0 +1,200 @@
+package fiap
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+func TestFetchOnceSOAPFault(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+
+	// テストケースを定義
+	testCases := []struct {
+		name        string
+		status      int
+		body        string
+		expected    SOAPFaultError
+		wantMessage string
+	}{
+		{
+			name:   "SOAP 1.1",
+			status: 500,
+			body: `<?xml version='1.0' encoding='utf-8'?>
+			<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/">
+				<soapenv:Body>
+					<soapenv:Fault>
+						<faultcode>soapenv:Server</faultcode>
+						<faultstring>database is not available</faultstring>
+						<faultactor>http://example.com/actor</faultactor>
+						<detail><reason>timeout</reason></detail>
+					</soapenv:Fault>
+				</soapenv:Body>
+			</soapenv:Envelope>`,
+			expected: SOAPFaultError{
+				Version: "1.1",
+				Code:    "soapenv:Server",
+				String:  "database is not available",
+				Actor:   "http://example.com/actor",
+				Detail:  "<reason>timeout</reason>",
+			},
+			wantMessage: "soap fault: code soapenv:Server, string database is not available, detail <reason>timeout</reason>",
+		},
+		{
+			name:   "SOAP 1.2",
+			status: 500,
+			body: `<?xml version='1.0' encoding='utf-8'?>
+			<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope">
+				<env:Body>
+					<env:Fault>
+						<env:Code>
+							<env:Value>env:Sender</env:Value>
+							<env:Subcode><env:Value>m:MessageTimeout</env:Value></env:Subcode>
+						</env:Code>
+						<env:Reason><env:Text xml:lang="en">Sender Timeout</env:Text></env:Reason>
+						<env:Node>http://example.com/node</env:Node>
+						<env:Detail><m:MaxTime xmlns:m="http://example.com/timeouts">P5M</m:MaxTime></env:Detail>
+					</env:Fault>
+				</env:Body>
+			</env:Envelope>`,
+			expected: SOAPFaultError{
+				Version: "1.2",
+				Code:    "env:Sender",
+				Subcode: "m:MessageTimeout",
+				String:  "Sender Timeout",
+				Actor:   "http://example.com/node",
+				Detail:  `<m:MaxTime xmlns:m="http://example.com/timeouts">P5M</m:MaxTime>`,
+			},
+			wantMessage: "soap fault: code env:Sender/m:MessageTimeout, string Sender Timeout",
+		},
+		{
+			name:   "SOAP 1.1 with status 200",
+			status: 200,
+			body: `<?xml version='1.0' encoding='utf-8'?>
+			<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/">
+				<soapenv:Body>
+					<soapenv:Fault>
+						<faultcode>soapenv:Client</faultcode>
+						<faultstring>invalid query</faultstring>
+					</soapenv:Fault>
+				</soapenv:Body>
+			</soapenv:Envelope>`,
+			expected: SOAPFaultError{
+				Version: "1.1",
+				Code:    "soapenv:Client",
+				String:  "invalid query",
+			},
+			wantMessage: "soap fault: code soapenv:Client, string invalid query",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewFetchClient(connectionURL)
+
+			// mockの有効化
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterResponder("POST", connectionURL, httpmock.NewStringResponder(tc.status, tc.body))
+
+			// テスト対象の関数を実行
+			_, _, _, _, err := f.FetchOnce([]model.UserInputKey{
+				{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+			}, nil)
+
+			assert.ErrorIs(t, err, ErrSOAPFault)
+			assert.NotErrorIs(t, err, ErrMalformedResponse)
+			assert.Equal(t, tc.status != 200, errors.Is(err, ErrHTTPStatus))
+			var fault *SOAPFaultError
+			if assert.True(t, errors.As(err, &fault)) {
+				assert.Equal(t, tc.expected, *fault)
+			}
+			assert.Contains(t, err.Error(), tc.wantMessage)
+		})
+	}
+}
+
+func TestFetchOnceNonSOAPResponse(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+
+	t.Run("html error page", func(t *testing.T) {
+		f := NewFetchClient(connectionURL)
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, httpmock.NewStringResponder(502, `<html>
+			<head><title>502 Bad Gateway</title></head>
+			<body><h1>Bad Gateway</h1></body>
+		</html>`))
+
+		// テスト対象の関数を実行
+		_, _, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		assert.ErrorIs(t, err, ErrMalformedResponse)
+		assert.ErrorIs(t, err, ErrHTTPStatus)
+		assert.Contains(t, err.Error(), "response is not a SOAP message")
+		assert.Contains(t, err.Error(), "body: <html> <head><title>502 Bad Gateway</title></head> <body><h1>Bad Gateway</h1></body> </html>")
+		var malformed *MalformedResponseError
+		if assert.True(t, errors.As(err, &malformed)) {
+			assert.True(t, strings.HasPrefix(malformed.Body, "<html>"))
+		}
+	})
+
+	t.Run("large body is truncated", func(t *testing.T) {
+		f := NewFetchClient(connectionURL)
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, httpmock.NewStringResponder(500, strings.Repeat("あ", 1000)))
+
+		// テスト対象の関数を実行
+		_, _, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		var malformed *MalformedResponseError
+		if assert.True(t, errors.As(err, &malformed)) {
+			assert.Equal(t, strings.Repeat("あ", maxBodySnippetSize/3)+"...", malformed.Body)
+		}
+	})
+
+	t.Run("soap message without transport", func(t *testing.T) {
+		f := NewFetchClient(connectionURL)
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, testutil.CustomTransportStatusCodeResponder("", 500))
+
+		// テスト対象の関数を実行
+		_, _, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		assert.ErrorIs(t, err, ErrMalformedResponse)
+		assert.ErrorIs(t, err, ErrHTTPStatus)
+		assert.Contains(t, err.Error(), "queryRS.Transport is nil, http status: 500")
+		assert.NotContains(t, err.Error(), "body:")
+	})
+}
+
+func TestBodySnippet(t *testing.T) {
+	assert.Equal(t, "a b c", bodySnippet([]byte(" a\n\tb  c \n")))
+	assert.Equal(t, strings.Repeat("x", maxBodySnippetSize)+"...", bodySnippet([]byte(strings.Repeat("x", maxBodySnippetSize+1))))
+	// マルチバイト文字の途中で切り詰めない
+	assert.Equal(t, "x"+strings.Repeat("あ", (maxBodySnippetSize-1)/3)+"...", bodySnippet([]byte("x"+strings.Repeat("あ", 1000))))
+}
@@ -1,12 +1,16 @@
 package fiap
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
 	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
 	"github.com/cockroachdb/errors"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 /*
@@ -39,9 +43,45 @@ type Fetcher interface {
 FetchClient is a client struct for fetching data from a FIAP server.
 
 FetchClientはFIAPサーバからデータを取得するためのクライアント構造体です。
+
+ConnectionURLは、接続先のFIAPサーバのURLです。
+
+Locationを指定すると、取得した時系列データの時刻とFIAPサーバに送信するkeyの時刻をLocationのタイムゾーンに揃えます。
+nilの場合は、FIAPサーバが返したタイムゾーンのまま返します。
+
+Loggerは、ログの出力に使用する*slog.Loggerです。nilの場合はtools.Logger()を使用します。
+送受信したデータの内容は、tools.LevelTraceのレベルでのみ出力されます。
+
+HTTPClientは、リクエストの送信に使用する*http.Clientです。nilの場合はhttp.DefaultClientを使用します。
+
+TracerProviderとMeterProviderは、OpenTelemetryのspanとメトリクスの記録に使用します。
+nilの場合はotel.GetTracerProvider()とotel.GetMeterProvider()で取得したグローバルのProviderを使用します。
+グローバルのProviderを設定していない場合は、何も記録されません。
+
+Hooksは、SOAPのリクエストの送信前とレスポンスの受信後に呼び出すHookです。
+
+TimeLayoutは、FIAPサーバに送信するkeyの時刻の書式です。空文字の場合はtime.RFC3339を使用し、秒未満は切り捨てられます。
+秒未満の精度で範囲を指定する場合はtime.RFC3339Nanoを指定します。受信した時系列データの時刻は、TimeLayoutによらず秒未満も含めて解釈します。
+
+各項目は、NewFetchClientとOptionを使用して設定することもできます。
 */
 type FetchClient struct {
-	ConnectionURL string
+	ConnectionURL  string
+	Location       *time.Location
+	Logger         *slog.Logger
+	HTTPClient     *http.Client
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	Hooks          []Hook
+	TimeLayout     string
+}
+
+// logger はログの出力に使用する*slog.Loggerを返す
+func (f *FetchClient) logger() *slog.Logger {
+	if f.Logger != nil {
+		return f.Logger
+	}
+	return tools.Logger()
 }
 
 /*
@@ -66,12 +106,43 @@ Fetchは、与えられたキーとオプションを使用してFIAPサーバ
  
 errの発生条件
   - fetchOnceメソッドでエラーが発生した場合
+  - option.Deduplicationにmodel.DeduplicationErrorを指定し、同じ時刻で値が異なるデータを受信した場合
 */
 func (f *FetchClient) Fetch(keys []model.UserInputKey, option *model.FetchOption) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
-	tools.LogPrintf(tools.LogLevelDebug, "Fetch start, connectionURL: %s, keys: %v, option: %#v\n", f.ConnectionURL, keys, option)
+	result, err := f.fetch(context.Background(), keys, option)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return result.PointSets, result.Points, result.FIAPError, nil
+}
 
-	pointSets = make(map[string](model.ProcessedPointSet))
-	points = make(map[string]([]model.Value))
+// fetch はFetchの処理を行う。ctxは作成するspanの親として使用する
+func (f *FetchClient) fetch(ctx context.Context, keys []model.UserInputKey, option *model.FetchOption) (result *FetchResult, err error) {
+	logger := f.logger()
+	start := time.Now()
+	logger.Debug("Fetch start", "url", f.ConnectionURL, "key_count", len(keys))
+
+	var fiapErr *model.Error
+	ctx, span := f.tracer().Start(ctx, "fiap.Fetch", trace.WithAttributes(
+		attrURL.String(f.ConnectionURL),
+		attrKeyCount.Int(len(keys)),
+	))
+	defer func() { endSpan(span, fiapErr, err) }()
+
+	// デフォルト値の設定
+	if option == nil {
+		option = &model.FetchOption{}
+	}
+
+	result = &FetchResult{
+		PointSets: make(map[string](model.ProcessedPointSet)),
+		Points:    make(map[string]([]model.Value)),
+		StartTime: start,
+	}
+	pointSets, points := result.PointSets, result.Points
+	if option.Tree {
+		result.Trees = make(map[string]*model.PointSetTree)
+	}
 
 	// cursorの初期化
 	cursor := ""
@@ -80,17 +151,31 @@ func (f *FetchClient) Fetch(keys []model.UserInputKey, option *model.FetchOption
 	i := 0
 	for {
 		i++
+		// contextがキャンセルされた場合は、次のページを取得しない
+		if err := ctx.Err(); err != nil {
+			err = errors.Wrapf(err, "context is done before loop iteration %d", i)
+			logger.Error("Fetch failed", "url", f.ConnectionURL, "page", i, "cursor", cursor, "error", err)
+			return nil, err
+		}
 		// FetchOnceを実行
-		fetchOnceOption := &model.FetchOnceOption{AcceptableSize: option.AcceptableSize, Cursor: cursor}
-		fetchOncePointSets, fetchOncePoints, newCursor, fiapErr, err := f.FetchOnce(keys, fetchOnceOption)
+		fetchOnceOption := &model.FetchOnceOption{AcceptableSize: option.AcceptableSize, Cursor: cursor, Tree: option.Tree}
+		page, err := f.fetchOnce(ctx, keys, fetchOnceOption)
 		if err != nil {
 			err = errors.Wrapf(err, "FetchOnce error on loop iteration %d", i)
-			tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
-			return nil, nil, nil, err
+			logger.Error("Fetch failed", "url", f.ConnectionURL, "page", i, "cursor", cursor, "error", err)
+			return nil, err
 		}
-		if fiapErr != nil {
-			return pointSets, points, fiapErr, nil
+		result.PageCount = i
+		result.QueryIDs = append(result.QueryIDs, page.QueryIDs...)
+		if page.FIAPError != nil {
+			fiapErr = page.FIAPError
+			logger.Warn("Fetch received fiap error", "url", f.ConnectionURL, "page", i, "cursor", cursor, "fiap_error_type", fiapErr.Type, "fiap_error", fiapErr.Value)
+			result.FIAPError = fiapErr
+			// それまでに取得したページも、重複を取り除いてから返す
+			break
 		}
+		fetchOncePointSets, fetchOncePoints, newCursor := page.PointSets, page.Points, page.Cursor
+		logger.Debug("Fetch page received", "url", f.ConnectionURL, "page", i, "cursor", cursor, "next_cursor", newCursor, "value_count", countValues(fetchOncePoints))
 
 		// pointSetにデータを追加
 		for key, value := range fetchOncePointSets {
@@ -114,14 +199,36 @@ func (f *FetchClient) Fetch(keys []model.UserInputKey, option *model.FetchOption
 			// pointsのkeyが設定されていない場合にはデータを加工せず代入する
 			points[key] = tempValues
 		}
+		// 木構造にデータを追加
+		if result.Trees != nil {
+			mergeTrees(result.Trees, page.Trees)
+		}
 
 		if newCursor == "" {
 			break
 		}
 		cursor = newCursor
 	}
-	tools.LogPrintf(tools.LogLevelDebug, "Fetch end, pointSets: %v, points: %v\n", pointSets, points)
-	return pointSets, points, fiapErr, err
+
+	// ページをまたいだ重複を取り除く
+	if err := deduplicatePoints(points, option.Deduplication); err != nil {
+		err = errors.Wrap(err, "deduplicatePoints error")
+		logger.Error("Fetch failed", "url", f.ConnectionURL, "error", err)
+		return nil, err
+	}
+	if err := deduplicateTrees(result.Trees, option.Deduplication); err != nil {
+		err = errors.Wrap(err, "deduplicateTrees error")
+		logger.Error("Fetch failed", "url", f.ConnectionURL, "error", err)
+		return nil, err
+	}
+	result.Duration = time.Since(start)
+	if fiapErr != nil {
+		return result, nil
+	}
+	span.SetAttributes(attrPageCount.Int(i), attrValueCount.Int(countValues(points)))
+	logger.Info("Fetch end", "url", f.ConnectionURL, "page_count", i, "point_set_count", len(pointSets), "point_count", len(points), "value_count", countValues(points), "duration", result.Duration)
+	logger.Log(ctx, tools.LevelTrace, "Fetch result", "point_sets", pointSets, "points", points)
+	return result, nil
 }
 
 /*
@@ -180,25 +287,92 @@ errの発生条件
  - queryRS.Transportがnilの場合(processQueryRS内でエラー): データが取得できていないためエラーとし、その原因を特定するためにhttp status codeを表示する
  - queryRS.Transport.Headerがnilの場合(processQueryRS内でエラー): SOAP通信に成功した場合はHeader内にokまたはerrorが格納されるためHeaderがnilの場合はエラーとし、その原因を特定するためhttp status codeを表示する
  - queryRS.Transport.Header.OKがnilでなく、queryRS.Transport.Bodyがnilの場合(processQueryRS内でエラー): SOAP通信に成功した場合はBody内にデータが格納されるためBodyがnilの場合はエラーとし、その原因を特定するためにhttp status codeを表示する
+ - queryRS.Transport.HeaderにOKとerrorのどちらもない場合(processQueryRS内でエラー)
+ - queryRS.Transport.BodyのpointSet、pointのIDが空または長すぎる場合、valueに時刻がない場合、cursorが長すぎる場合(processQueryRS内でエラー)
+ - option.Deduplicationにmodel.DeduplicationErrorを指定し、同じ時刻で値が異なるデータを受信した場合
 */
 func (f *FetchClient) FetchOnce(keys []model.UserInputKey, option *model.FetchOnceOption) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), cursor string, fiapErr *model.Error, err error) {
-	tools.LogPrintf(tools.LogLevelDebug, "FetchOnce start, connectionURL: %s, keys: %v, option: %#v\n", f.ConnectionURL, keys, option)
+	result, err := f.fetchOnce(context.Background(), keys, option)
+	if err != nil {
+		return nil, nil, "", nil, err
+	}
+	return result.PointSets, result.Points, result.Cursor, result.FIAPError, nil
+}
+
+// fetchOnce はFetchOnceの処理を行う。ctxは作成するspanの親として使用する
+func (f *FetchClient) fetchOnce(ctx context.Context, keys []model.UserInputKey, option *model.FetchOnceOption) (result *FetchResult, err error) {
+	logger := f.logger()
+	start := time.Now()
+	logger.Debug("FetchOnce start", "url", f.ConnectionURL, "key_count", len(keys))
 
-	httpResponse, body, err := fiapFetch(f.ConnectionURL, keys, option)
+	requestCursor := ""
+	if option != nil {
+		requestCursor = option.Cursor
+	}
+	ctx, span := f.tracer().Start(ctx, "fiap.FetchOnce", trace.WithAttributes(
+		attrURL.String(f.ConnectionURL),
+		attrKeyCount.Int(len(keys)),
+		attrCursor.String(requestCursor),
+	))
+	var fiapErr *model.Error
+	defer func() { endSpan(span, fiapErr, err) }()
+	inst := f.instruments()
+
+	httpResponse, body, queryID, err := f.fiapFetch(ctx, keysInLocation(keys, f.Location), option)
 	if err != nil {
 		err = errors.Wrap(err, "fiapFetch error")
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
-		return nil, nil, "", nil, err
+		logger.Error("FetchOnce failed", "url", f.ConnectionURL, "error", err)
+		return nil, err
 	}
 
-	pointSets, points, cursor, fiapErr, err = processQueryRS(httpResponse, body)
+	pointSets, points, cursor, fiapErr, err := f.processQueryRS(httpResponse, body)
 	if err != nil {
 		err = errors.Wrap(err, "processQueryRS error")
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
-		return nil, nil, "", nil, err
+		inst.errors.Add(ctx, 1, metric.WithAttributes(attrURL.String(f.ConnectionURL), attrErrorType.String(errorTypeMalformedResponse)))
+		logger.Error("FetchOnce failed", "url", f.ConnectionURL, "error", err)
+		return nil, err
+	}
+	if fiapErr != nil {
+		inst.errors.Add(ctx, 1, metric.WithAttributes(attrURL.String(f.ConnectionURL), attrErrorType.String(fiapErr.Type)))
 	}
-	tools.LogPrintf(tools.LogLevelDebug, "FetchOnce end, pointSets: %v, points: %v, cursor: %v\n", pointSets, points, cursor)
-	return pointSets, points, cursor, fiapErr, nil
+	normalizeLocation(points, f.Location)
+	var trees map[string]*model.PointSetTree
+	if option != nil && option.Tree && fiapErr == nil {
+		body := responseBody(httpResponse)
+		if trees, err = parseTrees(body, f.Location); err != nil {
+			err = errors.Wrap(responseError(err, httpResponse, body), "processQueryRS error")
+			inst.errors.Add(ctx, 1, metric.WithAttributes(attrURL.String(f.ConnectionURL), attrErrorType.String(errorTypeMalformedResponse)))
+			logger.Error("FetchOnce failed", "url", f.ConnectionURL, "error", err)
+			return nil, err
+		}
+	}
+	if option != nil && fiapErr == nil {
+		if err := deduplicatePoints(points, option.Deduplication); err != nil {
+			err = errors.Wrap(err, "deduplicatePoints error")
+			logger.Error("FetchOnce failed", "url", f.ConnectionURL, "error", err)
+			return nil, err
+		}
+		if err := deduplicateTrees(trees, option.Deduplication); err != nil {
+			err = errors.Wrap(err, "deduplicateTrees error")
+			logger.Error("FetchOnce failed", "url", f.ConnectionURL, "error", err)
+			return nil, err
+		}
+	}
+	valueCount := countValues(points)
+	inst.values.Add(ctx, int64(valueCount), metric.WithAttributes(attrURL.String(f.ConnectionURL)))
+	span.SetAttributes(attrNextCursor.String(cursor), attrValueCount.Int(valueCount))
+	logger.Debug("FetchOnce end", "url", f.ConnectionURL, "cursor", cursor, "value_count", valueCount)
+	return &FetchResult{
+		PointSets: pointSets,
+		Points:    points,
+		Trees:     trees,
+		Cursor:    cursor,
+		FIAPError: fiapErr,
+		PageCount: 1,
+		StartTime: start,
+		Duration:  time.Since(start),
+		QueryIDs:  []string{queryID},
+	}, nil
 }
 
 /*
@@ -235,35 +409,11 @@ errの発生条件
  - Fetchメソッドでエラーが発生した場合
 */
 func (f *FetchClient) FetchByIdsWithKey(key model.UserInputKeyNoID, ids ...string) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
-	tools.LogPrintf(tools.LogLevelDebug, "FetchByIdsWithKey start, connectionURL: %s, key: %#v, ids: %v\n", f.ConnectionURL, key, ids)
-	if len(ids) == 0 {
-		err = errors.New("ids is empty, set at least one id")
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
-		return nil, nil, nil, err
-	}
-	// Fetchのためのキーを作成
-	var keys []model.UserInputKey
-	for _, id := range ids {
-		keys = append(keys, model.UserInputKey{
-			ID:              id,
-			Eq:              key.Eq,
-			Neq:             key.Neq,
-			Lt:              key.Lt,
-			Gt:              key.Gt,
-			Lteq:            key.Lteq,
-			Gteq:            key.Gteq,
-			MinMaxIndicator: key.MinMaxIndicator,
-		})
-	}
-	// Fetchを実行
-	pointSets, points, fiapErr, err = f.Fetch(keys, &model.FetchOption{})
+	result, err := f.FetchByIdsWithKeyContext(context.Background(), key, ids...)
 	if err != nil {
-		err = errors.Wrap(err, "Fetch error")
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
 		return nil, nil, nil, err
 	}
-	tools.LogPrintf(tools.LogLevelDebug, "FetchByIdsWithKey end, pointSets: %v, points: %v\n", pointSets, points)
-	return pointSets, points, fiapErr, nil
+	return result.PointSets, result.Points, result.FIAPError, nil
 }
 
 /*
@@ -299,19 +449,11 @@ errの発生条件
  - FetchByIdWithKeyでエラーが発生した場合
 */
 func (f *FetchClient) FetchLatest(fromDate *time.Time, untilDate *time.Time, ids ...string) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
-	tools.LogPrintf(tools.LogLevelDebug, "FetchLatest start connectionURL: %s, fromDate: %v, untilDate: %v, ids: %v\n", f.ConnectionURL, fromDate, untilDate, ids)
-	pointSets, points, fiapErr, err = f.FetchByIdsWithKey(model.UserInputKeyNoID{
-		MinMaxIndicator: model.SelectTypeMaximum,
-		Gteq:            fromDate,
-		Lteq:            untilDate,
-	}, ids...)
+	result, err := f.FetchLatestContext(context.Background(), fromDate, untilDate, ids...)
 	if err != nil {
-		err = errors.Wrap(err, "FetchByIdsWithKey error")
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
 		return nil, nil, nil, err
 	}
-	tools.LogPrintf(tools.LogLevelDebug, "FetchLatest end, pointSets: %v, points: %v\n", pointSets, points)
-	return pointSets, points, fiapErr, nil
+	return result.PointSets, result.Points, result.FIAPError, nil
 }
 
 /*
@@ -347,19 +489,11 @@ errの発生条件
  - FetchByIdWithKeyでエラーが発生した場合
 */
 func (f *FetchClient) FetchOldest(fromDate *time.Time, untilDate *time.Time, ids ...string) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
-	tools.LogPrintf(tools.LogLevelDebug, "FetchOldest start connectionURL: %s, fromDate: %v, untilDate: %v, ids: %v\n", f.ConnectionURL, fromDate, untilDate, ids)
-	pointSets, points, fiapErr, err = f.FetchByIdsWithKey(model.UserInputKeyNoID{
-		MinMaxIndicator: model.SelectTypeMinimum,
-		Gteq:            fromDate,
-		Lteq:            untilDate,
-	}, ids...)
+	result, err := f.FetchOldestContext(context.Background(), fromDate, untilDate, ids...)
 	if err != nil {
-		err = errors.Wrap(err, "FetchByIdsWithKey error")
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
 		return nil, nil, nil, err
 	}
-	tools.LogPrintf(tools.LogLevelDebug, "FetchOldest end, pointSets: %v, points: %v\n", pointSets, points)
-	return pointSets, points, fiapErr, nil
+	return result.PointSets, result.Points, result.FIAPError, nil
 }
 
 /*
@@ -393,47 +527,46 @@ errの発生条件
  - FetchByIdWithKeyでエラーが発生した場合
 */
 func (f *FetchClient) FetchDateRange(fromDate *time.Time, untilDate *time.Time, ids ...string) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
-	tools.LogPrintf(tools.LogLevelDebug, "FetchDateRange start, connectionURL: %s, fromDate: %v, untilDate: %v,  ids: %v\n", f.ConnectionURL, fromDate, untilDate, ids)
-	pointSets, points, fiapErr, err = f.FetchByIdsWithKey(
-		model.UserInputKeyNoID{
-			Gteq:            fromDate,
-			Lteq:            untilDate,
-			MinMaxIndicator: model.SelectTypeNone,
-		},
-		ids...,
-	)
+	result, err := f.FetchDateRangeContext(context.Background(), fromDate, untilDate, ids...)
 	if err != nil {
-		err = errors.Wrap(err, "FetchByIdsWithKey error")
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
 		return nil, nil, nil, err
 	}
-	tools.LogPrintf(tools.LogLevelDebug, "FetchDateRange end, pointSets: %v, points: %v\n", pointSets, points)
-	return pointSets, points, fiapErr, nil
+	return result.PointSets, result.Points, result.FIAPError, nil
 }
 
 // processQueryRS はQueryRSを処理し、IDをキーとしたPointSetとPointのmapを返す
-func processQueryRS(httpResponse *http.Response, queryRS *model.QueryRS) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), cursor string, fiapErr *model.Error, err error) {
-	tools.LogPrintf(tools.LogLevelDebug, "processQueryRS start, data: %#v\n", queryRS)
+func (f *FetchClient) processQueryRS(httpResponse *http.Response, queryRS *model.QueryRS) (pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), cursor string, fiapErr *model.Error, err error) {
+	logger := f.logger()
 	if queryRS.Transport == nil {
-		err = errors.Newf("queryRS.Transport is nil, http status: %d", httpResponse.StatusCode)
-		tools.LogPrintf(tools.LogLevelError, "aaa", err)
+		err = responseError(errors.Newf("queryRS.Transport is nil, http status: %d", httpResponse.StatusCode), httpResponse, responseBody(httpResponse))
+		logger.Error("processQueryRS failed", "http_status", httpResponse.StatusCode, "error", err)
 		return nil, nil, "", nil, err
 	}
 	if queryRS.Transport.Header == nil {
-		err = errors.Newf("queryRS.Transport.Header is nil, http status: %d", httpResponse.StatusCode)
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
+		err = responseError(errors.Newf("queryRS.Transport.Header is nil, http status: %d", httpResponse.StatusCode), httpResponse, responseBody(httpResponse))
+		logger.Error("processQueryRS failed", "http_status", httpResponse.StatusCode, "error", err)
 		return nil, nil, "", nil, err
 	}
 	if queryRS.Transport.Header.OK != nil &&
 		queryRS.Transport.Body == nil {
-		err = errors.Newf("queryRS.Transport.Body is nil, http status: %d", httpResponse.StatusCode)
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
+		err = responseError(errors.Newf("queryRS.Transport.Body is nil, http status: %d", httpResponse.StatusCode), httpResponse, responseBody(httpResponse))
+		logger.Error("processQueryRS failed", "http_status", httpResponse.StatusCode, "error", err)
 		return nil, nil, "", nil, err
 	}
 	if queryRS.Transport.Header.Error != nil {
 		fiapErr = queryRS.Transport.Header.Error
 		return nil, nil, "", fiapErr, nil
 	}
+	if queryRS.Transport.Header.OK == nil {
+		err = responseError(errors.Newf("queryRS.Transport.Header has neither OK nor error, http status: %d", httpResponse.StatusCode), httpResponse, responseBody(httpResponse))
+		logger.Error("processQueryRS failed", "http_status", httpResponse.StatusCode, "error", err)
+		return nil, nil, "", nil, err
+	}
+	if err = validateQueryRS(queryRS); err != nil {
+		err = responseError(errors.Wrapf(err, "invalid queryRS, http status: %d", httpResponse.StatusCode), httpResponse, responseBody(httpResponse))
+		logger.Error("processQueryRS failed", "http_status", httpResponse.StatusCode, "error", err)
+		return nil, nil, "", nil, err
+	}
 
 	// mapの初期化
 	pointSets = make(map[string](model.ProcessedPointSet))
@@ -441,7 +574,6 @@ func processQueryRS(httpResponse *http.Response, queryRS *model.QueryRS) (pointS
 
 	// BodyにPointSetが返っていれば、それを処理する
 	if queryRS.Transport.Body.PointSet != nil {
-		tools.LogPrintf(tools.LogLevelDebug, "processQueryRS, pointSet is not nil")
 		// pointSetsを初期化
 		// PointSetの数だけ処理を繰り返す
 		for _, ps := range queryRS.Transport.Body.PointSet {
@@ -476,8 +608,68 @@ func processQueryRS(httpResponse *http.Response, queryRS *model.QueryRS) (pointS
 		}
 	}
 
-	cursor = queryRS.Transport.Header.Query.Cursor
+	if queryRS.Transport.Header.Query != nil {
+		cursor = queryRS.Transport.Header.Query.Cursor
+	}
 
-	tools.LogPrintf(tools.LogLevelDebug, "processQueryRS end, pointSets: %v, points: %v, cursor: %s\n", pointSets, points, cursor)
+	logger.Debug("processQueryRS end", "point_set_count", len(pointSets), "point_count", len(points), "value_count", countValues(points), "cursor", cursor)
 	return pointSets, points, cursor, nil, nil
 }
+
+// maxAttributeLength はqueryRSのIDとcursorとして受け付ける文字列の長さの上限
+const maxAttributeLength = 64 * 1024
+
+// validateQueryRS はOKを返したqueryRSのbodyとcursorを検証する。IDが空、長すぎるID、時刻のないvalueをエラーとする
+func validateQueryRS(queryRS *model.QueryRS) error {
+	if query := queryRS.Transport.Header.Query; query != nil && len(query.Cursor) > maxAttributeLength {
+		return errors.Newf("cursor is too long, length: %d", len(query.Cursor))
+	}
+	body := queryRS.Transport.Body
+	for i, ps := range body.PointSet {
+		if ps == nil {
+			return errors.Newf("pointSet is nil, index: %d", i)
+		}
+		if err := validateResponseID(ps.Id); err != nil {
+			return errors.Wrapf(err, "invalid pointSet id, index: %d", i)
+		}
+		for _, id := range append(append([]string{}, ps.PointSetId...), ps.PointId...) {
+			if err := validateResponseID(id); err != nil {
+				return errors.Wrapf(err, "invalid child id of pointSet %s", ps.Id)
+			}
+		}
+	}
+	for i, p := range body.Point {
+		if p == nil {
+			return errors.Newf("point is nil, index: %d", i)
+		}
+		if err := validateResponseID(p.Id); err != nil {
+			return errors.Wrapf(err, "invalid point id, index: %d", i)
+		}
+		for j, v := range p.Value {
+			if v.Time.IsZero() {
+				return errors.Newf("value has no time, point: %s, index: %d", p.Id, j)
+			}
+		}
+	}
+	return nil
+}
+
+// validateResponseID はqueryRSに含まれるIDが空でなく、長すぎないことを検証する
+func validateResponseID(id string) error {
+	if id == "" {
+		return errors.New("id is empty")
+	}
+	if len(id) > maxAttributeLength {
+		return errors.Newf("id is too long, length: %d", len(id))
+	}
+	return nil
+}
+
+// countValues はpointsに含まれる値の総数を返す
+func countValues(points map[string]([]model.Value)) int {
+	count := 0
+	for _, values := range points {
+		count += len(values)
+	}
+	return count
+}
@@ -34,7 +34,7 @@ func BenchmarkFiapFetch(b *testing.B) {
 	}	{
 		b.Run(id, func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
-				_, _, err := fiapFetch(benchMarkConnectionURL, []model.UserInputKey{
+				_, _, _, err := (&FetchClient{ConnectionURL: benchMarkConnectionURL}).fiapFetch(context.Background(), []model.UserInputKey{
 					{ID: id},
 				},&model.FetchOnceOption{})
 				if err != nil {
@@ -61,7 +61,7 @@ func BenchmarkSoapCall(b *testing.B) {
 		"http://go-fiap-client/perf/test-perf/1/pointSet-100MB",
 	}	{
 		client := soap.NewClient(benchMarkConnectionURL, nil)
-		queryRQ := newQueryRQ(&model.FetchOnceOption{}, []model.UserInputKey{{ID: id}})
+		queryRQ := newQueryRQ(&model.FetchOnceOption{}, []model.UserInputKey{{ID: id}}, "")
 		resBody := &model.QueryRS{}
 
 		b.ResetTimer()
@@ -1026,6 +1026,131 @@ func TestFetchOnceProcessQueryRSErrorWithStatusCode(t *testing.T) {
 	}
 }
 
+func TestFetchOnceProcessQueryRSMalformed(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	f := FetchClient{ConnectionURL: connectionURL}
+	header := `<header><OK/><query id="e3264a29-b4a6-41dd-a6bb-cbf57b76e571" type="storage"/></header>`
+
+	// テストケースを定義
+	testcases := []struct {
+		name      string
+		transport string
+		wantErr   string
+	}{
+		{
+			name:      "when queryRS.Transport.Header has neither OK nor error",
+			transport: `<header></header><body></body>`,
+			wantErr:   "queryRS.Transport.Header has neither OK nor error",
+		},
+		{
+			name:      "when pointSet id is empty",
+			transport: header + `<body><pointSet><point id="p1"/></pointSet></body>`,
+			wantErr:   "invalid pointSet id, index: 0: id is empty",
+		},
+		{
+			name:      "when child id of pointSet is empty",
+			transport: header + `<body><pointSet id="ps1"><point/></pointSet></body>`,
+			wantErr:   "invalid child id of pointSet ps1: id is empty",
+		},
+		{
+			name:      "when point id is empty",
+			transport: header + `<body><point><value time="2012-02-02T16:34:05.000+09:00">30</value></point></body>`,
+			wantErr:   "invalid point id, index: 0: id is empty",
+		},
+		{
+			name:      "when point id is too long",
+			transport: header + `<body><point id="` + strings.Repeat("x", maxAttributeLength+1) + `"/></body>`,
+			wantErr:   "id is too long",
+		},
+		{
+			name:      "when value has no time",
+			transport: header + `<body><point id="p1"><value>30</value></point></body>`,
+			wantErr:   "value has no time, point: p1, index: 0",
+		},
+		{
+			name:      "when cursor is too long",
+			transport: `<header><OK/><query cursor="` + strings.Repeat("x", maxAttributeLength+1) + `"/></header><body></body>`,
+			wantErr:   "cursor is too long",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// mockの有効化
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			// 下記URLにPOSTしたときの挙動を定義
+			responder := testutil.CustomHeaderBodyResponder(tc.transport)
+			httpmock.RegisterResponder("POST", connectionURL, responder)
+
+			// テスト対象の関数を実行
+			_, _, _, _, err := f.FetchOnce(
+				[]model.UserInputKey{
+					{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+				},
+				&model.FetchOnceOption{},
+			)
+
+			assert.ErrorIs(t, err, ErrMalformedResponse)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestFetchOnceProcessQueryRSRobust(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	f := FetchClient{ConnectionURL: connectionURL}
+
+	// テストケースを定義
+	testcases := []struct {
+		name              string
+		transport         string
+		expectedPointSets map[string](model.ProcessedPointSet)
+	}{
+		{
+			name:              "when query is omitted",
+			transport:         `<header><OK/></header><body></body>`,
+			expectedPointSets: map[string](model.ProcessedPointSet){},
+		},
+		{
+			name: "when pointSets are deeply nested",
+			transport: `<header><OK/></header><body><pointSet id="ps1">` +
+				strings.Repeat(`<pointSet id="child">`, 5000) + strings.Repeat(`</pointSet>`, 5000) +
+				`</pointSet></body>`,
+			expectedPointSets: map[string](model.ProcessedPointSet){
+				"ps1": {PointSetID: []string{"child"}, PointID: []string{}},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			// mockの有効化
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			// 下記URLにPOSTしたときの挙動を定義
+			responder := testutil.CustomHeaderBodyResponder(tc.transport)
+			httpmock.RegisterResponder("POST", connectionURL, responder)
+
+			// テスト対象の関数を実行
+			pointSets, points, cursor, fiapErr, err := f.FetchOnce(
+				[]model.UserInputKey{
+					{ID: "http://xxxxxxxx/tokyo/building1/"},
+				},
+				&model.FetchOnceOption{},
+			)
+
+			assert.NoError(t, err)
+			assert.Nil(t, fiapErr)
+			assert.Equal(t, "", cursor)
+			assert.Equal(t, tc.expectedPointSets, pointSets)
+			assert.Equal(t, map[string]([]model.Value){}, points)
+		})
+	}
+}
+
 func TestFetchOnceProcessQueryRSFiapErr(t *testing.T) {
 	var connectionURL = defaultConnectionURL
 	f := FetchClient{ConnectionURL: connectionURL}
@@ -1876,17 +2001,17 @@ func TestFetchByIdsWithKeyIdBoundary(t *testing.T) {
 
 	testcases := []struct {
 		name        string
-		ids         string
+		ids         []string
 		expectedIds []string
 	}{
 		{
 			name:        "when one id is specified",
-			ids:         "http://xxxxxxxx/tokyo/building1/",
+			ids:         []string{"http://xxxxxxxx/tokyo/building1/"},
 			expectedIds: []string{"http://xxxxxxxx/tokyo/building1/"},
 		},
 		{
 			name:        "when two ids are specified",
-			ids:         `"http://xxxxxxxx/tokyo/building1/", "http://xxxxxxxx/tokyo/building2/"`,
+			ids:         []string{"http://xxxxxxxx/tokyo/building1/", "http://xxxxxxxx/tokyo/building2/"},
 			expectedIds: []string{"http://xxxxxxxx/tokyo/building1/", "http://xxxxxxxx/tokyo/building2/"},
 		},
 	}
@@ -1947,7 +2072,7 @@ func TestFetchByIdsWithKeyIdBoundary(t *testing.T) {
 				responder,
 			)
 			// テスト対象の関数を実行
-			pointSets, points, _, _ := f.FetchByIdsWithKey(model.UserInputKeyNoID{}, tc.ids)
+			pointSets, points, _, _ := f.FetchByIdsWithKey(model.UserInputKeyNoID{}, tc.ids...)
 			assert.Equal(t, expectedPointSets, pointSets)
 			assert.Equal(t, expectedPoints, points)
 		})
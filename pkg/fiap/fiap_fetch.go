@@ -1,11 +1,19 @@
 package fiap
 
 import (
+	"bytes"
 	"context"
+	"encoding/xml"
+	"io"
+	"log/slog"
 	"net/http"
 	"regexp"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
 	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
@@ -15,51 +23,81 @@ import (
 
 var regexpURL = regexp.MustCompile(`^https?://`)
 
-func fiapFetch(connectionURL string, keys []model.UserInputKey, option *model.FetchOnceOption) (httpResponse *http.Response, resBody *model.QueryRS, err error) {
-	tools.LogPrintf(tools.LogLevelDebug, "fiapFetch start, connectionURL: %s, keys: %v, option: %v\n", connectionURL, keys, option)
+func (f *FetchClient) fiapFetch(ctx context.Context, keys []model.UserInputKey, option *model.FetchOnceOption) (httpResponse *http.Response, resBody *model.QueryRS, queryID string, err error) {
+	connectionURL := f.ConnectionURL
+	logger := f.logger().With("url", connectionURL)
 
 	if !regexpURL.Match([]byte(connectionURL)) {
-		err = errors.Newf("invalid connectionURL: %s", connectionURL)
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
-		return nil, nil, err
+		err = markError(errors.Newf("invalid connectionURL: %s", connectionURL), ErrInvalidURL)
+		logger.Error("fiapFetch failed", "error", err)
+		return nil, nil, "", err
 	}
-	if len(keys) == 0 {
-		err = errors.New("keys is empty")
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
-		return nil, nil, err
-	}
-	for _, key := range keys {
-		if key.ID == "" {
-			err = errors.Newf("keys.ID is empty, key: %#v", keys)
-			tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
-			return nil, nil, err
-		}
+	if err = ValidateKeysWithLayout(keys, f.TimeLayout); err != nil {
+		err = errors.Wrap(err, "invalid keys")
+		logger.Error("fiapFetch failed", "error", err)
+		return nil, nil, "", err
 	}
 
 	client := soap.NewClient(connectionURL, nil)
+	if f.HTTPClient != nil {
+		client.HTTPClientDoFn = f.HTTPClient.Do
+	}
+	var receivedBytes atomic.Int64
+	client.HTTPClientDoFn = countingDoFn(client.HTTPClientDoFn, &receivedBytes)
 
 	// クエリを作成
-	queryRQ := newQueryRQ(option, keys)
+	queryRQ := newQueryRQ(option, keys, f.TimeLayout)
+	client.HTTPClientDoFn = hookDoFn(client.HTTPClientDoFn, f.Hooks, client.Marshaller, queryRQ)
+	var (
+		receivedResponse *http.Response
+		receivedBody     bytes.Buffer
+	)
+	captureLimit := maxCapturedBodySize
+	if option != nil && option.Tree {
+		// pointSetの木構造はSOAP通信の終了後にレスポンスから作成するため、レスポンス全体を保持する
+		captureLimit = -1
+	}
+	client.HTTPClientDoFn = capturingDoFn(client.HTTPClientDoFn, &receivedResponse, &receivedBody, captureLimit)
 	resBody = &model.QueryRS{}
+	query := queryRQ.Transport.Header.Query
+	logger = logger.With("query_id", query.Id)
+
+	ctx, span := f.tracer().Start(ctx, "fiap.query", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attrURL.String(connectionURL),
+		attrQueryID.String(query.Id),
+		attrKeyCount.Int(len(keys)),
+		attrCursor.String(query.Cursor),
+	))
+	defer func() { endSpan(span, nil, err) }()
+	inst := f.instruments()
+	metricAttrs := metric.WithAttributes(attrURL.String(connectionURL))
 
 	// クエリを実行
-	tools.LogPrintf(tools.LogLevelDebug, "fiapFetch, client.Call start, queryRQ: %#v\n", queryRQ)
-	httpResponse, err = client.Call(context.Background(), "http://soap.fiap.org/query", queryRQ, resBody)
-	tools.LogPrintf(tools.LogLevelDebug, "fiapFetch, client.Call end, httpResponse: %#v, resBody: %#v\n", httpResponse, resBody)
+	logger.Debug("SOAP call start", "key_count", len(keys), "cursor", query.Cursor, "acceptable_size", query.AcceptableSize)
+	logger.Log(ctx, tools.LevelTrace, "SOAP request", "query_rq", xmlLogValue{queryRQ})
+	start := time.Now()
+	httpResponse, err = client.Call(ctx, "http://soap.fiap.org/query", queryRQ, resBody)
+	duration := time.Since(start)
 
+	inst.requests.Add(ctx, 1, metricAttrs)
+	inst.duration.Record(ctx, duration.Seconds(), metricAttrs)
+	inst.bytes.Add(ctx, receivedBytes.Load(), metricAttrs)
 	if err != nil {
-		err = errors.Wrap(err, "client.Call error")
-		tools.LogPrintf(tools.LogLevelError, "%+v\n", err)
-		return nil, nil, err
+		err = errors.Wrap(classifyCallError(err, receivedResponse, receivedBody.Bytes()), "client.Call error")
+		inst.errors.Add(ctx, 1, metric.WithAttributes(attrURL.String(connectionURL), attrErrorType.String(errorType(err))))
+		logger.Error("SOAP call failed", "duration", duration, "error", err)
+		return nil, nil, "", err
 	}
+	span.SetAttributes(attrHTTPStatusCode.Int(httpResponse.StatusCode))
+	// 受信した内容をエラーの詳細の作成に使用できるようにする
+	httpResponse.Body = io.NopCloser(bytes.NewReader(receivedBody.Bytes()))
 
-	tools.LogPrintf(tools.LogLevelDebug, "fiapFetch end, resBody: %#v\n", resBody)
-	return httpResponse, resBody, nil
+	logger.Debug("SOAP call end", "http_status", httpResponse.StatusCode, "duration", duration, "bytes", receivedBytes.Load())
+	logger.Log(ctx, tools.LevelTrace, "SOAP response", "query_rs", xmlLogValue{resBody})
+	return httpResponse, resBody, query.Id, nil
 }
 
-func newQueryRQ(option *model.FetchOnceOption, keys []model.UserInputKey) *model.QueryRQ {
-	tools.LogPrintf(tools.LogLevelDebug, "CreateFetchQueryRQ start, option: %v, keys: %v\n", option, keys)
-
+func newQueryRQ(option *model.FetchOnceOption, keys []model.UserInputKey, timeLayout string) *model.QueryRQ {
 	// デフォルト値の設定
 	if option == nil {
 		option = &model.FetchOnceOption{}
@@ -76,11 +114,23 @@ func newQueryRQ(option *model.FetchOnceOption, keys []model.UserInputKey) *model
 					AcceptableSize: option.AcceptableSize,
 					Type:           "storage",
 					Cursor:         option.Cursor,
-					Key:            tools.UserInputKeysToKeys(keys),
+					Key:            tools.UserInputKeysToKeysWithLayout(keys, timeLayout),
 				},
 			},
 		},
 	}
-	tools.LogPrintf(tools.LogLevelDebug, "CreateFetchQueryRQ end, queryRQ: %#v\n", queryRQ)
 	return queryRQ
 }
+
+// xmlLogValue はログの出力時にvをXMLとして文字列化する。トレースレベルが無効な場合は文字列化しない
+type xmlLogValue struct {
+	v any
+}
+
+func (x xmlLogValue) LogValue() slog.Value {
+	b, err := xml.Marshal(x.v)
+	if err != nil {
+		return slog.StringValue(err.Error())
+	}
+	return slog.StringValue(string(b))
+}
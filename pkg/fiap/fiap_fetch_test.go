@@ -1,6 +1,7 @@
 package fiap
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 	"io"
@@ -39,8 +40,7 @@ func TestFiapFetchResponseOnlyPoint(t *testing.T) {
 	httpmock.RegisterResponder("POST", "http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage", responder)
 
 	// テスト対象の関数を実行
-	httpResponse, QueryRS, err := fiapFetch(
-		"http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage",
+	httpResponse, QueryRS, _, err := (&FetchClient{ConnectionURL: "http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage"}).fiapFetch(context.Background(),
 		[]model.UserInputKey{
 			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
 		},
@@ -170,8 +170,7 @@ func TestFiapFetchResponseOnlyOnePointSet(t *testing.T) {
 			httpmock.RegisterResponder("POST", "http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage", tc.responder)
 
 			// テスト対象の関数を実行
-			httpResponse, QueryRS, err := fiapFetch(
-				"http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage",
+			httpResponse, QueryRS, _, err := (&FetchClient{ConnectionURL: "http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage"}).fiapFetch(context.Background(),
 				[]model.UserInputKey{
 					{ID: "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD"},
 				},
@@ -260,8 +259,7 @@ func TestFiapFetchResponseOnlyMultiplePointSet(t *testing.T) {
 			httpmock.RegisterResponder("POST", "http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage", tc.responder)
 
 			// テスト対象の関数を実行
-			httpResponse, QueryRS, err := fiapFetch(
-				"http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage",
+			httpResponse, QueryRS, _, err := (&FetchClient{ConnectionURL: "http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage"}).fiapFetch(context.Background(),
 				[]model.UserInputKey{
 					{ID: "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD"},
 				},
@@ -320,8 +318,7 @@ func TestFiapFetchInputErrors(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// テスト対象の関数を実行
-			httpResponse, QueryRS, err := fiapFetch(
-				tc.connectionURL,
+			httpResponse, QueryRS, _, err := (&FetchClient{ConnectionURL: tc.connectionURL}).fiapFetch(context.Background(),
 				tc.keys,
 				nil,
 			)
@@ -344,8 +341,7 @@ func TestFiapFetchRequestFailure(t *testing.T) {
 		httpmock.NewErrorResponder(errors.New("mocked error")))
 
 	// テスト対象の関数を実行
-	httpResponse, QueryRS, err := fiapFetch(
-		"http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage",
+	httpResponse, QueryRS, _, err := (&FetchClient{ConnectionURL: "http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage"}).fiapFetch(context.Background(),
 		[]model.UserInputKey{
 			{ID: "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD"},
 		},
@@ -383,12 +379,9 @@ func TestFiapFetchRequestOptionAndKeys(t *testing.T) {
 			keys: []model.UserInputKey{
 				{
 					ID:              "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD",
-					Eq:              testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
 					Neq:             testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Lt:              testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
 					Gt:              testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Lteq:            testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Gteq:            testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
+					Lteq:            testutil.TimeToTimep(time.Date(2021, 1, 2, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
 					MinMaxIndicator: model.SelectTypeMaximum,
 				},
 				{
@@ -402,12 +395,9 @@ func TestFiapFetchRequestOptionAndKeys(t *testing.T) {
 			expectedRequestKeys: []model.Key{
 				{
 					Id:     "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD",
-					Eq:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
 					Neq:    tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Lt:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
 					Gt:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Lteq:   tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Gteq:   tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
+					Lteq:   tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 2, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
 					Select: "maximum",
 				},
 				{
@@ -513,12 +503,9 @@ func TestFiapFetchRequestOptionAndKeys(t *testing.T) {
 			keys: []model.UserInputKey{
 				{
 					ID:              "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD",
-					Eq:              testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
 					Neq:             testutil.TimeToTimep(time.Date(2021, 1, 2, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
 					Lt:              testutil.TimeToTimep(time.Date(2021, 1, 3, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Gt:              testutil.TimeToTimep(time.Date(2021, 1, 4, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Lteq:            testutil.TimeToTimep(time.Date(2021, 1, 5, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Gteq:            testutil.TimeToTimep(time.Date(2021, 1, 6, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
+					Gteq:            testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
 					MinMaxIndicator: model.SelectTypeMaximum,
 				},
 			},
@@ -527,12 +514,9 @@ func TestFiapFetchRequestOptionAndKeys(t *testing.T) {
 			expectedRequestKeys: []model.Key{
 				{
 					Id:     "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD",
-					Eq:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
 					Neq:    tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 2, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
 					Lt:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 3, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Gt:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 4, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Lteq:   tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 5, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Gteq:   tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 6, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
+					Gteq:   tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
 					Select: "maximum",
 				},
 			},
@@ -541,23 +525,14 @@ func TestFiapFetchRequestOptionAndKeys(t *testing.T) {
 			name: "when keys contain two UserInputKey",
 			keys: []model.UserInputKey{
 				{
-					ID:              "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD",
-					Eq:              testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Neq:             testutil.TimeToTimep(time.Date(2021, 1, 2, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Lt:              testutil.TimeToTimep(time.Date(2021, 1, 3, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Gt:              testutil.TimeToTimep(time.Date(2021, 1, 4, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Lteq:            testutil.TimeToTimep(time.Date(2021, 1, 5, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Gteq:            testutil.TimeToTimep(time.Date(2021, 1, 6, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					MinMaxIndicator: model.SelectTypeMaximum,
+					ID: "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD",
+					Eq: testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
 				},
 				{
 					ID:              "http://kurimoto/nukaya/vaisala/B-2/Humidity_TD",
-					Eq:              testutil.TimeToTimep(time.Date(2021, 1, 6, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
 					Neq:             testutil.TimeToTimep(time.Date(2021, 1, 5, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Lt:              testutil.TimeToTimep(time.Date(2021, 1, 4, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
 					Gt:              testutil.TimeToTimep(time.Date(2021, 1, 3, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Lteq:            testutil.TimeToTimep(time.Date(2021, 1, 2, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
-					Gteq:            testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
+					Lteq:            testutil.TimeToTimep(time.Date(2021, 1, 6, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60))),
 					MinMaxIndicator: model.SelectTypeMinimum,
 				},
 			},
@@ -565,23 +540,14 @@ func TestFiapFetchRequestOptionAndKeys(t *testing.T) {
 			expectedRequestOption: &model.FetchOnceOption{},
 			expectedRequestKeys: []model.Key{
 				{
-					Id:     "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD",
-					Eq:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Neq:    tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 2, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Lt:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 3, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Gt:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 4, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Lteq:   tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 5, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Gteq:   tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 6, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Select: "maximum",
+					Id: "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD",
+					Eq: tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
 				},
 				{
 					Id:     "http://kurimoto/nukaya/vaisala/B-2/Humidity_TD",
-					Eq:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 6, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
 					Neq:    tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 5, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Lt:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 4, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
 					Gt:     tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 3, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Lteq:   tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 2, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
-					Gteq:   tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 1, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
+					Lteq:   tools.TimeToString(testutil.TimeToTimep(time.Date(2021, 1, 6, 0, 0, 0, 0, time.FixedZone("Asia/Tokyo", 9*60*60)))),
 					Select: "minimum",
 				},
 			},
@@ -632,8 +598,7 @@ func TestFiapFetchRequestOptionAndKeys(t *testing.T) {
 			)
 
 			// テスト対象の関数を実行
-			httpResponse, _, err := fiapFetch(
-				"http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage",
+			httpResponse, _, _, err := (&FetchClient{ConnectionURL: "http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage"}).fiapFetch(context.Background(),
 				tc.keys,
 				tc.option,
 			)
@@ -744,8 +709,7 @@ func TestFiapFetchRequestWithoutSpecificString(t *testing.T) {
 			)
 
 			// テスト対象の関数を実行
-			httpResponse, _, err := fiapFetch(
-				"http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage",
+			httpResponse, _, _, err := (&FetchClient{ConnectionURL: "http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage"}).fiapFetch(context.Background(),
 				tc.keys,
 				tc.option,
 			)
@@ -788,8 +752,7 @@ func TestFiapFetchRequestQueryType(t *testing.T) {
 	)
 
 	// テスト対象の関数を実行
-	httpResponse, _, err := fiapFetch(
-		"http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage",
+	httpResponse, _, _, err := (&FetchClient{ConnectionURL: "http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage"}).fiapFetch(context.Background(),
 		[]model.UserInputKey{
 			{ID: "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD"},
 		},
@@ -831,8 +794,7 @@ func TestFiapFetchRequestUuid(t *testing.T) {
 	)
 
 	// テスト対象の関数を実行
-	httpResponse, _, err := fiapFetch(
-		"http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage",
+	httpResponse, _, _, err := (&FetchClient{ConnectionURL: "http://iot.info.nara-k.ac.jp/axis2/services/FIAPStorage"}).fiapFetch(context.Background(),
 		[]model.UserInputKey{
 			{ID: "http://kurimoto/nukaya/vaisala/B-2/Temperature_TD"},
 		},
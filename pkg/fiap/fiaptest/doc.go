@@ -0,0 +1,17 @@
+/*
+Package fiaptest provides an in-memory fake FIAP storage server for tests.
+
+fiaptestパッケージは、テストで使用するインメモリのFIAPストレージサーバを提供します。
+
+Serverはhttptest.Serverを使用して起動し、登録されたpointとpointSetをstorage.Memoryに保持します。
+クエリの評価とacceptableSizeとcursorによるページングは、serverパッケージのServerと同じ実装で行います。
+また、FIAPのerror、遅延、HTTPのエラーを返すように設定できます。
+
+	server := fiaptest.NewServer()
+	defer server.Close()
+	server.AddPoint("http://xxxxxxxx/tokyo/building1/Room101/", model.Value{Time: t, Value: "30"})
+
+	fetchClient := fiap.NewFetchClient(server.URL)
+	pointSets, points, fiapErr, err := fetchClient.Fetch(keys, nil)
+*/
+package fiaptest
@@ -0,0 +1,183 @@
+package fiaptest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/server"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/storage"
+)
+
+/*
+DefaultMaxAcceptableSize is the default upper limit of values the Server returns at once.
+
+DefaultMaxAcceptableSizeは、Serverが一度に返すvalueの数の上限の初期値です。
+*/
+const DefaultMaxAcceptableSize uint = server.DefaultMaxAcceptableSize
+
+/*
+Failure describes a failure the Server returns instead of a normal response.
+
+Failureは、Serverが通常のレスポンスの代わりに返す失敗の内容です。
+
+Latencyは、レスポンスを返す前に待機する時間です。他のフィールドと組み合わせて使用できます。
+StatusCodeが0でない場合は、SOAPではないBodyをそのステータスコードで返します。
+FIAPErrorがnilでない場合は、headerにerrorを含むレスポンスを返します。
+*/
+type Failure struct {
+	Latency    time.Duration
+	StatusCode int
+	Body       string
+	FIAPError  *model.Error
+}
+
+/*
+Server is an in-memory fake FIAP storage server.
+
+Serverは、pointとpointSetをメモリ上に保持するFIAPストレージサーバです。NewServerで作成し、使用後はCloseを呼び出してください。
+
+クエリの評価とページングは、storage.Memoryを保存先とするserver.Serverが行います。
+Serverは、受信したクエリの記録と、FailNextとSetLatencyによる失敗と遅延の注入のみを行います。
+*/
+type Server struct {
+	*httptest.Server
+
+	store *storage.Memory
+	fiap  *server.Server
+
+	// serveMu はfiapの設定の変更とリクエストの処理を排他する
+	serveMu  sync.Mutex
+	mu       sync.Mutex
+	queries  []model.Query
+	failures []Failure
+	latency  time.Duration
+}
+
+// failureKey はリクエストのcontextに注入するFIAPのerrorのキー
+type failureKey struct{}
+
+/*
+NewServer starts and returns a new Server.
+
+NewServerは、新しいServerを起動して返します。
+*/
+func NewServer() *Server {
+	s := &Server{store: storage.NewMemory()}
+	s.fiap = server.NewServer(s.store, server.WithQueryHook(s.hook))
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+/*
+AddPoint stores values of the point with the given id.
+
+AddPointは、指定されたIDのpointにvaluesを追加します。pointが存在しない場合は作成します。
+同じ時刻のvalueが存在する場合は置き換えます。
+*/
+func (s *Server) AddPoint(id string, values ...model.Value) {
+	s.store.Write(context.Background(), nil, []*model.Point{{Id: id, Value: values}})
+}
+
+/*
+AddPointSet stores a pointSet with the given child pointSets and points.
+
+AddPointSetは、子のpointSetとpointのIDを持つpointSetを登録します。
+同じIDのpointSetが存在する場合は子のIDを追加します。登録されていない子のpointSetとpointは、空の状態で登録します。
+*/
+func (s *Server) AddPointSet(id string, pointSetIDs []string, pointIDs []string) {
+	pointSet := &model.OriginalPointSet{Id: id}
+	for _, pointSetID := range pointSetIDs {
+		pointSet.PointSet = append(pointSet.PointSet, &model.OriginalPointSet{Id: pointSetID})
+	}
+	for _, pointID := range pointIDs {
+		pointSet.Point = append(pointSet.Point, &model.Point{Id: pointID})
+	}
+	s.store.Write(context.Background(), []*model.OriginalPointSet{pointSet}, nil)
+}
+
+/*
+SetMaxAcceptableSize sets the upper limit of values the Server returns at once.
+
+SetMaxAcceptableSizeは、Serverが一度に返すvalueの数の上限を設定します。
+クエリのacceptableSizeが0の場合や、この値より大きい場合はこの値を使用します。0の場合は上限を設けません。
+*/
+func (s *Server) SetMaxAcceptableSize(size uint) {
+	s.serveMu.Lock()
+	defer s.serveMu.Unlock()
+	s.fiap.MaxAcceptableSize = size
+}
+
+/*
+SetLatency sets the time the Server waits before every response.
+
+SetLatencyは、Serverがすべてのレスポンスを返す前に待機する時間を設定します。
+*/
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = latency
+}
+
+/*
+FailNext makes the Server return the failures to the next requests, one per request in order.
+
+FailNextは、次に受信するリクエストから順に、1リクエストにつき1つのfailureを返すように設定します。
+すべてのfailureを返した後は、通常のレスポンスに戻ります。
+*/
+func (s *Server) FailNext(failures ...Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failures...)
+}
+
+/*
+Queries returns the queries the Server has received.
+
+Queriesは、Serverが受信したクエリを受信した順に返します。
+*/
+func (s *Server) Queries() []model.Query {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]model.Query(nil), s.queries...)
+}
+
+// handle は遅延とHTTPのエラーを注入し、それ以外のリクエストをserver.Serverで処理する
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	latency := s.latency
+	var failure Failure
+	if len(s.failures) > 0 {
+		failure = s.failures[0]
+		s.failures = s.failures[1:]
+	}
+	s.mu.Unlock()
+
+	time.Sleep(latency + failure.Latency)
+
+	if failure.StatusCode != 0 {
+		w.WriteHeader(failure.StatusCode)
+		fmt.Fprint(w, failure.Body)
+		return
+	}
+	if failure.FIAPError != nil {
+		r = r.WithContext(context.WithValue(r.Context(), failureKey{}, failure.FIAPError))
+	}
+
+	s.serveMu.Lock()
+	defer s.serveMu.Unlock()
+	s.fiap.ServeHTTP(w, r)
+}
+
+// hook は受信したクエリを記録し、handleが注入したFIAPのerrorを返す
+func (s *Server) hook(ctx context.Context, query *model.Query) *model.Error {
+	s.mu.Lock()
+	s.queries = append(s.queries, *query)
+	s.mu.Unlock()
+	fiapErr, _ := ctx.Value(failureKey{}).(*model.Error)
+	return fiapErr
+}
@@ -0,0 +1,250 @@
+package fiaptest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+const (
+	room101 = "http://xxxxxxxx/tokyo/building1/Room101/"
+	room102 = "http://xxxxxxxx/tokyo/building1/Room102/"
+)
+
+// newTestServer はRoom101に1分間隔の5つのvalueを持つServerを返す
+func newTestServer(t *testing.T) (*Server, time.Time) {
+	base := time.Date(2012, 2, 2, 16, 34, 0, 0, time.UTC)
+	s := NewServer()
+	t.Cleanup(s.Close)
+	for i := 0; i < 5; i++ {
+		s.AddPoint(room101, model.Value{Time: base.Add(time.Duration(i) * time.Minute), Value: string(rune('0' + i))})
+	}
+	s.AddPoint(room102)
+	s.AddPointSet("http://xxxxxxxx/tokyo/building1/", nil, []string{room101, room102})
+	return s, base
+}
+
+func TestServerKeys(t *testing.T) {
+	s, base := newTestServer(t)
+	f := fiap.NewFetchClient(s.URL)
+	at := func(minutes int) *time.Time {
+		return testutil.TimeToTimep(base.Add(time.Duration(minutes) * time.Minute))
+	}
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		key      model.UserInputKey
+		expected []string
+	}{
+		{name: "no condition", key: model.UserInputKey{ID: room101}, expected: []string{"0", "1", "2", "3", "4"}},
+		{name: "eq", key: model.UserInputKey{ID: room101, Eq: at(2)}, expected: []string{"2"}},
+		{name: "neq", key: model.UserInputKey{ID: room101, Neq: at(2)}, expected: []string{"0", "1", "3", "4"}},
+		{name: "gteq and lteq", key: model.UserInputKey{ID: room101, Gteq: at(1), Lteq: at(3)}, expected: []string{"1", "2", "3"}},
+		{name: "gt and lt", key: model.UserInputKey{ID: room101, Gt: at(1), Lt: at(3)}, expected: []string{"2"}},
+		{name: "maximum", key: model.UserInputKey{ID: room101, Lt: at(3), MinMaxIndicator: model.SelectTypeMaximum}, expected: []string{"2"}},
+		{name: "minimum", key: model.UserInputKey{ID: room101, Gteq: at(1), MinMaxIndicator: model.SelectTypeMinimum}, expected: []string{"1"}},
+		{name: "no match", key: model.UserInputKey{ID: room101, Gt: at(4)}, expected: []string{}},
+		{name: "value gteq and lt", key: model.UserInputKey{ID: room101, Value: &model.ValueCondition{Gteq: testutil.StringToStringp("1"), Lt: testutil.StringToStringp("3")}}, expected: []string{"1", "2"}},
+		{name: "value neq", key: model.UserInputKey{ID: room101, Value: &model.ValueCondition{Neq: testutil.StringToStringp("2")}}, expected: []string{"0", "1", "3", "4"}},
+		{name: "value maximum", key: model.UserInputKey{ID: room101, Value: &model.ValueCondition{Lteq: testutil.StringToStringp("3")}, MinMaxIndicator: model.SelectTypeMaximum}, expected: []string{"3"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// テスト対象の関数を実行
+			_, points, fiapErr, err := f.Fetch([]model.UserInputKey{tc.key}, nil)
+
+			require.NoError(t, err)
+			assert.Nil(t, fiapErr)
+			actual := []string{}
+			for _, v := range points[room101] {
+				actual = append(actual, v.Value)
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestServerPointSet(t *testing.T) {
+	s, _ := newTestServer(t)
+	f := fiap.NewFetchClient(s.URL)
+
+	// テスト対象の関数を実行
+	pointSets, points, fiapErr, err := f.Fetch([]model.UserInputKey{{ID: "http://xxxxxxxx/tokyo/building1/"}}, nil)
+
+	require.NoError(t, err)
+	assert.Nil(t, fiapErr)
+	assert.Equal(t, map[string]model.ProcessedPointSet{
+		"http://xxxxxxxx/tokyo/building1/": {PointSetID: []string{}, PointID: []string{room101, room102}},
+	}, pointSets)
+	assert.Empty(t, points)
+}
+
+func TestServerPagination(t *testing.T) {
+	s, _ := newTestServer(t)
+	f := fiap.NewFetchClient(s.URL)
+	keys := []model.UserInputKey{{ID: room101}, {ID: room102}}
+
+	t.Run("FetchOnce", func(t *testing.T) {
+		// テスト対象の関数を実行
+		_, points, cursor, fiapErr, err := f.FetchOnce(keys, &model.FetchOnceOption{AcceptableSize: 2})
+
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		assert.NotEmpty(t, cursor)
+		assert.Len(t, points[room101], 2)
+
+		_, points, cursor, fiapErr, err = f.FetchOnce(keys, &model.FetchOnceOption{AcceptableSize: 2, Cursor: cursor})
+
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		assert.NotEmpty(t, cursor)
+		assert.Equal(t, "2", points[room101][0].Value)
+	})
+
+	t.Run("Fetch", func(t *testing.T) {
+		before := len(s.Queries())
+
+		// テスト対象の関数を実行
+		_, points, fiapErr, err := f.Fetch(keys, &model.FetchOption{AcceptableSize: 2})
+
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		assert.Len(t, points[room101], 5)
+		assert.Contains(t, points, room102)
+		assert.Len(t, s.Queries()[before:], 3)
+	})
+
+	t.Run("MaxAcceptableSize", func(t *testing.T) {
+		s.SetMaxAcceptableSize(4)
+		defer s.SetMaxAcceptableSize(DefaultMaxAcceptableSize)
+
+		// テスト対象の関数を実行
+		_, points, cursor, _, err := f.FetchOnce(keys, nil)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, cursor)
+		assert.Len(t, points[room101], 4)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		// テスト対象の関数を実行
+		_, _, _, fiapErr, err := f.FetchOnce(keys, &model.FetchOnceOption{Cursor: "unknown"})
+
+		require.NoError(t, err)
+		require.NotNil(t, fiapErr)
+		assert.Equal(t, "INVALID_CURSOR", fiapErr.Type)
+	})
+}
+
+func TestServerSubSecond(t *testing.T) {
+	// 100ミリ秒間隔の5つのvalueを持つServer
+	base := time.Date(2012, 2, 2, 16, 34, 0, 0, time.UTC)
+	s := NewServer()
+	t.Cleanup(s.Close)
+	for i := 0; i < 5; i++ {
+		s.AddPoint(room101, model.Value{Time: base.Add(time.Duration(i) * 100 * time.Millisecond), Value: string(rune('0' + i))})
+	}
+	keys := []model.UserInputKey{{
+		ID:   room101,
+		Gteq: testutil.TimeToTimep(base.Add(100 * time.Millisecond)),
+		Lt:   testutil.TimeToTimep(base.Add(300 * time.Millisecond)),
+	}}
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		opts     []fiap.Option
+		expected []string
+	}{
+		// 秒未満が切り捨てられ、gteqとltが同じ時刻になる
+		{name: "RFC3339", expected: []string{}},
+		{name: "RFC3339Nano", opts: []fiap.Option{fiap.WithTimeLayout(time.RFC3339Nano)}, expected: []string{"1", "2"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := fiap.NewFetchClient(s.URL, tc.opts...)
+
+			// テスト対象の関数を実行
+			_, points, fiapErr, err := f.Fetch(keys, nil)
+
+			require.NoError(t, err)
+			assert.Nil(t, fiapErr)
+			actual := []string{}
+			for i, v := range points[room101] {
+				actual = append(actual, v.Value)
+				// 時刻の秒未満も往復して保たれる
+				assert.True(t, v.Time.Equal(base.Add(time.Duration(i+1)*100*time.Millisecond)), "unexpected time %s", v.Time)
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestServerPointNotFound(t *testing.T) {
+	s, _ := newTestServer(t)
+	f := fiap.NewFetchClient(s.URL)
+
+	// テスト対象の関数を実行
+	_, _, fiapErr, err := f.Fetch([]model.UserInputKey{{ID: "http://xxxxxxxx/tokyo/building1/Room999/"}}, nil)
+
+	require.NoError(t, err)
+	require.NotNil(t, fiapErr)
+	assert.Equal(t, "POINT_NOT_FOUND", fiapErr.Type)
+}
+
+func TestServerFailNext(t *testing.T) {
+	s, _ := newTestServer(t)
+	f := fiap.NewFetchClient(s.URL)
+	keys := []model.UserInputKey{{ID: room101}}
+
+	t.Run("fiap error", func(t *testing.T) {
+		s.FailNext(Failure{FIAPError: &model.Error{Type: "SERVER_ERROR", Value: "database is down"}})
+
+		// テスト対象の関数を実行
+		_, _, fiapErr, err := f.Fetch(keys, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, &model.Error{Type: "SERVER_ERROR", Value: "database is down"}, fiapErr)
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		s.FailNext(Failure{StatusCode: http.StatusServiceUnavailable, Body: "maintenance"})
+
+		// テスト対象の関数を実行
+		_, _, _, err := f.Fetch(keys, nil)
+
+		assert.ErrorIs(t, err, fiap.ErrHTTPStatus)
+		var statusErr *fiap.HTTPStatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		s.FailNext(Failure{Latency: 200 * time.Millisecond})
+		timeoutClient := fiap.NewFetchClient(s.URL, fiap.WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}))
+
+		// テスト対象の関数を実行
+		_, _, _, err := timeoutClient.Fetch(keys, nil)
+
+		assert.ErrorIs(t, err, fiap.ErrTransport)
+	})
+
+	t.Run("recovered", func(t *testing.T) {
+		// テスト対象の関数を実行
+		_, points, fiapErr, err := f.Fetch(keys, nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		assert.Len(t, points[room101], 5)
+	})
+}
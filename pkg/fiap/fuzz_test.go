@@ -0,0 +1,131 @@
+package fiap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+// fuzzQueryRSSeeds はfuzzingの初期値に使用するqueryRSのtransport
+var fuzzQueryRSSeeds = []string{
+	`<transport xmlns="http://gutp.jp/fiap/2009/11/"><header><OK/><query id="q" type="storage" cursor="c"/></header><body><point id="p1"><value time="2012-02-02T16:34:05.000+09:00">30</value></point></body></transport>`,
+	`<transport xmlns="http://gutp.jp/fiap/2009/11/"><header><OK/><query id="q" type="storage"/></header><body><pointSet id="ps1"><pointSet id="ps2"/><point id="p1"/></pointSet></body></transport>`,
+	`<transport xmlns="http://gutp.jp/fiap/2009/11/"><header><error type="POINT_NOT_FOUND">not found</error></header></transport>`,
+	`<transport xmlns="http://gutp.jp/fiap/2009/11/"><header><OK/></header></transport>`,
+	`<transport xmlns="http://gutp.jp/fiap/2009/11/"><header/><body><point><value time="invalid">30</value></point></body></transport>`,
+	`<transport xmlns="http://gutp.jp/fiap/2009/11/"><header><OK/></header><body><pointSet id="a"><pointSet><pointSet id="b"><point id="c"/></pointSet></pointSet></pointSet></body></transport>`,
+	``,
+}
+
+// fuzzEnvelope はtransportをqueryRSのSOAPのメッセージに埋め込む
+func fuzzEnvelope(transport string) string {
+	return `<?xml version='1.0' encoding='utf-8'?><soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Header/><soapenv:Body><ns2:queryRS xmlns:ns2="http://soap.fiap.org/">` +
+		transport + `</ns2:queryRS></soapenv:Body></soapenv:Envelope>`
+}
+
+// checkProcessed はFetchOnceとprocessQueryRSの結果が、成功、FIAPのerror、エラーのいずれか1つであることを検証する
+func checkProcessed(t *testing.T, pointSets map[string](model.ProcessedPointSet), points map[string]([]model.Value), fiapErr *model.Error, err error) {
+	t.Helper()
+	switch {
+	case err != nil:
+		if fiapErr != nil || pointSets != nil || points != nil {
+			t.Errorf("results are returned with error: %v", err)
+		}
+	case fiapErr != nil:
+		if pointSets != nil || points != nil {
+			t.Errorf("results are returned with fiap error: %v", fiapErr)
+		}
+	default:
+		if pointSets == nil || points == nil {
+			t.Error("nil maps are returned without error")
+		}
+		for id, values := range points {
+			if id == "" {
+				t.Error("empty point id is returned")
+			}
+			for _, v := range values {
+				if v.Time.IsZero() {
+					t.Errorf("value without time is returned for %s", id)
+				}
+			}
+		}
+		if _, ok := pointSets[""]; ok {
+			t.Error("empty pointSet id is returned")
+		}
+	}
+}
+
+func FuzzPointSetUnmarshalXML(f *testing.F) {
+	f.Add(`<pointSet id="ps1"><pointSet id="ps2"/><point id="p1"/></pointSet>`)
+	f.Add(`<pointSet id="ps1"><pointSet id="ps2"><pointSet id="ps3"/></pointSet><point id="p1"><value time="invalid"/></point></pointSet>`)
+	f.Add(`<pointSet>`)
+
+	f.Fuzz(func(t *testing.T, data string) {
+		p := &model.PointSet{}
+		if err := xml.Unmarshal([]byte(data), p); err != nil {
+			return
+		}
+		if p.PointSetId == nil || p.PointId == nil {
+			t.Error("child ids are nil")
+		}
+	})
+}
+
+func FuzzProcessQueryRS(f *testing.F) {
+	for _, seed := range fuzzQueryRSSeeds {
+		f.Add(seed)
+	}
+	client := &FetchClient{}
+
+	f.Fuzz(func(t *testing.T, transport string) {
+		queryRS := &model.QueryRS{}
+		if err := xml.Unmarshal([]byte(`<queryRS xmlns="http://soap.fiap.org/">`+transport+`</queryRS>`), queryRS); err != nil {
+			return
+		}
+		httpResponse := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(transport)))}
+
+		pointSets, points, _, fiapErr, err := client.processQueryRS(httpResponse, queryRS)
+		checkProcessed(t, pointSets, points, fiapErr, err)
+	})
+}
+
+// fuzzTransport はすべてのリクエストにbodyを返すhttp.RoundTripper
+type fuzzTransport struct {
+	body string
+}
+
+func (tr *fuzzTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/xml; charset=utf-8"}},
+		Body:       io.NopCloser(bytes.NewReader([]byte(tr.body))),
+		Request:    req,
+	}, nil
+}
+
+func FuzzFetchOnce(f *testing.F) {
+	for _, seed := range fuzzQueryRSSeeds {
+		f.Add(fuzzEnvelope(seed))
+	}
+	f.Add(`<html><body>502 Bad Gateway</body></html>`)
+	f.Add(`<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"><soapenv:Body><soapenv:Fault><faultcode>soapenv:Server</faultcode><faultstring>error</faultstring></soapenv:Fault></soapenv:Body></soapenv:Envelope>`)
+	tr := &fuzzTransport{}
+	client := NewFetchClient(defaultConnectionURL, WithHTTPClient(&http.Client{Transport: tr}), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	keys := []model.UserInputKey{{ID: "http://xxxxxxxx/tokyo/building1/Room101/"}}
+
+	f.Fuzz(func(t *testing.T, body string) {
+		tr.body = body
+
+		pointSets, points, _, fiapErr, err := client.FetchOnce(keys, nil)
+		checkProcessed(t, pointSets, points, fiapErr, err)
+		if err != nil && !errors.Is(err, ErrMalformedResponse) && !errors.Is(err, ErrSOAPFault) {
+			t.Errorf("unclassified error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package fiap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/globusdigital/soap"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+/*
+SOAPRequest is an outgoing SOAP request passed to Hook.BeforeSend.
+
+SOAPRequestは、Hook.BeforeSendに渡される送信前のSOAPリクエストです。
+
+QueryRQは送信するクエリです。QueryRQを変更した場合は、変更後のQueryRQからBodyを作成し直して送信します。
+Bodyは送信するSOAPエンベロープのXMLです。Bodyを置き換えた場合は、置き換えた内容をそのまま送信します。
+HTTPRequestは送信するHTTPリクエストです。ヘッダの追加などに使用します。Bodyは読み込まないで下さい。
+*/
+type SOAPRequest struct {
+	QueryRQ     *model.QueryRQ
+	Body        []byte
+	HTTPRequest *http.Request
+}
+
+/*
+SOAPResponse is an incoming SOAP response passed to Hook.AfterReceive.
+
+SOAPResponseは、Hook.AfterReceiveに渡される受信したSOAPレスポンスです。
+
+QueryRQは、このレスポンスを返したリクエストのクエリです。
+Bodyは受信したレスポンスのXMLです。Bodyを置き換えた場合は、置き換えた内容をqueryRSとして解釈します。
+HTTPResponseは受信したHTTPレスポンスです。Bodyは読み込まないで下さい。
+*/
+type SOAPResponse struct {
+	QueryRQ      *model.QueryRQ
+	Body         []byte
+	HTTPResponse *http.Response
+}
+
+/*
+Hook is an interface for inspecting and modifying SOAP requests and responses.
+
+Hookは、SOAPのリクエストとレスポンスの確認や変更を行うためのインターフェースです。
+
+BeforeSend: リクエストの送信前に呼び出されます。
+AfterReceive: レスポンスの受信後、queryRSとして解釈する前に呼び出されます。
+
+複数のHookを設定した場合、BeforeSendは設定した順に、AfterReceiveは設定した逆順に呼び出されます。
+いずれかのHookがエラーを返した場合は、以降のHookを呼び出さずに通信を中断し、エラーを返します。
+*/
+type Hook interface {
+	BeforeSend(ctx context.Context, req *SOAPRequest) error
+	AfterReceive(ctx context.Context, res *SOAPResponse) error
+}
+
+/*
+HookFuncs is an adapter to use functions as a Hook.
+
+HookFuncsは、関数をHookとして使用するためのアダプタです。nilの関数は呼び出されません。
+*/
+type HookFuncs struct {
+	BeforeSendFunc   func(ctx context.Context, req *SOAPRequest) error
+	AfterReceiveFunc func(ctx context.Context, res *SOAPResponse) error
+}
+
+/*
+BeforeSend calls BeforeSendFunc.
+
+BeforeSendは、BeforeSendFuncを呼び出します。
+*/
+func (h HookFuncs) BeforeSend(ctx context.Context, req *SOAPRequest) error {
+	if h.BeforeSendFunc == nil {
+		return nil
+	}
+	return h.BeforeSendFunc(ctx, req)
+}
+
+/*
+AfterReceive calls AfterReceiveFunc.
+
+AfterReceiveは、AfterReceiveFuncを呼び出します。
+*/
+func (h HookFuncs) AfterReceive(ctx context.Context, res *SOAPResponse) error {
+	if h.AfterReceiveFunc == nil {
+		return nil
+	}
+	return h.AfterReceiveFunc(ctx, res)
+}
+
+// hookDoFn はリクエストの送信前とレスポンスの受信後にhooksを呼び出すようにdoFnを包む
+func hookDoFn(doFn func(*http.Request) (*http.Response, error), hooks []Hook, marshaller soap.XMLMarshaller, queryRQ *model.QueryRQ) func(*http.Request) (*http.Response, error) {
+	if len(hooks) == 0 {
+		return doFn
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read request body")
+		}
+		req.Body.Close()
+
+		// QueryRQの変更を検出するため、Hookを呼び出す前の内容を保持する
+		before, err := xml.Marshal(queryRQ)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal queryRQ")
+		}
+		soapReq := &SOAPRequest{QueryRQ: queryRQ, Body: body, HTTPRequest: req}
+		for _, hook := range hooks {
+			if err := hook.BeforeSend(ctx, soapReq); err != nil {
+				return nil, markError(errors.Wrap(err, "BeforeSend hook error"), errHook)
+			}
+		}
+		if bytes.Equal(soapReq.Body, body) {
+			after, err := xml.Marshal(soapReq.QueryRQ)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to marshal queryRQ")
+			}
+			if !bytes.Equal(before, after) {
+				soapReq.Body, err = marshaller.Marshal(soap.Envelope{Body: soap.Body{Content: soapReq.QueryRQ}})
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to marshal envelope")
+				}
+			}
+		}
+		req.Body = io.NopCloser(bytes.NewReader(soapReq.Body))
+		req.ContentLength = int64(len(soapReq.Body))
+
+		res, err := doFn(req)
+		if err != nil {
+			return nil, err
+		}
+		resBody, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read response body")
+		}
+		soapRes := &SOAPResponse{QueryRQ: soapReq.QueryRQ, Body: resBody, HTTPResponse: res}
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if err := hooks[i].AfterReceive(ctx, soapRes); err != nil {
+				return nil, markError(errors.Wrap(err, "AfterReceive hook error"), errHook)
+			}
+		}
+		res.Body = io.NopCloser(bytes.NewReader(soapRes.Body))
+		res.ContentLength = int64(len(soapRes.Body))
+		return res, nil
+	}
+}
@@ -0,0 +1,203 @@
+package fiap
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+func TestFetchOnceHooks(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+
+	body := `
+	<body>
+		<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+			<value time="2012-02-02T16:34:05.000+09:00">30</value>
+		</point>
+	</body>
+	`
+
+	t.Run("inspect", func(t *testing.T) {
+		var (
+			calls       []string
+			requestRQ   *model.QueryRQ
+			requestBody string
+			response    *http.Response
+			rawResponse string
+		)
+		f := NewFetchClient(connectionURL, WithHooks(
+			HookFuncs{
+				BeforeSendFunc: func(ctx context.Context, req *SOAPRequest) error {
+					calls = append(calls, "first.BeforeSend")
+					requestRQ = req.QueryRQ
+					requestBody = string(req.Body)
+					return nil
+				},
+				AfterReceiveFunc: func(ctx context.Context, res *SOAPResponse) error {
+					calls = append(calls, "first.AfterReceive")
+					response = res.HTTPResponse
+					rawResponse = string(res.Body)
+					return nil
+				},
+			},
+			HookFuncs{
+				BeforeSendFunc: func(ctx context.Context, req *SOAPRequest) error {
+					calls = append(calls, "second.BeforeSend")
+					return nil
+				},
+				AfterReceiveFunc: func(ctx context.Context, res *SOAPResponse) error {
+					calls = append(calls, "second.AfterReceive")
+					return nil
+				},
+			},
+		))
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, testutil.CustomBodyResponder(body))
+
+		// テスト対象の関数を実行
+		_, points, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		assert.NoError(t, err)
+		assert.Len(t, points["http://xxxxxxxx/tokyo/building1/Room101/"], 1)
+		assert.Equal(t, []string{"first.BeforeSend", "second.BeforeSend", "second.AfterReceive", "first.AfterReceive"}, calls)
+		assert.Equal(t, "http://xxxxxxxx/tokyo/building1/Room101/", requestRQ.Transport.Header.Query.Key[0].Id)
+		assert.Contains(t, requestBody, `<key id="http://xxxxxxxx/tokyo/building1/Room101/"`)
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Contains(t, rawResponse, `<value time="2012-02-02T16:34:05.000+09:00">30</value>`)
+	})
+
+	t.Run("modify request", func(t *testing.T) {
+		f := NewFetchClient(connectionURL, WithHooks(
+			HookFuncs{
+				BeforeSendFunc: func(ctx context.Context, req *SOAPRequest) error {
+					req.QueryRQ.Transport.Header.Query.AcceptableSize = 10
+					req.HTTPRequest.Header.Set("X-Signature", "signed")
+					return nil
+				},
+			},
+		))
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		// 変更したQueryRQとヘッダが送信されることを確認する
+		matcher := httpmock.NewMatcher("", func(req *http.Request) bool {
+			envelope := &Envelope{}
+			if err := xml.NewDecoder(req.Body).Decode(envelope); err != nil {
+				return false
+			}
+			return envelope.Body.QueryRQ.Transport.Header.Query.AcceptableSize == 10 &&
+				req.Header.Get("X-Signature") == "signed"
+		})
+		httpmock.RegisterMatcherResponder("POST", connectionURL, matcher, testutil.CustomBodyResponder(body))
+
+		// テスト対象の関数を実行
+		_, _, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("replace request body", func(t *testing.T) {
+		f := NewFetchClient(connectionURL, WithHooks(
+			HookFuncs{
+				BeforeSendFunc: func(ctx context.Context, req *SOAPRequest) error {
+					req.Body = []byte(strings.Replace(string(req.Body), "Room101", "Room102", 1))
+					return nil
+				},
+			},
+		))
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		matcher := httpmock.NewMatcher("", func(req *http.Request) bool {
+			b, _ := io.ReadAll(req.Body)
+			return strings.Contains(string(b), "Room102")
+		})
+		httpmock.RegisterMatcherResponder("POST", connectionURL, matcher, testutil.CustomBodyResponder(body))
+
+		// テスト対象の関数を実行
+		_, _, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("modify response", func(t *testing.T) {
+		f := NewFetchClient(connectionURL, WithHooks(
+			HookFuncs{
+				AfterReceiveFunc: func(ctx context.Context, res *SOAPResponse) error {
+					res.Body = []byte(strings.Replace(string(res.Body), ">30<", ">31<", 1))
+					return nil
+				},
+			},
+		))
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, testutil.CustomBodyResponder(body))
+
+		// テスト対象の関数を実行
+		_, points, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "31", points["http://xxxxxxxx/tokyo/building1/Room101/"][0].Value)
+	})
+
+	t.Run("hook error", func(t *testing.T) {
+		called := false
+		f := NewFetchClient(connectionURL, WithHooks(
+			HookFuncs{
+				BeforeSendFunc: func(ctx context.Context, req *SOAPRequest) error {
+					return assert.AnError
+				},
+			},
+			HookFuncs{
+				BeforeSendFunc: func(ctx context.Context, req *SOAPRequest) error {
+					called = true
+					return nil
+				},
+			},
+		))
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, testutil.CustomBodyResponder(body))
+
+		// テスト対象の関数を実行
+		_, _, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Contains(t, err.Error(), "BeforeSend hook error")
+		assert.False(t, called)
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	})
+}
@@ -0,0 +1,39 @@
+package fiap
+
+import (
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+// normalizeLocation はpointsの各時系列データの時刻をlocのタイムゾーンに揃える。locがnilの場合は何もしない
+func normalizeLocation(points map[string]([]model.Value), loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	for _, values := range points {
+		for i := range values {
+			values[i].Time = values[i].Time.In(loc)
+		}
+	}
+}
+
+// keysInLocation はkeysの時刻をlocのタイムゾーンに揃えたコピーを返す。locがnilの場合はkeysをそのまま返す
+func keysInLocation(keys []model.UserInputKey, loc *time.Location) []model.UserInputKey {
+	if loc == nil {
+		return keys
+	}
+	in := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		converted := t.In(loc)
+		return &converted
+	}
+	converted := make([]model.UserInputKey, 0, len(keys))
+	for _, k := range keys {
+		k.Eq, k.Neq, k.Lt, k.Gt, k.Lteq, k.Gteq = in(k.Eq), in(k.Neq), in(k.Lt), in(k.Gt), in(k.Lteq), in(k.Gteq)
+		converted = append(converted, k)
+	}
+	return converted
+}
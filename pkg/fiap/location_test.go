@@ -0,0 +1,84 @@
+package fiap
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+func TestFetchOnceLocation(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
+
+	// +09:00とZが混在したレスポンス
+	body := `
+	<body>
+		<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+			<value time="2012-02-02T16:34:05.000+09:00">30</value>
+			<value time="2012-02-02T07:35:05Z">31</value>
+		</point>
+	</body>
+	`
+
+	t.Run("without location", func(t *testing.T) {
+		f := &FetchClient{ConnectionURL: connectionURL}
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, testutil.CustomBodyResponder(body))
+
+		// テスト対象の関数を実行
+		_, points, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		assert.NoError(t, err)
+		values := points["http://xxxxxxxx/tokyo/building1/Room101/"]
+		_, offset := values[0].Time.Zone()
+		assert.Equal(t, 9*60*60, offset)
+		assert.Equal(t, time.UTC, values[1].Time.Location())
+	})
+
+	t.Run("with location", func(t *testing.T) {
+		f := &FetchClient{ConnectionURL: connectionURL, Location: time.UTC}
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		// keyの時刻がUTCに揃えられて送信されることを確認する
+		matcher := httpmock.NewMatcher("", func(req *http.Request) bool {
+			envelope := &Envelope{}
+			if err := xml.NewDecoder(req.Body).Decode(envelope); err != nil {
+				return false
+			}
+			return envelope.Body.QueryRQ.Transport.Header.Query.Key[0].Gteq == "2012-02-01T15:00:00Z"
+		})
+		httpmock.RegisterMatcherResponder("POST", connectionURL, matcher, testutil.CustomBodyResponder(body))
+
+		// テスト対象の関数を実行
+		fromDate := time.Date(2012, 2, 2, 0, 0, 0, 0, jst)
+		_, points, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/", Gteq: &fromDate},
+		}, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]model.Value{
+			"http://xxxxxxxx/tokyo/building1/Room101/": {
+				{Time: time.Date(2012, 2, 2, 7, 34, 5, 0, time.UTC), Value: "30"},
+				{Time: time.Date(2012, 2, 2, 7, 35, 5, 0, time.UTC), Value: "31"},
+			},
+		}, points)
+		// 呼び出し元のkeyは変更されない
+		assert.Equal(t, jst, fromDate.Location())
+	})
+}
@@ -0,0 +1,124 @@
+package fiap
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
+)
+
+func TestFetchClientLogger(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+
+	body := `
+	<body>
+		<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+			<value time="2012-02-02T16:34:05.000+09:00">30</value>
+			<value time="2012-02-02T16:35:05.000+09:00">31</value>
+		</point>
+	</body>
+	`
+
+	// テストケースを定義
+	testCases := []struct {
+		name        string
+		level       slog.Level
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:  "debug",
+			level: slog.LevelDebug,
+			contains: []string{
+				`level=INFO msg="Fetch end" url=` + connectionURL,
+				`msg="SOAP call start" url=` + connectionURL + ` query_id=`,
+				"page=1",
+				"value_count=2",
+				"duration=",
+			},
+			notContains: []string{"SOAP request", "SOAP response", "Fetch result"},
+		},
+		{
+			name:  "info",
+			level: slog.LevelInfo,
+			contains: []string{
+				`msg="Fetch end"`,
+			},
+			notContains: []string{"level=DEBUG", "SOAP call start"},
+		},
+		{
+			name:  "trace",
+			level: tools.LevelTrace,
+			contains: []string{
+				`level=DEBUG-4 msg="SOAP request"`,
+				`level=DEBUG-4 msg="SOAP response"`,
+				`<point id=\"http://xxxxxxxx/tokyo/building1/Room101/\">`,
+				`level=DEBUG-4 msg="Fetch result"`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			f := &FetchClient{
+				ConnectionURL: connectionURL,
+				Logger:        slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: tc.level})),
+			}
+
+			// mockの有効化
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterResponder("POST", connectionURL, testutil.CustomBodyResponder(body))
+
+			// テスト対象の関数を実行
+			_, _, _, err := f.Fetch([]model.UserInputKey{
+				{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+			}, nil)
+
+			assert.NoError(t, err)
+			for _, s := range tc.contains {
+				assert.Contains(t, buf.String(), s)
+			}
+			for _, s := range tc.notContains {
+				assert.NotContains(t, buf.String(), s)
+			}
+		})
+	}
+}
+
+func TestFetchClientLoggerFIAPError(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	var buf bytes.Buffer
+	f := &FetchClient{
+		ConnectionURL: connectionURL,
+		Logger:        slog.New(slog.NewTextHandler(&buf, nil)),
+	}
+
+	// mockの有効化
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", connectionURL, testutil.CustomHeaderBodyResponder(`
+	<header>
+		<error type="POINT_NOT_FOUND">point is not found</error>
+	</header>
+	`))
+
+	// テスト対象の関数を実行
+	_, _, fiapErr, err := f.Fetch([]model.UserInputKey{
+		{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, fiapErr)
+	assert.Contains(t, buf.String(), `level=WARN msg="Fetch received fiap error"`)
+	assert.Contains(t, buf.String(), "fiap_error_type=POINT_NOT_FOUND")
+}
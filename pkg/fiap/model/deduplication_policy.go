@@ -0,0 +1,48 @@
+package model
+
+/*
+DeduplicationPolicy is a type for the way to sort and de-duplicate fetched values.
+
+DeduplicationPolicy は取得した時系列データの並べ替えと重複除去の方法を表す型です。
+
+DeduplicationNone以外を指定すると、IDごとの時系列データは時刻の昇順に並べ替えられ、時刻と値が同じ重複データは1つにまとめられます。
+同じ時刻で値が異なるデータ(競合)の扱いは、定数ごとに異なります。
+この型の値を指定する場合は、DeduplicationNoneなどの定数を使用してください。
+*/
+type DeduplicationPolicy string
+
+/*
+DeduplicationNone is a constant of DeduplicationPolicy.
+
+DeduplicationNone は DeduplicationPolicy型の定数です。
+
+時系列データを受信した順序のまま、重複を取り除かずに返す場合に使用してください。
+*/
+const DeduplicationNone DeduplicationPolicy = ""
+
+/*
+DeduplicationKeepFirst is a constant of DeduplicationPolicy.
+
+DeduplicationKeepFirst は DeduplicationPolicy型の定数です。
+
+時系列データを並べ替えて重複を取り除き、競合したデータのうち先に受信した値を残す場合に使用してください。
+*/
+const DeduplicationKeepFirst DeduplicationPolicy = "keep_first"
+
+/*
+DeduplicationKeepLast is a constant of DeduplicationPolicy.
+
+DeduplicationKeepLast は DeduplicationPolicy型の定数です。
+
+時系列データを並べ替えて重複を取り除き、競合したデータのうち後に受信した値を残す場合に使用してください。
+*/
+const DeduplicationKeepLast DeduplicationPolicy = "keep_last"
+
+/*
+DeduplicationError is a constant of DeduplicationPolicy.
+
+DeduplicationError は DeduplicationPolicy型の定数です。
+
+時系列データを並べ替えて重複を取り除き、競合したデータがある場合はエラーとする場合に使用してください。
+*/
+const DeduplicationError DeduplicationPolicy = "error"
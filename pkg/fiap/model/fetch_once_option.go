@@ -10,8 +10,15 @@ FetchOnce関数のoptionの型として使用します。FetchOptionとの違い
 AccetableSizeは、fiapのqueryクラス内のacceptableSizeに対応し、一度に受信可能なValueオブジェクトの数を表します。
 
 Cursorは、fiapのqueryクラス内のcursorに対応し、連続したデータを取得するためのポインタを表します。
+
+Deduplicationは、取得した時系列データを時刻順に並べ替え、重複を取り除く方法を表します。
+指定しない場合は、受信した順序のまま重複を取り除かずに返します。
+
+Treeをtrueにすると、pointSetの入れ子の構造と、その中に返された時系列データをFetchResult.Treesに格納します。
 */
 type FetchOnceOption struct {
 	AcceptableSize uint
 	Cursor         string
+	Deduplication  DeduplicationPolicy
+	Tree           bool
 }
@@ -6,7 +6,14 @@ FetchOption is type for Fetch option.
 FetchOptionは、Fetchのオプションの型です。Fetch関数のoptionの型として使用します。
 
 AccetableSizeは、fiapのqueryクラス内のacceptableSizeに対応し、一度に受信可能なValueオブジェクトの数を表します。
+
+Deduplicationは、取得した時系列データを時刻順に並べ替え、重複を取り除く方法を表します。
+指定しない場合は、受信した順序のまま重複を取り除かずに返します。
+
+Treeをtrueにすると、pointSetの入れ子の構造と、その中に返された時系列データをFetchResult.Treesに格納します。
 */
 type FetchOption struct {
 	AcceptableSize uint
+	Deduplication  DeduplicationPolicy
+	Tree           bool
 }
@@ -43,6 +43,17 @@ Keyのselect属性に何も指定しない場合は、SelectTypeNoneを使用し
 const SelectTypeNone SelectType = ""
 
 
+/*
+AttrNameTime and AttrNameValue are the attrName of the Key.
+
+AttrNameTimeとAttrNameValueは、KeyのAttrNameに指定する値です。
+AttrNameTimeは時刻で、AttrNameValueは値で時系列データを絞り込むことを表します。
+*/
+const (
+	AttrNameTime  = "time"
+	AttrNameValue = "value"
+)
+
 /*
 Key is a type used for the Key attribute of Query.
 
@@ -179,9 +190,10 @@ UnmarshalXML is a custom unmarshal function for PointSet.
 PointSet に対するカスタムのUnmarshal関数です。
 
 FIAP通信でOriginalPointSet型として受け取ったデータをPointSet型に変換します。
+子のpointSetとpointはIDのみを読み取り、それより深い要素は読み飛ばします。入れ子の構造が必要な場合は、FetchOption.Treeを指定してください。
 */
 func (p *PointSet) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	aux := new(OriginalPointSet)
+	aux := new(shallowPointSet)
 
 	if err := d.DecodeElement(&aux, &start); err != nil {
 		return err
@@ -208,6 +220,17 @@ func (p *PointSet) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	return nil
 }
 
+// shallowPointSet はPointSetのUnmarshalXMLで使用する型。入れ子のpointSetを再帰的に読み込まないよう、子のIDのみを保持する
+type shallowPointSet struct {
+	PointSet []struct {
+		Id string `xml:"id,attr"`
+	} `xml:"pointSet"`
+	Point []struct {
+		Id string `xml:"id,attr"`
+	} `xml:"point"`
+	Id string `xml:"id,attr"`
+}
+
 /*
 OriginalPointSet is a type used for the PointSet attribute of Body.
 
@@ -12,3 +12,17 @@ type ProcessedPointSet struct {
 	PointSetID []string `json:"point_set_id"`
 	PointID    []string `json:"point_id"`
 }
+
+/*
+PointSetTree is a type for a point set with its nested point sets and points.
+
+PointSetTree は、入れ子のpointSetとpointを含むポイントセットの木構造の型です。
+
+この型は、FetchOption.TreeまたはFetchOnceOption.Treeを指定した場合に、FetchResult.Treesとして返されます。
+PointSetsは子のpointSetのIDをキーとした子の木構造のmap、Pointsは子のpointのIDをキーとした時系列データのmapです。
+FIAPサーバがpointSetの中に時系列データを返さない場合、Pointsの時系列データは空になります。
+*/
+type PointSetTree struct {
+	PointSets map[string]*PointSetTree `json:"point_sets"`
+	Points    map[string]([]Value)     `json:"points"`
+}
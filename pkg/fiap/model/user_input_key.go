@@ -10,6 +10,9 @@ UserInputKey holds information about keys specified by the user.
 UserInputKey は、ユーザーが指定するキーの情報を保持する型です。
 
 この型は、Fetch、FetchOnceを呼び出す際に引数に使用されます。
+
+Valueを指定すると、時刻ではなく値で絞り込むkey(attrName="value")になります。
+この場合、Eq、Neq、Lt、Gt、Lteq、Gteqの時刻の条件は指定できず、MinMaxIndicatorは値の最大と最小を表します。
 */
 type UserInputKey struct {
 	ID              string
@@ -20,6 +23,7 @@ type UserInputKey struct {
 	Lteq            *time.Time
 	Gteq            *time.Time
 	MinMaxIndicator SelectType
+	Value           *ValueCondition
 }
 
 /*
@@ -27,7 +31,7 @@ UserInputKeyNoID holds information about keys specified by the user. (without ID
 
 UserInputKeyNoID は、ユーザーが指定するキーの情報を保持する型です。(IDなし)
 
-この型は、FetchByIdsWithKeyを呼び出す際に引数に使用されます。
+この型は、FetchByIdsWithKeyを呼び出す際に引数に使用されます。Valueの扱いはUserInputKeyと同じです。
 */
 type UserInputKeyNoID struct {
 	Eq              *time.Time
@@ -37,4 +41,22 @@ type UserInputKeyNoID struct {
 	Lteq            *time.Time
 	Gteq            *time.Time
 	MinMaxIndicator SelectType
+	Value           *ValueCondition
+}
+
+/*
+ValueCondition holds the conditions on values for a key with attrName="value".
+
+ValueCondition は、値で絞り込むkey(attrName="value")の条件を保持する型です。
+
+各フィールドは、FIAPのkeyクラスのeq、neq、lt、gt、lteq、gteq属性に対応します。nilの条件は送信しません。
+値は文字列として送信します。数値として比較するか文字列として比較するかは、FIAPサーバの実装によります。
+*/
+type ValueCondition struct {
+	Eq   *string
+	Neq  *string
+	Lt   *string
+	Gt   *string
+	Lteq *string
+	Gteq *string
 }
@@ -0,0 +1,127 @@
+package fiap
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
+)
+
+/*
+Option is a functional option for NewFetchClient.
+
+Optionは、NewFetchClientに渡す設定です。
+*/
+type Option func(*FetchClient)
+
+/*
+NewFetchClient returns a FetchClient configured with the given options.
+
+NewFetchClientは、指定された設定を適用したFetchClientを返します。
+
+設定は引数の順に適用されます。同じ項目を設定するOptionを複数指定した場合は、後に指定したものが有効になります。
+*/
+func NewFetchClient(connectionURL string, opts ...Option) *FetchClient {
+	f := &FetchClient{ConnectionURL: connectionURL}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+/*
+WithLogger sets the logger of the client.
+
+WithLoggerは、クライアントがログの出力に使用する*slog.Loggerを設定します。
+*/
+func WithLogger(logger *slog.Logger) Option {
+	return func(f *FetchClient) {
+		f.Logger = logger
+	}
+}
+
+/*
+WithLogLevel sets the log level of the client.
+
+WithLogLevelは、クライアントのログレベルを設定します。
+
+ログは、tools.NewLogHandlerと同様に標準のlogパッケージを通して出力されます。
+tools.SetLogLevelで設定したログレベルには影響されず、他のクライアントにも影響しません。
+*/
+func WithLogLevel(level slog.Leveler) Option {
+	return func(f *FetchClient) {
+		f.Logger = slog.New(tools.NewLogHandler(level))
+	}
+}
+
+/*
+WithLocation sets the location used to normalize times.
+
+WithLocationは、時刻を揃えるタイムゾーンを設定します。
+*/
+func WithLocation(location *time.Location) Option {
+	return func(f *FetchClient) {
+		f.Location = location
+	}
+}
+
+/*
+WithHTTPClient sets the HTTP client used to send requests.
+
+WithHTTPClientは、リクエストの送信に使用する*http.Clientを設定します。
+タイムアウトやプロキシ、TLSの設定を変更する場合に使用します。
+*/
+func WithHTTPClient(client *http.Client) Option {
+	return func(f *FetchClient) {
+		f.HTTPClient = client
+	}
+}
+
+/*
+WithTracerProvider sets the OpenTelemetry TracerProvider used to create spans.
+
+WithTracerProviderは、spanの作成に使用するOpenTelemetryのTracerProviderを設定します。
+*/
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(f *FetchClient) {
+		f.TracerProvider = provider
+	}
+}
+
+/*
+WithMeterProvider sets the OpenTelemetry MeterProvider used to record metrics.
+
+WithMeterProviderは、メトリクスの記録に使用するOpenTelemetryのMeterProviderを設定します。
+*/
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(f *FetchClient) {
+		f.MeterProvider = provider
+	}
+}
+
+/*
+WithHooks appends hooks called before sending requests and after receiving responses.
+
+WithHooksは、リクエストの送信前とレスポンスの受信後に呼び出すHookを追加します。
+*/
+func WithHooks(hooks ...Hook) Option {
+	return func(f *FetchClient) {
+		f.Hooks = append(f.Hooks, hooks...)
+	}
+}
+
+/*
+WithTimeLayout sets the layout of the times in the keys sent to the server.
+
+WithTimeLayoutは、FIAPサーバに送信するkeyの時刻の書式を設定します。
+秒未満の精度で範囲を指定する場合は、time.RFC3339Nanoを指定します。
+*/
+func WithTimeLayout(layout string) Option {
+	return func(f *FetchClient) {
+		f.TimeLayout = layout
+	}
+}
@@ -0,0 +1,169 @@
+package fiap
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
+)
+
+func TestNewFetchClient(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	httpClient := &http.Client{Timeout: time.Second}
+
+	t.Run("without options", func(t *testing.T) {
+		f := NewFetchClient(defaultConnectionURL)
+
+		assert.Equal(t, &FetchClient{ConnectionURL: defaultConnectionURL}, f)
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		f := NewFetchClient(defaultConnectionURL,
+			WithLocation(time.UTC),
+			WithLogger(logger),
+			WithHTTPClient(httpClient),
+		)
+
+		assert.Equal(t, defaultConnectionURL, f.ConnectionURL)
+		assert.Equal(t, time.UTC, f.Location)
+		assert.Same(t, logger, f.Logger)
+		assert.Same(t, httpClient, f.HTTPClient)
+	})
+
+	t.Run("with time layout", func(t *testing.T) {
+		f := NewFetchClient(defaultConnectionURL, WithTimeLayout(time.RFC3339Nano))
+
+		assert.Equal(t, time.RFC3339Nano, f.TimeLayout)
+	})
+
+	t.Run("later option wins", func(t *testing.T) {
+		f := NewFetchClient(defaultConnectionURL, WithLogger(logger), WithLogLevel(slog.LevelInfo))
+
+		assert.NotSame(t, logger, f.Logger)
+		assert.True(t, f.Logger.Enabled(context.Background(), slog.LevelInfo))
+		assert.False(t, f.Logger.Enabled(context.Background(), slog.LevelDebug))
+	})
+}
+
+func TestWithLogLevelIsPerClient(t *testing.T) {
+	defer tools.SetLogLevel(tools.LogLevelError)
+	tools.SetLogLevel(tools.LogLevelError)
+
+	debugClient := NewFetchClient(defaultConnectionURL, WithLogLevel(slog.LevelDebug))
+	errorClient := NewFetchClient(defaultConnectionURL, WithLogLevel(slog.LevelError))
+	defaultClient := NewFetchClient(defaultConnectionURL)
+
+	assert.True(t, debugClient.logger().Enabled(context.Background(), slog.LevelDebug))
+	assert.False(t, errorClient.logger().Enabled(context.Background(), slog.LevelWarn))
+	assert.False(t, defaultClient.logger().Enabled(context.Background(), slog.LevelDebug))
+
+	// グローバルのログレベルはLoggerを設定していないクライアントにのみ適用される
+	tools.SetLogLevel(tools.LogLevelDebug)
+	assert.True(t, defaultClient.logger().Enabled(context.Background(), slog.LevelDebug))
+	assert.False(t, errorClient.logger().Enabled(context.Background(), slog.LevelWarn))
+}
+
+func TestSetLogLevelConcurrently(t *testing.T) {
+	defer tools.SetLogLevel(tools.LogLevelError)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				tools.SetLogLevel(tools.LogLevelDebug)
+			} else {
+				tools.SetLogLevel(tools.LogLevelError)
+			}
+			NewFetchClient(defaultConnectionURL).logger().Enabled(context.Background(), slog.LevelDebug)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+
+	// mockの有効化
+	transport := httpmock.NewMockTransport()
+	transport.RegisterResponder("POST", connectionURL, testutil.CustomBodyResponder(`
+	<body>
+		<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+			<value time="2012-02-02T16:34:05.000+09:00">30</value>
+		</point>
+	</body>
+	`))
+
+	f := NewFetchClient(connectionURL, WithHTTPClient(&http.Client{Transport: transport}))
+
+	// テスト対象の関数を実行
+	_, points, _, _, err := f.FetchOnce([]model.UserInputKey{
+		{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+	}, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, points["http://xxxxxxxx/tokyo/building1/Room101/"], 1)
+	assert.Equal(t, 1, transport.GetTotalCallCount())
+}
+
+func TestWithTimeLayout(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	id := "http://xxxxxxxx/tokyo/building1/Room101/"
+	from := time.Date(2012, 2, 2, 7, 34, 5, 100000000, time.UTC)
+	until := time.Date(2012, 2, 2, 7, 34, 5, 250000000, time.UTC)
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{name: "default", expected: `lteq="2012-02-02T07:34:05Z" gteq="2012-02-02T07:34:05Z"`},
+		{name: "RFC3339Nano", opts: []Option{WithTimeLayout(time.RFC3339Nano)}, expected: `lteq="2012-02-02T07:34:05.25Z" gteq="2012-02-02T07:34:05.1Z"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requestBody string
+			// mockの有効化
+			transport := httpmock.NewMockTransport()
+			transport.RegisterResponder("POST", connectionURL, func(req *http.Request) (*http.Response, error) {
+				body, err := io.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				requestBody = string(body)
+				return testutil.CustomBodyResponder(`
+				<body>
+					<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+						<value time="2012-02-02T16:34:05.123456789+09:00">30</value>
+					</point>
+				</body>
+				`)(req)
+			})
+			f := NewFetchClient(connectionURL, append(tc.opts, WithHTTPClient(&http.Client{Transport: transport}))...)
+
+			// テスト対象の関数を実行
+			_, points, _, _, err := f.FetchOnce([]model.UserInputKey{{ID: id, Gteq: &from, Lteq: &until}}, nil)
+
+			assert.NoError(t, err)
+			assert.Contains(t, requestBody, tc.expected)
+			// 受信した時刻は書式によらず秒未満も含めて解釈する
+			if assert.Len(t, points[id], 1) {
+				assert.Equal(t, 123456789, points[id][0].Time.Nanosecond())
+			}
+		})
+	}
+}
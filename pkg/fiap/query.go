@@ -0,0 +1,321 @@
+package fiap
+
+import (
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/cockroachdb/errors"
+)
+
+/*
+QueryBuilder composes the keys and options for Fetch and FetchOnce.
+
+QueryBuilderは、FetchとFetchOnceに渡すkeysとoptionを組み立てるためのビルダです。
+
+Query関数で作成し、メソッドを連結して条件を指定します。条件はIDsで指定したすべてのIDに適用されます。
+	keys, option, err := fiap.Query().
+		IDs("id1", "id2").
+		From(fromDate).
+		Until(untilDate).
+		Latest().
+		AcceptableSize(100).
+		Build()
+	if err != nil {
+		return err
+	}
+	pointSets, points, fiapErr, err := fetchClient.Fetch(keys, option)
+
+Build、BuildOnce、Keysは、作成したkeysをValidateKeysで検証し、矛盾する条件が指定されている場合にエラーを返します。
+エラーはすべての問題をまとめたもので、errors.Is(err, ErrInvalidQuery)で判定できます。
+keysの問題によるエラーは、errors.Is(err, ErrInvalidKey)でも判定できます。
+*/
+type QueryBuilder struct {
+	ids            []string
+	key            model.UserInputKeyNoID
+	acceptableSize uint
+	deduplication  model.DeduplicationPolicy
+	cursor         string
+	tree           bool
+	errs           []error
+}
+
+/*
+Query returns a new QueryBuilder.
+
+Queryは、新しいQueryBuilderを返します。
+*/
+func Query() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+/*
+IDs adds the IDs of the points to fetch.
+
+IDsは、取得するデータのIDを追加します。複数回呼び出した場合は、すべてのIDを取得します。
+*/
+func (q *QueryBuilder) IDs(ids ...string) *QueryBuilder {
+	q.ids = append(q.ids, ids...)
+	return q
+}
+
+/*
+At fetches the value at exactly t. (eq)
+
+Atは、時刻がtと一致するデータを取得します。(eq)
+*/
+func (q *QueryBuilder) At(t time.Time) *QueryBuilder {
+	q.key.Eq = &t
+	return q
+}
+
+/*
+Except excludes the value at exactly t. (neq)
+
+Exceptは、時刻がtと一致するデータを除外します。(neq)
+*/
+func (q *QueryBuilder) Except(t time.Time) *QueryBuilder {
+	q.key.Neq = &t
+	return q
+}
+
+/*
+From fetches values at or after t. (gteq)
+
+Fromは、時刻がt以降のデータを取得します。(gteq)
+*/
+func (q *QueryBuilder) From(t time.Time) *QueryBuilder {
+	q.key.Gteq = &t
+	return q
+}
+
+/*
+Until fetches values at or before t. (lteq)
+
+Untilは、時刻がt以前のデータを取得します。(lteq)
+*/
+func (q *QueryBuilder) Until(t time.Time) *QueryBuilder {
+	q.key.Lteq = &t
+	return q
+}
+
+/*
+After fetches values strictly after t. (gt)
+
+Afterは、時刻がtより後のデータを取得します。(gt)
+*/
+func (q *QueryBuilder) After(t time.Time) *QueryBuilder {
+	q.key.Gt = &t
+	return q
+}
+
+/*
+Before fetches values strictly before t. (lt)
+
+Beforeは、時刻がtより前のデータを取得します。(lt)
+*/
+func (q *QueryBuilder) Before(t time.Time) *QueryBuilder {
+	q.key.Lt = &t
+	return q
+}
+
+/*
+ValueEquals fetches the values equal to v. (attrName="value", eq)
+
+ValueEqualsは、値がvと一致するデータを取得します。(attrName="value", eq)
+値の条件は時刻の条件と同時に指定できません。
+*/
+func (q *QueryBuilder) ValueEquals(v string) *QueryBuilder {
+	q.value().Eq = &v
+	return q
+}
+
+/*
+ValueNotEquals excludes the values equal to v. (attrName="value", neq)
+
+ValueNotEqualsは、値がvと一致するデータを除外します。(attrName="value", neq)
+*/
+func (q *QueryBuilder) ValueNotEquals(v string) *QueryBuilder {
+	q.value().Neq = &v
+	return q
+}
+
+/*
+ValueAtLeast fetches the values greater than or equal to v. (attrName="value", gteq)
+
+ValueAtLeastは、値がv以上のデータを取得します。(attrName="value", gteq)
+*/
+func (q *QueryBuilder) ValueAtLeast(v string) *QueryBuilder {
+	q.value().Gteq = &v
+	return q
+}
+
+/*
+ValueAtMost fetches the values less than or equal to v. (attrName="value", lteq)
+
+ValueAtMostは、値がv以下のデータを取得します。(attrName="value", lteq)
+*/
+func (q *QueryBuilder) ValueAtMost(v string) *QueryBuilder {
+	q.value().Lteq = &v
+	return q
+}
+
+/*
+ValueAbove fetches the values strictly greater than v. (attrName="value", gt)
+
+ValueAboveは、値がvより大きいデータを取得します。(attrName="value", gt)
+*/
+func (q *QueryBuilder) ValueAbove(v string) *QueryBuilder {
+	q.value().Gt = &v
+	return q
+}
+
+/*
+ValueBelow fetches the values strictly less than v. (attrName="value", lt)
+
+ValueBelowは、値がvより小さいデータを取得します。(attrName="value", lt)
+*/
+func (q *QueryBuilder) ValueBelow(v string) *QueryBuilder {
+	q.value().Lt = &v
+	return q
+}
+
+// value は値の条件を返す。まだ値の条件が指定されていない場合は作成する
+func (q *QueryBuilder) value() *model.ValueCondition {
+	if q.key.Value == nil {
+		q.key.Value = &model.ValueCondition{}
+	}
+	return q.key.Value
+}
+
+/*
+Latest fetches only the latest value. (select="maximum")
+
+Latestは、最新のデータのみを取得します。(select="maximum")
+*/
+func (q *QueryBuilder) Latest() *QueryBuilder {
+	return q.selectType(model.SelectTypeMaximum)
+}
+
+/*
+Oldest fetches only the oldest value. (select="minimum")
+
+Oldestは、最古のデータのみを取得します。(select="minimum")
+*/
+func (q *QueryBuilder) Oldest() *QueryBuilder {
+	return q.selectType(model.SelectTypeMinimum)
+}
+
+// selectType はselectを設定する。LatestとOldestの両方が指定された場合はエラーを記録する
+func (q *QueryBuilder) selectType(selectType model.SelectType) *QueryBuilder {
+	if q.key.MinMaxIndicator != model.SelectTypeNone && q.key.MinMaxIndicator != selectType {
+		q.errs = append(q.errs, errors.New("Latest and Oldest cannot be combined"))
+	}
+	q.key.MinMaxIndicator = selectType
+	return q
+}
+
+/*
+AcceptableSize sets the maximum number of values the server returns at once.
+
+AcceptableSizeは、FIAPサーバが一度に返すデータの数の上限を設定します。
+*/
+func (q *QueryBuilder) AcceptableSize(n uint) *QueryBuilder {
+	q.acceptableSize = n
+	return q
+}
+
+/*
+Deduplication sets how to sort and deduplicate the fetched values.
+
+Deduplicationは、取得した時系列データを並べ替え、重複を取り除く方法を設定します。
+*/
+func (q *QueryBuilder) Deduplication(policy model.DeduplicationPolicy) *QueryBuilder {
+	q.deduplication = policy
+	return q
+}
+
+/*
+Cursor sets the cursor returned by a previous FetchOnce. It is used only by BuildOnce.
+
+Cursorは、前回のFetchOnceが返したcursorを設定します。BuildOnceでのみ使用されます。
+*/
+func (q *QueryBuilder) Cursor(cursor string) *QueryBuilder {
+	q.cursor = cursor
+	return q
+}
+
+/*
+Tree sets the FetchResult to contain the nested structure of the point sets.
+
+Treeは、FetchResult.TreesにpointSetの入れ子の構造を格納するように設定します。
+*/
+func (q *QueryBuilder) Tree() *QueryBuilder {
+	q.tree = true
+	return q
+}
+
+/*
+Keys builds and validates the keys for Fetch and FetchOnce.
+
+Keysは、FetchとFetchOnceに渡すkeysを作成し、ValidateKeysで検証して返します。
+返すkeyはそれぞれ値の条件の複製を持つため、Keysの後にQueryBuilderのメソッドを呼び出しても、返したkeysは変わりません。
+
+errの発生条件
+ - LatestとOldestが同時に指定されている場合
+ - ValidateKeysでエラーになる場合(IDsでIDが指定されていない場合、範囲の開始時刻が終了時刻より後の場合、値の条件と時刻の条件が同時に指定されている場合など)
+*/
+func (q *QueryBuilder) Keys() ([]model.UserInputKey, error) {
+	keys := make([]model.UserInputKey, 0, len(q.ids))
+	for _, id := range q.ids {
+		key := model.UserInputKey{
+			ID:              id,
+			Eq:              q.key.Eq,
+			Neq:             q.key.Neq,
+			Lt:              q.key.Lt,
+			Gt:              q.key.Gt,
+			Lteq:            q.key.Lteq,
+			Gteq:            q.key.Gteq,
+			MinMaxIndicator: q.key.MinMaxIndicator,
+		}
+		if q.key.Value != nil {
+			value := *q.key.Value
+			key.Value = &value
+		}
+		keys = append(keys, key)
+	}
+
+	errs := append([]error{}, q.errs...)
+	if err := ValidateKeys(keys); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return keys, nil
+	}
+	return nil, markError(errors.Join(errs...), ErrInvalidQuery)
+}
+
+/*
+Build returns the keys and option for Fetch.
+
+Buildは、Fetchに渡すkeysとoptionを返します。errの発生条件はKeysと同じです。
+*/
+func (q *QueryBuilder) Build() ([]model.UserInputKey, *model.FetchOption, error) {
+	keys, err := q.Keys()
+	if err != nil {
+		return nil, nil, err
+	}
+	return keys, &model.FetchOption{AcceptableSize: q.acceptableSize, Deduplication: q.deduplication, Tree: q.tree}, nil
+}
+
+/*
+BuildOnce returns the keys and option for FetchOnce.
+
+BuildOnceは、FetchOnceに渡すkeysとoptionを返します。errの発生条件はKeysと同じです。
+*/
+func (q *QueryBuilder) BuildOnce() ([]model.UserInputKey, *model.FetchOnceOption, error) {
+	keys, err := q.Keys()
+	if err != nil {
+		return nil, nil, err
+	}
+	return keys, &model.FetchOnceOption{AcceptableSize: q.acceptableSize, Cursor: q.cursor, Deduplication: q.deduplication, Tree: q.tree}, nil
+}
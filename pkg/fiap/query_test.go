@@ -0,0 +1,284 @@
+package fiap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	fromDate := time.Date(2012, 2, 2, 0, 0, 0, 0, time.UTC)
+	untilDate := time.Date(2012, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Build", func(t *testing.T) {
+		keys, option, err := Query().
+			IDs("http://xxxxxxxx/id1", "http://xxxxxxxx/id2").
+			From(fromDate).
+			Until(untilDate).
+			Latest().
+			AcceptableSize(100).
+			Deduplication(model.DeduplicationKeepLast).
+			Build()
+
+		require.NoError(t, err)
+		assert.Equal(t, []model.UserInputKey{
+			{ID: "http://xxxxxxxx/id1", Gteq: testutil.TimeToTimep(fromDate), Lteq: testutil.TimeToTimep(untilDate), MinMaxIndicator: model.SelectTypeMaximum},
+			{ID: "http://xxxxxxxx/id2", Gteq: testutil.TimeToTimep(fromDate), Lteq: testutil.TimeToTimep(untilDate), MinMaxIndicator: model.SelectTypeMaximum},
+		}, keys)
+		assert.Equal(t, &model.FetchOption{AcceptableSize: 100, Deduplication: model.DeduplicationKeepLast}, option)
+	})
+
+	t.Run("BuildOnce", func(t *testing.T) {
+		keys, option, err := Query().
+			IDs("http://xxxxxxxx/id1").
+			After(fromDate).
+			Before(untilDate).
+			Oldest().
+			AcceptableSize(10).
+			Cursor("cursor1").
+			BuildOnce()
+
+		require.NoError(t, err)
+		assert.Equal(t, []model.UserInputKey{
+			{ID: "http://xxxxxxxx/id1", Gt: testutil.TimeToTimep(fromDate), Lt: testutil.TimeToTimep(untilDate), MinMaxIndicator: model.SelectTypeMinimum},
+		}, keys)
+		assert.Equal(t, &model.FetchOnceOption{AcceptableSize: 10, Cursor: "cursor1"}, option)
+	})
+
+	t.Run("At", func(t *testing.T) {
+		keys, err := Query().IDs("http://xxxxxxxx/id1").At(fromDate).Keys()
+
+		require.NoError(t, err)
+		assert.Equal(t, []model.UserInputKey{{ID: "http://xxxxxxxx/id1", Eq: testutil.TimeToTimep(fromDate)}}, keys)
+	})
+
+	t.Run("same from and until", func(t *testing.T) {
+		_, err := Query().IDs("http://xxxxxxxx/id1").From(fromDate).Until(fromDate).Keys()
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("value range", func(t *testing.T) {
+		keys, err := Query().IDs("http://xxxxxxxx/id1", "http://xxxxxxxx/id2").ValueAtLeast("9").ValueBelow("10").ValueNotEquals("9.5").Latest().Keys()
+
+		require.NoError(t, err)
+		value := &model.ValueCondition{Gteq: testutil.StringToStringp("9"), Lt: testutil.StringToStringp("10"), Neq: testutil.StringToStringp("9.5")}
+		assert.Equal(t, []model.UserInputKey{
+			{ID: "http://xxxxxxxx/id1", Value: value, MinMaxIndicator: model.SelectTypeMaximum},
+			{ID: "http://xxxxxxxx/id2", Value: value, MinMaxIndicator: model.SelectTypeMaximum},
+		}, keys)
+	})
+
+	t.Run("keys do not share value condition", func(t *testing.T) {
+		q := Query().IDs("http://xxxxxxxx/id1", "http://xxxxxxxx/id2").ValueAtLeast("9")
+		keys, err := q.Keys()
+		require.NoError(t, err)
+
+		// Keysの後にQueryBuilderで条件を追加しても、返したkeysは変わらない
+		q.ValueAbove("10")
+		keys[0].Value.Lt = testutil.StringToStringp("20")
+
+		value := &model.ValueCondition{Gteq: testutil.StringToStringp("9")}
+		assert.Equal(t, value, keys[1].Value)
+		assert.NotSame(t, keys[0].Value, keys[1].Value)
+	})
+
+	t.Run("value equals", func(t *testing.T) {
+		keys, err := Query().IDs("http://xxxxxxxx/id1").ValueEquals("ON").Keys()
+
+		require.NoError(t, err)
+		assert.Equal(t, []model.UserInputKey{{ID: "http://xxxxxxxx/id1", Value: &model.ValueCondition{Eq: testutil.StringToStringp("ON")}}}, keys)
+	})
+
+	t.Run("value above and at most", func(t *testing.T) {
+		keys, err := Query().IDs("http://xxxxxxxx/id1").ValueAbove("a").ValueAtMost("b").Keys()
+
+		require.NoError(t, err)
+		assert.Equal(t, []model.UserInputKey{{ID: "http://xxxxxxxx/id1", Value: &model.ValueCondition{Gt: testutil.StringToStringp("a"), Lteq: testutil.StringToStringp("b")}}}, keys)
+	})
+}
+
+func TestQueryBuilderInvalid(t *testing.T) {
+	fromDate := time.Date(2012, 2, 2, 0, 0, 0, 0, time.UTC)
+	untilDate := time.Date(2012, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		query    *QueryBuilder
+		is       []error
+		messages []string
+	}{
+		{
+			name:     "no ids",
+			query:    Query().Latest(),
+			is:       []error{ErrInvalidKey, ErrEmptyKeys},
+			messages: []string{"keys is empty"},
+		},
+		{
+			name:     "empty id",
+			query:    Query().IDs("http://xxxxxxxx/id1", ""),
+			is:       []error{ErrInvalidKey, ErrEmptyID},
+			messages: []string{"keys.ID is empty, index: 1"},
+		},
+		{
+			name:     "At with range",
+			query:    Query().IDs("http://xxxxxxxx/id1").At(fromDate).Until(untilDate),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"keys.Eq is combined with other time conditions, index: 0"},
+		},
+		{
+			name:     "At with Latest",
+			query:    Query().IDs("http://xxxxxxxx/id1").At(fromDate).Latest(),
+			is:       []error{ErrInvalidKey},
+			messages: []string{`keys.MinMaxIndicator "maximum" is combined with keys.Eq, index: 0`},
+		},
+		{
+			name:     "From and After",
+			query:    Query().IDs("http://xxxxxxxx/id1").From(fromDate).After(fromDate),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"both keys.Gt and keys.Gteq are set, index: 0"},
+		},
+		{
+			name:     "Until and Before",
+			query:    Query().IDs("http://xxxxxxxx/id1").Until(untilDate).Before(untilDate),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"both keys.Lt and keys.Lteq are set, index: 0"},
+		},
+		{
+			name:     "from after until",
+			query:    Query().IDs("http://xxxxxxxx/id1").From(untilDate).Until(fromDate),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"keys range is inverted, index: 0, from: 2012-02-03T00:00:00Z, until: 2012-02-02T00:00:00Z"},
+		},
+		{
+			name:     "exclusive bounds at the same time",
+			query:    Query().IDs("http://xxxxxxxx/id1").After(fromDate).Until(fromDate),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"keys range is inverted, index: 0, from: 2012-02-02T00:00:00Z, until: 2012-02-02T00:00:00Z"},
+		},
+		{
+			name:     "Latest and Oldest",
+			query:    Query().IDs("http://xxxxxxxx/id1").Latest().Oldest(),
+			messages: []string{"Latest and Oldest cannot be combined"},
+		},
+		{
+			name:     "value with time",
+			query:    Query().IDs("http://xxxxxxxx/id1").ValueEquals("30").From(fromDate),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"keys.Value is combined with time conditions, index: 0"},
+		},
+		{
+			name:     "empty value",
+			query:    Query().IDs("http://xxxxxxxx/id1").ValueEquals(""),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"keys.Value.Eq is empty, index: 0"},
+		},
+		{
+			name:     "ValueEquals with range",
+			query:    Query().IDs("http://xxxxxxxx/id1").ValueEquals("30").ValueAtMost("40"),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"keys.Value.Eq is combined with other value conditions, index: 0"},
+		},
+		{
+			name:     "ValueEquals with ValueNotEquals",
+			query:    Query().IDs("http://xxxxxxxx/id1").ValueEquals("30").ValueNotEquals("40"),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"keys.Value.Eq is combined with other value conditions, index: 0"},
+		},
+		{
+			name:     "ValueEquals with Oldest",
+			query:    Query().IDs("http://xxxxxxxx/id1").ValueEquals("30").Oldest(),
+			is:       []error{ErrInvalidKey},
+			messages: []string{`keys.MinMaxIndicator "minimum" is combined with keys.Value.Eq, index: 0`},
+		},
+		{
+			name:     "ValueAtLeast and ValueAbove",
+			query:    Query().IDs("http://xxxxxxxx/id1").ValueAtLeast("10").ValueAbove("10"),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"both keys.Value.Gt and keys.Value.Gteq are set, index: 0"},
+		},
+		{
+			name:     "ValueAtMost and ValueBelow",
+			query:    Query().IDs("http://xxxxxxxx/id1").ValueAtMost("10").ValueBelow("10"),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"both keys.Value.Lt and keys.Value.Lteq are set, index: 0"},
+		},
+		{
+			name:     "numeric value range is empty",
+			query:    Query().IDs("http://xxxxxxxx/id1").ValueAtLeast("10").ValueAtMost("9"),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"keys value range is inverted, index: 0, from: 10, until: 9"},
+		},
+		{
+			name:     "exclusive value bounds at the same value",
+			query:    Query().IDs("http://xxxxxxxx/id1").ValueAbove("ON").ValueAtMost("ON"),
+			is:       []error{ErrInvalidKey},
+			messages: []string{"keys value range is inverted, index: 0, from: ON, until: ON"},
+		},
+		{
+			name:  "multiple problems",
+			query: Query().IDs("http://xxxxxxxx/id1", "").At(fromDate).Oldest().Latest().From(untilDate).Until(fromDate),
+			is:    []error{ErrInvalidKey, ErrEmptyID},
+			messages: []string{
+				"Latest and Oldest cannot be combined",
+				"keys.ID is empty, index: 1",
+				"keys.Eq is combined with other time conditions, index: 0",
+				`keys.MinMaxIndicator "maximum" is combined with keys.Eq, index: 0`,
+				"keys range is inverted, index: 0",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, option, err := tc.query.Build()
+
+			assert.Nil(t, keys)
+			assert.Nil(t, option)
+			assert.ErrorIs(t, err, ErrInvalidQuery)
+			for _, target := range tc.is {
+				assert.ErrorIs(t, err, target)
+			}
+			for _, message := range tc.messages {
+				assert.Contains(t, err.Error(), message)
+			}
+		})
+	}
+}
+
+func TestQueryBuilderInvalidKeys(t *testing.T) {
+	// テストケースを定義
+	testCases := []struct {
+		name    string
+		query   *QueryBuilder
+		message string
+	}{
+		{
+			name:    "invalid id",
+			query:   Query().IDs("http://xxxxxxxx/tokyo/building1/Room 101/"),
+			message: "keys.ID is not a valid URI",
+		},
+		{
+			name:    "duplicate ids",
+			query:   Query().IDs("http://xxxxxxxx/id1", "http://xxxxxxxx/id2").IDs("http://xxxxxxxx/id1"),
+			message: "keys is duplicated, index: 0 and 2, id: http://xxxxxxxx/id1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, err := tc.query.Keys()
+
+			assert.Nil(t, keys)
+			assert.ErrorIs(t, err, ErrInvalidKey)
+			assert.ErrorIs(t, err, ErrInvalidQuery)
+			assert.Contains(t, err.Error(), tc.message)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package fiap
+
+import (
+	"context"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/cockroachdb/errors"
+)
+
+/*
+FetchResult is the result of fetching data from the FIAP server.
+
+FetchResultは、FIAPサーバからデータを取得した結果です。
+
+ - PointSets: keysの中で指定したIDをキーとして取得したpointSetIDとPointIDのデータのmap
+ - Points: keysで指定したIDをキーとして取得した時系列データのmap
+ - Trees: keysで指定したpointSetのIDをキーとした、入れ子のpointSetとpointを含む木構造のmap。FetchOption.TreeまたはFetchOnceOption.Treeがtrueの場合のみ設定され、それ以外の場合はnil
+ - Cursor: 後続のfetchのためのカーソル。FetchOnceContextでのみ設定され、データを最後まで取得できた場合は""
+ - FIAPError: fiap通信の<error>タグを格納する構造体。タグがない場合はnil
+ - PageCount: FIAPサーバにクエリを送信した回数
+ - StartTime: 取得を開始した時刻
+ - Duration: 取得にかかった時間
+ - QueryIDs: FIAPサーバに送信したクエリのID。送信した順に、ページごとに1つ格納されます
+
+FIAPErrorがnilでない場合、PointSetsとPointsにはFIAPErrorを受信する前のページまでに取得したデータが格納されます。
+*/
+type FetchResult struct {
+	PointSets map[string](model.ProcessedPointSet)
+	Points    map[string]([]model.Value)
+	Trees     map[string]*model.PointSetTree
+	Cursor    string
+	FIAPError *model.Error
+	PageCount int
+	StartTime time.Time
+	Duration  time.Duration
+	QueryIDs  []string
+}
+
+/*
+ResultFetcher is an interface for fetching data from the FIAP server with a context, returning a FetchResult.
+
+ResultFetcherは、contextを指定してFIAPサーバからデータを取得し、FetchResultを返すためのインターフェースです。
+
+各メソッドは、Fetcherの同名のメソッドと同じデータを取得します。
+ctxがキャンセルされた場合は、通信を中断してエラーを返します。
+*/
+type ResultFetcher interface {
+	FetchContext(ctx context.Context, keys []model.UserInputKey, option *model.FetchOption) (*FetchResult, error)
+	FetchOnceContext(ctx context.Context, keys []model.UserInputKey, option *model.FetchOnceOption) (*FetchResult, error)
+	FetchByIdsWithKeyContext(ctx context.Context, key model.UserInputKeyNoID, ids ...string) (*FetchResult, error)
+	FetchLatestContext(ctx context.Context, fromDate *time.Time, untilDate *time.Time, ids ...string) (*FetchResult, error)
+	FetchOldestContext(ctx context.Context, fromDate *time.Time, untilDate *time.Time, ids ...string) (*FetchResult, error)
+	FetchDateRangeContext(ctx context.Context, fromDate *time.Time, untilDate *time.Time, ids ...string) (*FetchResult, error)
+}
+
+/*
+FetchContext is like Fetch but takes a context and returns a FetchResult.
+
+FetchContextは、Fetchと同様にデータを取得し、結果をFetchResultとして返します。
+
+errの発生条件はFetchと同じです。errがnilでない場合、FetchResultはnilです。
+*/
+func (f *FetchClient) FetchContext(ctx context.Context, keys []model.UserInputKey, option *model.FetchOption) (*FetchResult, error) {
+	return f.fetch(ctx, keys, option)
+}
+
+/*
+FetchOnceContext is like FetchOnce but takes a context and returns a FetchResult.
+
+FetchOnceContextは、FetchOnceと同様にデータを一度だけ取得し、結果をFetchResultとして返します。
+後続のデータがある場合は、FetchResult.CursorをFetchOnceOption.Cursorに指定して、続きのデータを取得して下さい。
+
+errの発生条件はFetchOnceと同じです。errがnilでない場合、FetchResultはnilです。
+*/
+func (f *FetchClient) FetchOnceContext(ctx context.Context, keys []model.UserInputKey, option *model.FetchOnceOption) (*FetchResult, error) {
+	return f.fetchOnce(ctx, keys, option)
+}
+
+/*
+FetchByIdsWithKeyContext is like FetchByIdsWithKey but takes a context and returns a FetchResult.
+
+FetchByIdsWithKeyContextは、FetchByIdsWithKeyと同様にデータを取得し、結果をFetchResultとして返します。
+
+errの発生条件
+ - idsの長さが0の場合
+ - FetchContextでエラーが発生した場合
+*/
+func (f *FetchClient) FetchByIdsWithKeyContext(ctx context.Context, key model.UserInputKeyNoID, ids ...string) (*FetchResult, error) {
+	logger := f.logger()
+	logger.Debug("FetchByIdsWithKey start", "url", f.ConnectionURL, "ids", ids, "select", key.MinMaxIndicator)
+	if len(ids) == 0 {
+		err := markError(errors.New("ids is empty, set at least one id"), ErrEmptyKeys)
+		logger.Error("FetchByIdsWithKey failed", "url", f.ConnectionURL, "error", err)
+		return nil, err
+	}
+	// Fetchのためのキーを作成
+	var keys []model.UserInputKey
+	for _, id := range ids {
+		keys = append(keys, model.UserInputKey{
+			ID:              id,
+			Eq:              key.Eq,
+			Neq:             key.Neq,
+			Lt:              key.Lt,
+			Gt:              key.Gt,
+			Lteq:            key.Lteq,
+			Gteq:            key.Gteq,
+			Value:           key.Value,
+			MinMaxIndicator: key.MinMaxIndicator,
+		})
+	}
+	// Fetchを実行
+	result, err := f.fetch(ctx, keys, &model.FetchOption{})
+	if err != nil {
+		err = errors.Wrap(err, "Fetch error")
+		logger.Error("FetchByIdsWithKey failed", "url", f.ConnectionURL, "error", err)
+		return nil, err
+	}
+	logger.Debug("FetchByIdsWithKey end", "url", f.ConnectionURL, "value_count", countValues(result.Points))
+	return result, nil
+}
+
+/*
+FetchLatestContext is like FetchLatest but takes a context and returns a FetchResult.
+
+FetchLatestContextは、FetchLatestと同様に指定された日付範囲とIDセット内の最新データを取得し、結果をFetchResultとして返します。
+
+errの発生条件
+ - FetchByIdsWithKeyContextでエラーが発生した場合
+*/
+func (f *FetchClient) FetchLatestContext(ctx context.Context, fromDate *time.Time, untilDate *time.Time, ids ...string) (*FetchResult, error) {
+	return f.fetchSelect(ctx, "FetchLatest", model.SelectTypeMaximum, fromDate, untilDate, ids...)
+}
+
+/*
+FetchOldestContext is like FetchOldest but takes a context and returns a FetchResult.
+
+FetchOldestContextは、FetchOldestと同様に指定された日付範囲とIDセット内の最古のデータを取得し、結果をFetchResultとして返します。
+
+errの発生条件
+ - FetchByIdsWithKeyContextでエラーが発生した場合
+*/
+func (f *FetchClient) FetchOldestContext(ctx context.Context, fromDate *time.Time, untilDate *time.Time, ids ...string) (*FetchResult, error) {
+	return f.fetchSelect(ctx, "FetchOldest", model.SelectTypeMinimum, fromDate, untilDate, ids...)
+}
+
+/*
+FetchDateRangeContext is like FetchDateRange but takes a context and returns a FetchResult.
+
+FetchDateRangeContextは、FetchDateRangeと同様に指定された日付範囲とIDセット内のデータを取得し、結果をFetchResultとして返します。
+
+errの発生条件
+ - FetchByIdsWithKeyContextでエラーが発生した場合
+*/
+func (f *FetchClient) FetchDateRangeContext(ctx context.Context, fromDate *time.Time, untilDate *time.Time, ids ...string) (*FetchResult, error) {
+	return f.fetchSelect(ctx, "FetchDateRange", model.SelectTypeNone, fromDate, untilDate, ids...)
+}
+
+// fetchSelect は日付範囲とselectを指定してFetchByIdsWithKeyContextを実行する。nameはログに出力するメソッド名
+func (f *FetchClient) fetchSelect(ctx context.Context, name string, selectType model.SelectType, fromDate *time.Time, untilDate *time.Time, ids ...string) (*FetchResult, error) {
+	logger := f.logger()
+	logger.Debug(name+" start", "url", f.ConnectionURL, "from", fromDate, "until", untilDate, "ids", ids)
+	result, err := f.FetchByIdsWithKeyContext(ctx, model.UserInputKeyNoID{
+		MinMaxIndicator: selectType,
+		Gteq:            fromDate,
+		Lteq:            untilDate,
+	}, ids...)
+	if err != nil {
+		err = errors.Wrap(err, "FetchByIdsWithKey error")
+		logger.Error(name+" failed", "url", f.ConnectionURL, "error", err)
+		return nil, err
+	}
+	logger.Debug(name+" end", "url", f.ConnectionURL, "value_count", countValues(result.Points))
+	return result, nil
+}
@@ -0,0 +1,253 @@
+package fiap
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+// queryRecorder は送信したクエリを記録するHookを返す
+func queryRecorder(queries *[]model.Query) Hook {
+	return HookFuncs{
+		BeforeSendFunc: func(ctx context.Context, req *SOAPRequest) error {
+			*queries = append(*queries, *req.QueryRQ.Transport.Header.Query)
+			return nil
+		},
+	}
+}
+
+// firstPageResponder はcursorを返す1ページ目のレスポンス
+var firstPageResponder = testutil.CustomTransportResponder(`
+	<transport xmlns="http://gutp.jp/fiap/2009/11/">
+		<header>
+			<OK />
+			<query id="6ffac1fc-1d96-4c37-9a9a-0f1d8f3a3e2b" type="storage" cursor="cursor1" />
+		</header>
+		<body>
+			<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+				<value time="2012-02-02T16:34:05.000+09:00">30</value>
+			</point>
+		</body>
+	</transport>
+	`)
+
+func TestFetchContext(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+
+	t.Run("multiple pages", func(t *testing.T) {
+		var queries []model.Query
+		f := NewFetchClient(connectionURL, WithHooks(queryRecorder(&queries)))
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, firstPageResponder.Then(testutil.CustomBodyResponder(`
+		<body>
+			<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+				<value time="2012-02-02T16:35:05.000+09:00">31</value>
+			</point>
+		</body>
+		`)))
+
+		// テスト対象の関数を実行
+		before := time.Now()
+		result, err := f.FetchContext(context.Background(), []model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, result.FIAPError)
+		assert.Equal(t, "", result.Cursor)
+		assert.Equal(t, 2, result.PageCount)
+		assert.Len(t, result.Points["http://xxxxxxxx/tokyo/building1/Room101/"], 2)
+		require.Len(t, queries, 2)
+		assert.Equal(t, []string{queries[0].Id, queries[1].Id}, result.QueryIDs)
+		assert.NotEqual(t, result.QueryIDs[0], result.QueryIDs[1])
+		assert.False(t, result.StartTime.Before(before))
+		assert.Greater(t, result.Duration, time.Duration(0))
+	})
+
+	t.Run("fiap error on second page", func(t *testing.T) {
+		f := NewFetchClient(connectionURL)
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, firstPageResponder.Then(testutil.CustomHeaderBodyResponder(`
+		<header>
+			<error type="INVALID_CURSOR">cursor is expired</error>
+		</header>
+		`)))
+
+		// テスト対象の関数を実行
+		result, err := f.FetchContext(context.Background(), []model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, &model.Error{Type: "INVALID_CURSOR", Value: "cursor is expired"}, result.FIAPError)
+		assert.Equal(t, 2, result.PageCount)
+		assert.Len(t, result.QueryIDs, 2)
+		assert.Len(t, result.Points["http://xxxxxxxx/tokyo/building1/Room101/"], 1)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		f := NewFetchClient(connectionURL)
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, firstPageResponder)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// テスト対象の関数を実行
+		result, err := f.FetchContext(ctx, []model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, result)
+	})
+
+	t.Run("canceled between pages", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		f := NewFetchClient(connectionURL, WithHooks(HookFuncs{
+			AfterReceiveFunc: func(ctx context.Context, res *SOAPResponse) error {
+				cancel()
+				return nil
+			},
+		}))
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		// cursorを返し続けるため、キャンセルしなければ取得が終わらない
+		httpmock.RegisterResponder("POST", connectionURL, firstPageResponder)
+
+		// テスト対象の関数を実行
+		result, err := f.FetchContext(ctx, []model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Contains(t, err.Error(), "context is done before loop iteration 2")
+		assert.Nil(t, result)
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+}
+
+func TestFetchOnceContext(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	var queries []model.Query
+	f := NewFetchClient(connectionURL, WithHooks(queryRecorder(&queries)))
+
+	// mockの有効化
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", connectionURL, firstPageResponder)
+
+	// テスト対象の関数を実行
+	result, err := f.FetchOnceContext(context.Background(), []model.UserInputKey{
+		{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+	}, &model.FetchOnceOption{AcceptableSize: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, "cursor1", result.Cursor)
+	assert.Equal(t, 1, result.PageCount)
+	require.Len(t, queries, 1)
+	assert.Equal(t, []string{queries[0].Id}, result.QueryIDs)
+	assert.Len(t, result.Points["http://xxxxxxxx/tokyo/building1/Room101/"], 1)
+}
+
+func TestFetchSelectContext(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	fromDate := time.Date(2012, 2, 2, 0, 0, 0, 0, time.UTC)
+	untilDate := time.Date(2012, 2, 3, 0, 0, 0, 0, time.UTC)
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		fetch    func(f *FetchClient) (*FetchResult, error)
+		expected model.SelectType
+	}{
+		{
+			name: "FetchLatestContext",
+			fetch: func(f *FetchClient) (*FetchResult, error) {
+				return f.FetchLatestContext(context.Background(), &fromDate, &untilDate, "http://xxxxxxxx/id1", "http://xxxxxxxx/id2")
+			},
+			expected: model.SelectTypeMaximum,
+		},
+		{
+			name: "FetchOldestContext",
+			fetch: func(f *FetchClient) (*FetchResult, error) {
+				return f.FetchOldestContext(context.Background(), &fromDate, &untilDate, "http://xxxxxxxx/id1", "http://xxxxxxxx/id2")
+			},
+			expected: model.SelectTypeMinimum,
+		},
+		{
+			name: "FetchDateRangeContext",
+			fetch: func(f *FetchClient) (*FetchResult, error) {
+				return f.FetchDateRangeContext(context.Background(), &fromDate, &untilDate, "http://xxxxxxxx/id1", "http://xxxxxxxx/id2")
+			},
+			expected: model.SelectTypeNone,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var queries []model.Query
+			f := NewFetchClient(connectionURL, WithHooks(queryRecorder(&queries)))
+
+			// mockの有効化
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterResponder("POST", connectionURL, testutil.CustomBodyResponder(`
+			<body>
+				<point id="http://xxxxxxxx/id1">
+					<value time="2012-02-02T16:35:05.000+09:00">31</value>
+				</point>
+				<point id="http://xxxxxxxx/id2" />
+			</body>
+			`))
+
+			// テスト対象の関数を実行
+			result, err := tc.fetch(f)
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, result.PageCount)
+			assert.Len(t, result.Points["http://xxxxxxxx/id1"], 1)
+			require.Len(t, queries, 1)
+			require.Len(t, queries[0].Key, 2)
+			for _, key := range queries[0].Key {
+				assert.Equal(t, tc.expected, key.Select)
+				assert.NotEmpty(t, key.Gteq)
+				assert.NotEmpty(t, key.Lteq)
+			}
+		})
+	}
+
+	t.Run("empty ids", func(t *testing.T) {
+		f := NewFetchClient(connectionURL)
+
+		result, err := f.FetchByIdsWithKeyContext(context.Background(), model.UserInputKeyNoID{})
+
+		assert.ErrorIs(t, err, ErrEmptyKeys)
+		assert.Nil(t, result)
+	})
+}
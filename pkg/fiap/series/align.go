@@ -0,0 +1,194 @@
+package series
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/cockroachdb/errors"
+)
+
+/*
+AlignMethod is a type for the method used by Align to compute values on the grid.
+
+AlignMethod は Align で時刻グリッド上の値を求める方法の型です。
+
+この型の値を指定する場合は、AlignMethodStepHoldなどの定数を使用してください。
+*/
+type AlignMethod string
+
+const (
+	// AlignMethodStepHold はグリッドの時刻以前で最も新しい値を保持して使用します。
+	AlignMethodStepHold AlignMethod = "step"
+	// AlignMethodLinear はグリッドの時刻の前後の値を線形補間します。
+	AlignMethodLinear AlignMethod = "linear"
+	// AlignMethodNearest はグリッドの時刻に最も近い値を使用します。前後の距離が等しい場合は前の値を使用します。
+	AlignMethodNearest AlignMethod = "nearest"
+)
+
+/*
+AlignOption is type for Align option.
+
+AlignOptionは、Alignのオプションの型です。
+
+Methodはグリッド上の値を求める方法です。
+
+MaxDistanceは、グリッドの時刻と値を求めるために使用する元データの時刻との距離の最大値です。
+AlignMethodLinearの場合は前後の値の両方がこの距離以内にある必要があります。
+距離を超える場合、グリッド上の値は欠損(NaN)になります。0の場合は距離を制限しません。
+*/
+type AlignOption struct {
+	Method      AlignMethod
+	MaxDistance time.Duration
+}
+
+/*
+Grid returns times from "from" to "until" (inclusive) at intervals of step.
+
+Gridは、fromからuntilまで(untilを含む)、stepの間隔で並んだ時刻のスライスを返します。Alignの時刻グリッドとして使用します。
+*/
+func Grid(from, until time.Time, step time.Duration) ([]time.Time, error) {
+	if step <= 0 {
+		return nil, errors.Newf("step must be positive, step: %s", step)
+	}
+	if until.Before(from) {
+		return nil, errors.Newf("until is before from, from: %s, until: %s", from.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+	grid := make([]time.Time, 0, int(until.Sub(from)/step)+1)
+	for t := from; !t.After(until); t = t.Add(step) {
+		grid = append(grid, t)
+	}
+	return grid, nil
+}
+
+/*
+Align aligns several series onto the common time grid.
+
+Alignは、複数の時系列データを共通の時刻グリッド上に揃えます。
+
+戻り値のresult[i][j]は、series[i]のgrid[j]の時刻における値です。値を求められない場合はNaNになります。
+gridは時刻の昇順に並んでいる必要があります。seriesの各時系列データは時刻順に並んでいる必要はありません。
+同じ時刻の値が複数ある場合は、後ろにある値を使用します。
+
+errの発生条件
+ - optionのMethodが不正な場合
+ - gridが時刻の昇順に並んでいない場合
+ - 数値として解釈できない値が含まれている場合
+*/
+func Align(grid []time.Time, option AlignOption, series ...[]model.Value) ([][]float64, error) {
+	switch option.Method {
+	case AlignMethodStepHold, AlignMethodLinear, AlignMethodNearest:
+	default:
+		return nil, errors.Newf("unknown align method '%s', allows only step, linear, nearest", option.Method)
+	}
+	for i := 1; i < len(grid); i++ {
+		if grid[i].Before(grid[i-1]) {
+			return nil, errors.Newf("grid is not sorted, index: %d", i)
+		}
+	}
+
+	result := make([][]float64, 0, len(series))
+	for i, values := range series {
+		s, err := newSamples(values)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to align series %d", i)
+		}
+		aligned := make([]float64, len(grid))
+		for j, t := range grid {
+			aligned[j] = s.at(t, option)
+		}
+		result = append(result, aligned)
+	}
+	return result, nil
+}
+
+/*
+AlignPoints applies Align to each series of points.
+
+AlignPointsは、pointsの各時系列データにAlignを適用し、IDをキーとしたmapを返します。
+*/
+func AlignPoints(grid []time.Time, option AlignOption, points map[string]([]model.Value)) (map[string]([]float64), error) {
+	result := make(map[string]([]float64), len(points))
+	for id, values := range points {
+		aligned, err := Align(grid, option, values)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to align point '%s'", id)
+		}
+		result[id] = aligned[0]
+	}
+	return result, nil
+}
+
+// samples は時刻順に並べた数値の時系列データ
+type samples struct {
+	times  []time.Time
+	values []float64
+}
+
+func newSamples(values []model.Value) (*samples, error) {
+	sorted := make([]model.Value, len(values))
+	copy(sorted, values)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	s := &samples{
+		times:  make([]time.Time, 0, len(sorted)),
+		values: make([]float64, 0, len(sorted)),
+	}
+	for _, v := range sorted {
+		f, err := strconv.ParseFloat(strings.TrimSpace(v.Value), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "value is not a number, time: %s, value: %s", v.Time.Format(time.RFC3339), v.Value)
+		}
+		// 同じ時刻の値は後ろにある値で上書きする
+		if n := len(s.times); n > 0 && s.times[n-1].Equal(v.Time) {
+			s.values[n-1] = f
+			continue
+		}
+		s.times = append(s.times, v.Time)
+		s.values = append(s.values, f)
+	}
+	return s, nil
+}
+
+// at は時刻tにおける値を求める。求められない場合はNaNを返す
+func (s *samples) at(t time.Time, option AlignOption) float64 {
+	// nextはt以降で最初の値の位置
+	next := sort.Search(len(s.times), func(i int) bool {
+		return !s.times[i].Before(t)
+	})
+	if next < len(s.times) && s.times[next].Equal(t) {
+		return s.values[next]
+	}
+	prev := next - 1
+
+	within := func(d time.Duration) bool {
+		return option.MaxDistance <= 0 || d <= option.MaxDistance
+	}
+	hasPrev := prev >= 0 && within(t.Sub(s.times[prev]))
+	hasNext := next < len(s.times) && within(s.times[next].Sub(t))
+
+	switch option.Method {
+	case AlignMethodStepHold:
+		if hasPrev {
+			return s.values[prev]
+		}
+	case AlignMethodLinear:
+		if hasPrev && hasNext {
+			ratio := float64(t.Sub(s.times[prev])) / float64(s.times[next].Sub(s.times[prev]))
+			return s.values[prev] + (s.values[next]-s.values[prev])*ratio
+		}
+	case AlignMethodNearest:
+		if hasPrev && (!hasNext || t.Sub(s.times[prev]) <= s.times[next].Sub(t)) {
+			return s.values[prev]
+		}
+		if hasNext {
+			return s.values[next]
+		}
+	}
+	return math.NaN()
+}
@@ -0,0 +1,144 @@
+package series
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+func assertFloats(t *testing.T, expected, actual []float64) {
+	t.Helper()
+	if !assert.Len(t, actual, len(expected)) {
+		return
+	}
+	for i := range expected {
+		if math.IsNaN(expected[i]) {
+			assert.True(t, math.IsNaN(actual[i]), "index %d: expected NaN but %v", i, actual[i])
+		} else {
+			assert.InDelta(t, expected[i], actual[i], 1e-9, "index %d", i)
+		}
+	}
+}
+
+func TestGrid(t *testing.T) {
+	grid, err := Grid(minute(0), minute(10), 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{minute(0), minute(5), minute(10)}, grid)
+
+	_, err = Grid(minute(0), minute(10), 0)
+	assert.Error(t, err)
+
+	_, err = Grid(minute(10), minute(0), time.Minute)
+	assert.Error(t, err)
+}
+
+func TestAlignMethods(t *testing.T) {
+	nan := math.NaN()
+	values := []model.Value{
+		{Time: minute(2), Value: "10"},
+		{Time: minute(6), Value: "30"},
+		{Time: minute(20), Value: "100"},
+	}
+	grid := []time.Time{minute(0), minute(2), minute(3), minute(5), minute(10), minute(25)}
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		option   AlignOption
+		expected []float64
+	}{
+		{
+			name:     "step",
+			option:   AlignOption{Method: AlignMethodStepHold},
+			expected: []float64{nan, 10, 10, 10, 30, 100},
+		},
+		{
+			name:     "step with max distance",
+			option:   AlignOption{Method: AlignMethodStepHold, MaxDistance: 3 * time.Minute},
+			expected: []float64{nan, 10, 10, 10, nan, nan},
+		},
+		{
+			name:     "linear",
+			option:   AlignOption{Method: AlignMethodLinear},
+			expected: []float64{nan, 10, 15, 25, 50, nan},
+		},
+		{
+			name:     "linear with max distance",
+			option:   AlignOption{Method: AlignMethodLinear, MaxDistance: 5 * time.Minute},
+			expected: []float64{nan, 10, 15, 25, nan, nan},
+		},
+		{
+			name:     "nearest",
+			option:   AlignOption{Method: AlignMethodNearest},
+			expected: []float64{10, 10, 10, 30, 30, 100},
+		},
+		{
+			name:     "nearest with max distance",
+			option:   AlignOption{Method: AlignMethodNearest, MaxDistance: time.Minute},
+			expected: []float64{nan, 10, 10, 30, nan, nan},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := Align(grid, tc.option, values)
+			assert.NoError(t, err)
+			assert.Len(t, actual, 1)
+			assertFloats(t, tc.expected, actual[0])
+		})
+	}
+}
+
+func TestAlignMultipleSeries(t *testing.T) {
+	heat := []model.Value{
+		{Time: minute(0), Value: "30"},
+		{Time: minute(10), Value: "40"},
+	}
+	power := []model.Value{
+		{Time: minute(3), Value: "10"},
+		{Time: minute(8), Value: "8"},
+		// 同じ時刻の値は後ろの値を使用する
+		{Time: minute(8), Value: "10"},
+	}
+	grid, _ := Grid(minute(5), minute(10), 5*time.Minute)
+
+	actual, err := Align(grid, AlignOption{Method: AlignMethodStepHold}, heat, power)
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, 2)
+	assertFloats(t, []float64{30, 40}, actual[0])
+	assertFloats(t, []float64{10, 10}, actual[1])
+}
+
+func TestAlignPoints(t *testing.T) {
+	points := map[string]([]model.Value){
+		"id1": {{Time: minute(0), Value: "1"}},
+		"id2": {{Time: minute(1), Value: "2"}},
+	}
+
+	actual, err := AlignPoints([]time.Time{minute(1)}, AlignOption{Method: AlignMethodStepHold}, points)
+
+	assert.NoError(t, err)
+	assertFloats(t, []float64{1}, actual["id1"])
+	assertFloats(t, []float64{2}, actual["id2"])
+}
+
+func TestAlignErrors(t *testing.T) {
+	values := []model.Value{{Time: minute(0), Value: "1"}}
+
+	_, err := Align([]time.Time{minute(0)}, AlignOption{Method: "cubic"}, values)
+	assert.Error(t, err)
+
+	_, err = Align([]time.Time{minute(1), minute(0)}, AlignOption{Method: AlignMethodLinear}, values)
+	assert.Error(t, err)
+
+	_, err = Align([]time.Time{minute(0)}, AlignOption{Method: AlignMethodLinear}, []model.Value{{Time: minute(0), Value: "on"}})
+	assert.Error(t, err)
+
+	_, err = AlignPoints([]time.Time{minute(0)}, AlignOption{Method: AlignMethodLinear}, map[string]([]model.Value){"id": {{Time: minute(0), Value: "on"}}})
+	assert.Error(t, err)
+}
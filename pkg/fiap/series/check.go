@@ -0,0 +1,235 @@
+package series
+
+import (
+	"sort"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+/*
+Gap represents a period in which no value was reported.
+
+Gapは、値が報告されなかった期間を表す型です。
+
+Fromは期間直前の値の時刻、Untilは期間直後の値の時刻です。
+*/
+type Gap struct {
+	From     time.Time     `json:"from"`
+	Until    time.Time     `json:"until"`
+	Duration time.Duration `json:"duration"`
+}
+
+/*
+OutOfOrder represents a value whose time is earlier than that of the preceding value.
+
+OutOfOrderは、直前の値よりも時刻が古い値を表す型です。
+
+Indexは時系列データ内での値の位置、Previousは直前の値の時刻です。
+*/
+type OutOfOrder struct {
+	Index    int       `json:"index"`
+	Time     time.Time `json:"time"`
+	Previous time.Time `json:"previous"`
+}
+
+/*
+DetectGaps returns the periods longer than maxInterval in which no value was reported.
+
+DetectGapsは、値が報告されなかった期間のうちmaxIntervalより長いものを返します。
+
+valuesは時刻順に並んでいる必要はありません。maxIntervalが0以下の場合は常に空のスライスを返します。
+*/
+func DetectGaps(values []model.Value, maxInterval time.Duration) []Gap {
+	gaps := make([]Gap, 0)
+	if maxInterval <= 0 {
+		return gaps
+	}
+	times := sortedTimes(values)
+	for i := 1; i < len(times); i++ {
+		if d := times[i].Sub(times[i-1]); d > maxInterval {
+			gaps = append(gaps, Gap{From: times[i-1], Until: times[i], Duration: d})
+		}
+	}
+	return gaps
+}
+
+/*
+DetectDuplicates returns the timestamps which appear more than once in values.
+
+DetectDuplicatesは、valuesの中で2回以上現れる時刻を、古い順に重複なく返します。
+*/
+func DetectDuplicates(values []model.Value) []time.Time {
+	duplicates := make([]time.Time, 0)
+	times := sortedTimes(values)
+	for i := 1; i < len(times); i++ {
+		if !times[i].Equal(times[i-1]) {
+			continue
+		}
+		if len(duplicates) == 0 || !duplicates[len(duplicates)-1].Equal(times[i]) {
+			duplicates = append(duplicates, times[i])
+		}
+	}
+	return duplicates
+}
+
+/*
+DetectOutOfOrder returns the values whose time is earlier than that of the preceding value.
+
+DetectOutOfOrderは、直前の値よりも時刻が古い値を返します。
+*/
+func DetectOutOfOrder(values []model.Value) []OutOfOrder {
+	result := make([]OutOfOrder, 0)
+	for i := 1; i < len(values); i++ {
+		if values[i].Time.Before(values[i-1].Time) {
+			result = append(result, OutOfOrder{Index: i, Time: values[i].Time, Previous: values[i-1].Time})
+		}
+	}
+	return result
+}
+
+/*
+LastSeen returns the latest time in values.
+
+LastSeenは、valuesの中で最も新しい時刻を返します。valuesが空の場合、okはfalseになります。
+*/
+func LastSeen(values []model.Value) (last time.Time, ok bool) {
+	for _, v := range values {
+		if !ok || v.Time.After(last) {
+			last = v.Time
+			ok = true
+		}
+	}
+	return last, ok
+}
+
+/*
+CheckOption is type for Check option.
+
+CheckOptionは、Checkのオプションの型です。
+
+MaxGapは許容する値の間隔の最大値です。0の場合は間隔を検査しません。
+
+MaxAgeは最後の値から現在時刻までの経過時間の許容値です。0の場合は鮮度を検査しません。
+
+Nowは鮮度の検査に使用する現在時刻です。ゼロ値の場合はtime.Now()を使用します。
+*/
+type CheckOption struct {
+	MaxGap time.Duration
+	MaxAge time.Duration
+	Now    time.Time
+}
+
+/*
+PointReport is the result of Check for a point.
+
+PointReportは、1つのポイントに対するCheckの結果です。
+
+LastSeenは最後の値の時刻で、値が1つもない場合はnilです。
+Staleは、MaxAgeが指定されており、最後の値からの経過時間がMaxAgeを超えているか、値が1つもない場合にtrueになります。
+*/
+type PointReport struct {
+	ID         string        `json:"id"`
+	Count      int           `json:"count"`
+	LastSeen   *time.Time    `json:"last_seen,omitempty"`
+	Age        time.Duration `json:"age,omitempty"`
+	Stale      bool          `json:"stale"`
+	Gaps       []Gap         `json:"gaps"`
+	Duplicates []time.Time   `json:"duplicates"`
+	OutOfOrder []OutOfOrder  `json:"out_of_order"`
+}
+
+/*
+OK reports whether the point has no problems.
+
+OKは、ポイントに問題がない場合にtrueを返します。
+*/
+func (r PointReport) OK() bool {
+	return !r.Stale && len(r.Gaps) == 0 && len(r.Duplicates) == 0 && len(r.OutOfOrder) == 0
+}
+
+/*
+Report is the result of Check.
+
+Reportは、Checkの結果です。PointsはIDの昇順に並んでいます。
+*/
+type Report struct {
+	Points []PointReport `json:"points"`
+}
+
+/*
+OK reports whether all points have no problems.
+
+OKは、すべてのポイントに問題がない場合にtrueを返します。
+*/
+func (r Report) OK() bool {
+	for _, p := range r.Points {
+		if !p.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Failed returns the reports of the points which have problems.
+
+Failedは、問題があるポイントのPointReportを返します。
+*/
+func (r Report) Failed() []PointReport {
+	failed := make([]PointReport, 0)
+	for _, p := range r.Points {
+		if !p.OK() {
+			failed = append(failed, p)
+		}
+	}
+	return failed
+}
+
+/*
+Check detects gaps, duplicated timestamps, out-of-order values and staleness for each series of points.
+
+Checkは、pointsの各時系列データについて、値の欠損、時刻の重複、時刻順の乱れ、鮮度を検査します。
+
+pointsはFetcherのメソッドが返すpointsと同じ形式で、IDをキーとした時系列データのmapです。
+*/
+func Check(points map[string]([]model.Value), option CheckOption) Report {
+	now := option.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	report := Report{Points: make([]PointReport, 0, len(points))}
+	for id, values := range points {
+		p := PointReport{
+			ID:         id,
+			Count:      len(values),
+			Gaps:       DetectGaps(values, option.MaxGap),
+			Duplicates: DetectDuplicates(values),
+			OutOfOrder: DetectOutOfOrder(values),
+		}
+		if last, ok := LastSeen(values); ok {
+			p.LastSeen = &last
+			p.Age = now.Sub(last)
+			p.Stale = option.MaxAge > 0 && p.Age > option.MaxAge
+		} else {
+			p.Stale = option.MaxAge > 0
+		}
+		report.Points = append(report.Points, p)
+	}
+	sort.Slice(report.Points, func(i, j int) bool {
+		return report.Points[i].ID < report.Points[j].ID
+	})
+	return report
+}
+
+func sortedTimes(values []model.Value) []time.Time {
+	times := make([]time.Time, 0, len(values))
+	for _, v := range values {
+		times = append(times, v.Time)
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+	return times
+}
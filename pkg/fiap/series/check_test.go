@@ -0,0 +1,100 @@
+package series
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+func minute(m int) time.Time {
+	return time.Date(2024, 1, 1, 0, m, 0, 0, time.UTC)
+}
+
+func TestDetectGaps(t *testing.T) {
+	values := []model.Value{
+		{Time: minute(0)}, {Time: minute(1)}, {Time: minute(10)}, {Time: minute(11)}, {Time: minute(5)},
+	}
+
+	t.Run("with max interval", func(t *testing.T) {
+		assert.Equal(t, []Gap{
+			{From: minute(1), Until: minute(5), Duration: 4 * time.Minute},
+			{From: minute(5), Until: minute(10), Duration: 5 * time.Minute},
+		}, DetectGaps(values, 3*time.Minute))
+	})
+	t.Run("exactly max interval is not a gap", func(t *testing.T) {
+		assert.Equal(t, []Gap{}, DetectGaps(values, 5*time.Minute))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		assert.Equal(t, []Gap{}, DetectGaps(values, 0))
+	})
+}
+
+func TestDetectDuplicates(t *testing.T) {
+	values := []model.Value{
+		{Time: minute(2)}, {Time: minute(1)}, {Time: minute(2)}, {Time: minute(2)}, {Time: minute(3)}, {Time: minute(1)},
+	}
+
+	assert.Equal(t, []time.Time{minute(1), minute(2)}, DetectDuplicates(values))
+	assert.Equal(t, []time.Time{}, DetectDuplicates(values[3:5]))
+}
+
+func TestDetectOutOfOrder(t *testing.T) {
+	values := []model.Value{
+		{Time: minute(1)}, {Time: minute(3)}, {Time: minute(2)}, {Time: minute(2)}, {Time: minute(4)},
+	}
+
+	assert.Equal(t, []OutOfOrder{{Index: 2, Time: minute(2), Previous: minute(3)}}, DetectOutOfOrder(values))
+}
+
+func TestLastSeen(t *testing.T) {
+	last, ok := LastSeen([]model.Value{{Time: minute(3)}, {Time: minute(7)}, {Time: minute(5)}})
+	assert.True(t, ok)
+	assert.Equal(t, minute(7), last)
+
+	_, ok = LastSeen(nil)
+	assert.False(t, ok)
+}
+
+func TestCheck(t *testing.T) {
+	points := map[string]([]model.Value){
+		"ok":    {{Time: minute(0)}, {Time: minute(1)}, {Time: minute(2)}},
+		"gap":   {{Time: minute(0)}, {Time: minute(9)}},
+		"stale": {{Time: minute(0)}, {Time: minute(1)}},
+		"empty": {},
+	}
+	option := CheckOption{MaxGap: 5 * time.Minute, MaxAge: 10 * time.Minute, Now: minute(12)}
+
+	report := Check(points, option)
+
+	assert.False(t, report.OK())
+	assert.Len(t, report.Points, 4)
+	assert.Equal(t, []string{"empty", "gap", "ok", "stale"}, []string{
+		report.Points[0].ID, report.Points[1].ID, report.Points[2].ID, report.Points[3].ID,
+	})
+
+	empty := report.Points[0]
+	assert.True(t, empty.Stale)
+	assert.Nil(t, empty.LastSeen)
+
+	gap := report.Points[1]
+	assert.False(t, gap.Stale)
+	assert.Len(t, gap.Gaps, 1)
+	assert.Equal(t, 3*time.Minute, gap.Age)
+
+	assert.True(t, report.Points[2].OK())
+
+	stale := report.Points[3]
+	assert.True(t, stale.Stale)
+	assert.Equal(t, 11*time.Minute, stale.Age)
+
+	failed := report.Failed()
+	assert.Len(t, failed, 3)
+
+	t.Run("without thresholds", func(t *testing.T) {
+		report := Check(points, CheckOption{Now: minute(12)})
+		assert.True(t, report.OK())
+	})
+}
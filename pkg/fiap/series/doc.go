@@ -0,0 +1,7 @@
+/*
+Package series provides functions for processing time series data fetched from the FIAP server.
+
+パッケージseriesはFIAPサーバから取得した時系列データを加工するための関数を提供します。
+固定長の時間窓への集約(リサンプリング)、値の欠損や鮮度の検査、複数の時系列データの時刻の整列などが含まれています。
+*/
+package series
@@ -0,0 +1,289 @@
+package series
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/cockroachdb/errors"
+)
+
+/*
+Aggregation is a type for the aggregation method used by Resample.
+
+Aggregation は Resample で使用する集約方法の型です。
+
+この型の値を指定する場合は、AggregationMeanなどの定数を使用してください。
+*/
+type Aggregation string
+
+const (
+	// AggregationMean は時間窓内の値の平均値を求めます。
+	AggregationMean Aggregation = "mean"
+	// AggregationMin は時間窓内の値の最小値を求めます。
+	AggregationMin Aggregation = "min"
+	// AggregationMax は時間窓内の値の最大値を求めます。
+	AggregationMax Aggregation = "max"
+	// AggregationSum は時間窓内の値の合計値を求めます。
+	AggregationSum Aggregation = "sum"
+	// AggregationCount は時間窓内の値の個数を求めます。数値以外の値も数えます。
+	AggregationCount Aggregation = "count"
+	// AggregationFirst は時間窓内の最初の値を返します。数値以外の値もそのまま返します。
+	AggregationFirst Aggregation = "first"
+	// AggregationLast は時間窓内の最後の値を返します。数値以外の値もそのまま返します。
+	AggregationLast Aggregation = "last"
+	// AggregationDelta は積算値(電力量メーターなど)の時間窓内の増加量を求めます。
+	AggregationDelta Aggregation = "delta"
+)
+
+var aggregations = []Aggregation{
+	AggregationMean,
+	AggregationMin,
+	AggregationMax,
+	AggregationSum,
+	AggregationCount,
+	AggregationFirst,
+	AggregationLast,
+	AggregationDelta,
+}
+
+const day = 24 * time.Hour
+
+/*
+ParseAggregation converts the given string to an Aggregation.
+
+ParseAggregationは、指定された文字列をAggregationに変換します。
+
+mean, min, max, sum, count, first, last, deltaのいずれでもない場合はエラーを返します。
+*/
+func ParseAggregation(s string) (Aggregation, error) {
+	for _, a := range aggregations {
+		if string(a) == s {
+			return a, nil
+		}
+	}
+	names := make([]string, 0, len(aggregations))
+	for _, a := range aggregations {
+		names = append(names, string(a))
+	}
+	return "", errors.Newf("unknown aggregation '%s', allows only %s", s, strings.Join(names, ", "))
+}
+
+/*
+ParseInterval converts the given string to a time.Duration used as the interval of Resample.
+
+ParseIntervalは、指定された文字列をResampleの時間窓の長さとして使用するtime.Durationに変換します。
+
+time.ParseDurationの書式に加えて、日数を表す"1d"や"7d"のような書式を受け付けます。
+*/
+func ParseInterval(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, errors.Newf("invalid interval '%s'", s)
+		}
+		return time.Duration(n) * day, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid interval '%s'", s)
+	}
+	if d <= 0 {
+		return 0, errors.Newf("interval must be positive, interval: %s", s)
+	}
+	return d, nil
+}
+
+/*
+ResampleOption is type for Resample option.
+
+ResampleOptionは、Resampleのオプションの型です。
+
+Intervalは時間窓の長さです。24時間より長い場合は24時間の倍数である必要があります。
+
+Aggregationは時間窓ごとの集約方法です。
+
+Locationは時間窓の境界を決めるタイムゾーンです。nilの場合はUTCを使用します。
+1日より短い時間窓はLocationにおける毎日0時を起点に区切られ、日単位の時間窓はLocationにおける0時を境界とします。
+*/
+type ResampleOption struct {
+	Interval    time.Duration
+	Aggregation Aggregation
+	Location    *time.Location
+}
+
+func (o ResampleOption) validate() error {
+	if o.Interval <= 0 {
+		return errors.Newf("interval must be positive, interval: %s", o.Interval)
+	}
+	if o.Interval > day && o.Interval%day != 0 {
+		return errors.Newf("interval longer than a day must be a multiple of 24h, interval: %s", o.Interval)
+	}
+	if _, err := ParseAggregation(string(o.Aggregation)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o ResampleOption) location() *time.Location {
+	if o.Location == nil {
+		return time.UTC
+	}
+	return o.Location
+}
+
+/*
+WindowStart returns the start time of the window which contains t.
+
+WindowStartは、tを含む時間窓の開始時刻を返します。
+
+時間窓の区切り方はResampleOptionの説明を参照してください。
+*/
+func WindowStart(t time.Time, interval time.Duration, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	lt := t.In(loc)
+	y, m, d := lt.Date()
+	if interval >= day && interval%day == 0 {
+		// 1970-01-01からの暦日数を時間窓の日数で切り捨てる
+		days := int64(interval / day)
+		n := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / int64(day/time.Second)
+		start := n / days * days
+		if n%days < 0 {
+			start -= days
+		}
+		return time.Date(1970, 1, 1+int(start), 0, 0, 0, 0, loc)
+	}
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	return midnight.Add(lt.Sub(midnight) / interval * interval)
+}
+
+/*
+Resample aggregates values into fixed windows.
+
+Resampleは、valuesを固定長の時間窓ごとに集約します。
+
+戻り値の各Valueは1つの時間窓に対応し、Timeには時間窓の開始時刻、Valueには集約結果が格納されます。
+値が1つも含まれない時間窓は戻り値に含まれません。valuesは時刻順に並んでいる必要はありません。
+
+errの発生条件
+ - optionの内容が不正な場合
+ - 数値を必要とする集約方法で、数値として解釈できない値が含まれている場合
+*/
+func Resample(values []model.Value, option ResampleOption) ([]model.Value, error) {
+	if err := option.validate(); err != nil {
+		return nil, err
+	}
+	loc := option.location()
+
+	sorted := make([]model.Value, len(values))
+	copy(sorted, values)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	result := make([]model.Value, 0)
+	var (
+		w        *window
+		previous *float64
+	)
+	for _, v := range sorted {
+		start := WindowStart(v.Time, option.Interval, loc)
+		if w == nil || !w.start.Equal(start) {
+			if w != nil {
+				result = append(result, w.value(option.Aggregation))
+			}
+			w = &window{start: start, first: v.Value}
+		}
+		w.count++
+		w.last = v.Value
+
+		switch option.Aggregation {
+		case AggregationCount, AggregationFirst, AggregationLast:
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v.Value), 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "value is not a number, time: %s, value: %s", v.Time.Format(time.RFC3339), v.Value)
+		}
+		if option.Aggregation == AggregationDelta {
+			// 積算値の差分を時間窓に加算する。値が減少した場合はメーターのリセットとみなし、リセット後の値を増加量とする
+			if previous != nil {
+				increase := f - *previous
+				if increase < 0 {
+					increase = f
+				}
+				w.sum += increase
+			}
+			previous = &f
+			continue
+		}
+		if w.count == 1 || f < w.min {
+			w.min = f
+		}
+		if w.count == 1 || f > w.max {
+			w.max = f
+		}
+		w.sum += f
+	}
+	if w != nil {
+		result = append(result, w.value(option.Aggregation))
+	}
+	return result, nil
+}
+
+/*
+ResamplePoints applies Resample to each series of points.
+
+ResamplePointsは、pointsの各時系列データにResampleを適用します。
+
+pointsはFetcherのメソッドが返すpointsと同じ形式で、IDをキーとした時系列データのmapです。
+*/
+func ResamplePoints(points map[string]([]model.Value), option ResampleOption) (map[string]([]model.Value), error) {
+	result := make(map[string]([]model.Value), len(points))
+	for id, values := range points {
+		resampled, err := Resample(values, option)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resample point '%s'", id)
+		}
+		result[id] = resampled
+	}
+	return result, nil
+}
+
+// window は1つの時間窓の集計途中の値を保持する
+type window struct {
+	start       time.Time
+	count       int
+	first, last string
+	min, max    float64
+	sum         float64
+}
+
+func (w *window) value(aggregation Aggregation) model.Value {
+	var s string
+	switch aggregation {
+	case AggregationMean:
+		s = formatFloat(w.sum / float64(w.count))
+	case AggregationMin:
+		s = formatFloat(w.min)
+	case AggregationMax:
+		s = formatFloat(w.max)
+	case AggregationSum, AggregationDelta:
+		s = formatFloat(w.sum)
+	case AggregationCount:
+		s = strconv.Itoa(w.count)
+	case AggregationFirst:
+		s = w.first
+	case AggregationLast:
+		s = w.last
+	}
+	return model.Value{Time: w.start, Value: s}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
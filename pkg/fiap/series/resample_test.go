@@ -0,0 +1,228 @@
+package series
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+var tokyoTz = time.FixedZone("Asia/Tokyo", 9*60*60)
+
+func TestResampleAggregations(t *testing.T) {
+	values := []model.Value{
+		{Time: time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC), Value: "10"},
+		{Time: time.Date(2024, 1, 1, 0, 40, 0, 0, time.UTC), Value: "30"},
+		{Time: time.Date(2024, 1, 1, 0, 50, 0, 0, time.UTC), Value: "20"},
+		{Time: time.Date(2024, 1, 1, 2, 5, 0, 0, time.UTC), Value: "5"},
+	}
+	hour0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hour2 := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+
+	// テストケースを定義
+	testCases := []struct {
+		aggregation Aggregation
+		expected    []model.Value
+	}{
+		{aggregation: AggregationMean, expected: []model.Value{{Time: hour0, Value: "20"}, {Time: hour2, Value: "5"}}},
+		{aggregation: AggregationMin, expected: []model.Value{{Time: hour0, Value: "10"}, {Time: hour2, Value: "5"}}},
+		{aggregation: AggregationMax, expected: []model.Value{{Time: hour0, Value: "30"}, {Time: hour2, Value: "5"}}},
+		{aggregation: AggregationSum, expected: []model.Value{{Time: hour0, Value: "60"}, {Time: hour2, Value: "5"}}},
+		{aggregation: AggregationCount, expected: []model.Value{{Time: hour0, Value: "3"}, {Time: hour2, Value: "1"}}},
+		{aggregation: AggregationFirst, expected: []model.Value{{Time: hour0, Value: "10"}, {Time: hour2, Value: "5"}}},
+		{aggregation: AggregationLast, expected: []model.Value{{Time: hour0, Value: "20"}, {Time: hour2, Value: "5"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.aggregation), func(t *testing.T) {
+			actual, err := Resample(values, ResampleOption{Interval: time.Hour, Aggregation: tc.aggregation})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestResampleUnsortedValues(t *testing.T) {
+	values := []model.Value{
+		{Time: time.Date(2024, 1, 1, 1, 30, 0, 0, time.UTC), Value: "3"},
+		{Time: time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC), Value: "1"},
+		{Time: time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), Value: "2"},
+	}
+
+	actual, err := Resample(values, ResampleOption{Interval: time.Hour, Aggregation: AggregationFirst})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Value{
+		{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Value: "1"},
+		{Time: time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), Value: "2"},
+	}, actual)
+}
+
+func TestResampleDelta(t *testing.T) {
+	values := []model.Value{
+		{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Value: "100"},
+		{Time: time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC), Value: "110"},
+		{Time: time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), Value: "125"},
+		{Time: time.Date(2024, 1, 1, 1, 30, 0, 0, time.UTC), Value: "130"},
+		// メーターのリセット
+		{Time: time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC), Value: "4"},
+	}
+
+	actual, err := Resample(values, ResampleOption{Interval: time.Hour, Aggregation: AggregationDelta})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Value{
+		{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Value: "10"},
+		{Time: time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), Value: "20"},
+		{Time: time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC), Value: "4"},
+	}, actual)
+}
+
+func TestResampleDayBoundaryWithLocation(t *testing.T) {
+	values := []model.Value{
+		// 東京時間では2024-01-01 08:00
+		{Time: time.Date(2023, 12, 31, 23, 0, 0, 0, time.UTC), Value: "1"},
+		// 東京時間では2024-01-01 10:00
+		{Time: time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), Value: "2"},
+		// 東京時間では2024-01-02 09:00
+		{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Value: "3"},
+	}
+
+	t.Run("UTC", func(t *testing.T) {
+		actual, err := Resample(values, ResampleOption{Interval: 24 * time.Hour, Aggregation: AggregationSum})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Value{
+			{Time: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), Value: "1"},
+			{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Value: "2"},
+			{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Value: "3"},
+		}, actual)
+	})
+	t.Run("Tokyo", func(t *testing.T) {
+		actual, err := Resample(values, ResampleOption{Interval: 24 * time.Hour, Aggregation: AggregationSum, Location: tokyoTz})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Value{
+			{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, tokyoTz), Value: "3"},
+			{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, tokyoTz), Value: "3"},
+		}, actual)
+	})
+}
+
+func TestResampleEmpty(t *testing.T) {
+	actual, err := Resample(nil, ResampleOption{Interval: time.Hour, Aggregation: AggregationMean})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Value{}, actual)
+}
+
+func TestResampleErrors(t *testing.T) {
+	values := []model.Value{
+		{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Value: "on"},
+	}
+
+	// テストケースを定義
+	testCases := []struct {
+		name   string
+		option ResampleOption
+	}{
+		{name: "zero interval", option: ResampleOption{Interval: 0, Aggregation: AggregationMean}},
+		{name: "interval not multiple of day", option: ResampleOption{Interval: 36 * time.Hour, Aggregation: AggregationMean}},
+		{name: "unknown aggregation", option: ResampleOption{Interval: time.Hour, Aggregation: "median"}},
+		{name: "not a number", option: ResampleOption{Interval: time.Hour, Aggregation: AggregationMean}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := Resample(values, tc.option)
+			assert.Error(t, err)
+			assert.Nil(t, actual)
+		})
+	}
+
+	t.Run("not a number is allowed for count", func(t *testing.T) {
+		actual, err := Resample(values, ResampleOption{Interval: time.Hour, Aggregation: AggregationCount})
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Value{{Time: values[0].Time, Value: "1"}}, actual)
+	})
+}
+
+func TestWindowStart(t *testing.T) {
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		time     time.Time
+		interval time.Duration
+		loc      *time.Location
+		expected time.Time
+	}{
+		{
+			name:     "15 minutes",
+			time:     time.Date(2024, 3, 5, 10, 44, 59, 0, time.UTC),
+			interval: 15 * time.Minute,
+			expected: time.Date(2024, 3, 5, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "hour in other location",
+			time:     time.Date(2024, 3, 5, 10, 44, 59, 0, time.UTC),
+			interval: time.Hour,
+			loc:      tokyoTz,
+			expected: time.Date(2024, 3, 5, 19, 0, 0, 0, tokyoTz),
+		},
+		{
+			name:     "7 hours restart at midnight",
+			time:     time.Date(2024, 3, 5, 23, 0, 0, 0, time.UTC),
+			interval: 7 * time.Hour,
+			expected: time.Date(2024, 3, 5, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "2 days",
+			time:     time.Date(1970, 1, 4, 12, 0, 0, 0, time.UTC),
+			interval: 48 * time.Hour,
+			expected: time.Date(1970, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "2 days before epoch",
+			time:     time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC),
+			interval: 48 * time.Hour,
+			expected: time.Date(1969, 12, 30, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, WindowStart(tc.time, tc.interval, tc.loc))
+		})
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	// テストケースを定義
+	testCases := []struct {
+		input    string
+		expected time.Duration
+		hasError bool
+	}{
+		{input: "15m", expected: 15 * time.Minute},
+		{input: "1h", expected: time.Hour},
+		{input: "1d", expected: 24 * time.Hour},
+		{input: "7d", expected: 7 * 24 * time.Hour},
+		{input: "0d", hasError: true},
+		{input: "-1h", hasError: true},
+		{input: "hour", hasError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := ParseInterval(tc.input)
+			if tc.hasError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, actual)
+			}
+		})
+	}
+}
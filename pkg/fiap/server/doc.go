@@ -0,0 +1,15 @@
+/*
+Package server provides a FIAP storage server.
+
+serverパッケージは、FIAPのストレージサーバを提供します。
+
+Serverはhttp.Handlerを実装し、queryRQ(FETCH手順)とdataRQ(WRITE手順)に応答します。
+pointとpointSetの保存先には、storageパッケージのStorageを使用します。
+
+	store, err := storage.NewFile("fiap.json")
+	if err != nil {
+		return err
+	}
+	http.ListenAndServe(":8080", server.NewServer(store))
+*/
+package server
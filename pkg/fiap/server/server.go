@@ -0,0 +1,427 @@
+package server
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/globusdigital/soap"
+	"github.com/google/uuid"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/storage"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
+)
+
+const (
+	// DefaultMaxAcceptableSize はServerが一度に返すvalueの数の上限の初期値
+	DefaultMaxAcceptableSize uint = 1000
+	// DefaultCursorTTL はcursorの有効期間の初期値
+	DefaultCursorTTL = 10 * time.Minute
+	// DefaultMaxRequestSize はServerが受け付けるリクエストのbodyの大きさ(バイト数)の上限の初期値
+	DefaultMaxRequestSize int64 = 10 << 20
+	// DefaultMaxCursors はServerが同時に保持するcursorの数の上限の初期値
+	DefaultMaxCursors = 1000
+)
+
+// FIAPのerrorのtype
+const (
+	errorPointNotFound     = "POINT_NOT_FOUND"
+	errorInvalidRequest    = "INVALID_REQUEST"
+	errorQueryNotSupported = "QUERY_NOT_SUPPORTED"
+	errorInvalidCursor     = "INVALID_CURSOR"
+	errorServerError       = "SERVER_ERROR"
+)
+
+/*
+Server is a FIAP storage server that answers queryRQ and dataRQ.
+
+Serverは、queryRQとdataRQに応答するFIAPのストレージサーバです。NewServerで作成してください。
+
+MaxAcceptableSizeは、一度に返すvalueの数の上限です。クエリのacceptableSizeが0の場合や、この値より大きい場合はこの値を使用します。
+0の場合は上限を設けません。
+CursorTTLは、続きのデータを取得するためのcursorの有効期間です。
+MaxRequestSizeは、受け付けるリクエストのbodyの大きさ(バイト数)の上限です。超えた場合は413とSOAP Faultを返します。0の場合は上限を設けません。
+MaxCursorsは、同時に保持するcursorの数の上限です。超えた場合は有効期限が最も近いcursorから破棄します。0の場合は上限を設けません。
+QueryHookは、queryRQを受信するたびにクエリを評価する前に呼び出されます。nilでないerrorを返した場合は、クエリを評価せずにそのerrorを返します。
+*/
+type Server struct {
+	Storage           storage.Storage
+	MaxAcceptableSize uint
+	CursorTTL         time.Duration
+	MaxRequestSize    int64
+	MaxCursors        int
+	Logger            *slog.Logger
+	QueryHook         QueryHook
+
+	mu      sync.Mutex
+	cursors map[string]*cursor
+}
+
+// cursor は続きのデータを取得するために保持するpoint
+type cursor struct {
+	points  []*model.Point
+	expires time.Time
+}
+
+/*
+QueryHook is called with every received query before it is evaluated.
+
+QueryHookは、受信したクエリを評価する前に呼び出される関数です。
+クエリの記録や、認証、流量制限などに使用します。nilでない*model.Errorを返した場合、ServerはそのerrorをFIAPのerrorとして返します。
+*/
+type QueryHook func(ctx context.Context, query *model.Query) *model.Error
+
+/*
+Option is a functional option for NewServer.
+
+Optionは、NewServerに渡す設定です。
+*/
+type Option func(*Server)
+
+/*
+NewServer returns a Server that uses the storage.
+
+NewServerは、storageを保存先とするServerを返します。設定は引数の順に適用されます。
+*/
+func NewServer(store storage.Storage, opts ...Option) *Server {
+	s := &Server{
+		Storage:           store,
+		MaxAcceptableSize: DefaultMaxAcceptableSize,
+		CursorTTL:         DefaultCursorTTL,
+		MaxRequestSize:    DefaultMaxRequestSize,
+		MaxCursors:        DefaultMaxCursors,
+		cursors:           make(map[string]*cursor),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+/*
+WithMaxAcceptableSize sets the upper limit of values the server returns at once.
+
+WithMaxAcceptableSizeは、サーバが一度に返すvalueの数の上限を設定します。
+*/
+func WithMaxAcceptableSize(size uint) Option {
+	return func(s *Server) {
+		s.MaxAcceptableSize = size
+	}
+}
+
+/*
+WithCursorTTL sets how long a cursor stays valid.
+
+WithCursorTTLは、cursorの有効期間を設定します。
+*/
+func WithCursorTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.CursorTTL = ttl
+	}
+}
+
+/*
+WithMaxRequestSize sets the upper limit of the request body size in bytes.
+
+WithMaxRequestSizeは、受け付けるリクエストのbodyの大きさ(バイト数)の上限を設定します。0の場合は上限を設けません。
+*/
+func WithMaxRequestSize(size int64) Option {
+	return func(s *Server) {
+		s.MaxRequestSize = size
+	}
+}
+
+/*
+WithMaxCursors sets the upper limit of cursors the server keeps at once.
+
+WithMaxCursorsは、同時に保持するcursorの数の上限を設定します。0の場合は上限を設けません。
+*/
+func WithMaxCursors(n int) Option {
+	return func(s *Server) {
+		s.MaxCursors = n
+	}
+}
+
+/*
+WithLogger sets the logger of the server.
+
+WithLoggerは、サーバがログの出力に使用する*slog.Loggerを設定します。
+*/
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.Logger = logger
+	}
+}
+
+/*
+WithQueryHook sets the hook called with every received query.
+
+WithQueryHookは、受信したクエリを評価する前に呼び出すQueryHookを設定します。
+*/
+func WithQueryHook(hook QueryHook) Option {
+	return func(s *Server) {
+		s.QueryHook = hook
+	}
+}
+
+// logger はLoggerが設定されていない場合、toolsパッケージのロガーを返す
+func (s *Server) logger() *slog.Logger {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return tools.Logger()
+}
+
+// request は受信したqueryRQまたはdataRQ。pointSetの子孫のvalueを失わないよう、model.OriginalPointSetで受け取る
+type request struct {
+	XMLName xml.Name
+
+	Transport *transport `xml:"transport"`
+}
+
+/*
+ServeHTTP implements http.Handler.
+
+ServeHTTPは、http.Handlerインターフェースの実装です。
+POSTで受信したSOAPのリクエストを解釈し、queryRQにはqueryRS、dataRQにはdataRSを返します。
+リクエストがSOAPとして解釈できない場合は、SOAP Faultを返します。
+*/
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger().With("remote_addr", r.RemoteAddr)
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.MaxRequestSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.MaxRequestSize)
+	}
+	req := &request{}
+	if err := xml.NewDecoder(r.Body).Decode(&soap.Envelope{Body: soap.Body{Content: req}}); err != nil {
+		logger.Warn("failed to decode request", "error", err)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeEnvelope(w, http.StatusRequestEntityTooLarge, &fault{Code: "soapenv:Client", String: fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)})
+			return
+		}
+		writeFault(w, "soapenv:Client", fmt.Sprintf("failed to decode request: %v", err))
+		return
+	}
+	if req.Transport == nil {
+		writeFault(w, "soapenv:Client", "transport is not found in the request")
+		return
+	}
+
+	switch req.XMLName {
+	case xml.Name{Space: "http://soap.fiap.org/", Local: "queryRQ"}:
+		if req.Transport.Header == nil || req.Transport.Header.Query == nil {
+			writeFault(w, "soapenv:Client", "query is not found in the request")
+			return
+		}
+		res := s.query(r.Context(), req.Transport.Header.Query)
+		logger.Debug("query", "query_id", req.Transport.Header.Query.Id, "error", res.Header.Error)
+		writeEnvelope(w, http.StatusOK, &queryRS{Transport: res})
+	case xml.Name{Space: "http://soap.fiap.org/", Local: "dataRQ"}:
+		res := s.data(r.Context(), req.Transport)
+		logger.Debug("data", "error", res.Header.Error)
+		writeEnvelope(w, http.StatusOK, &dataRS{Transport: res})
+	default:
+		writeFault(w, "soapenv:Client", fmt.Sprintf("unsupported operation %s", req.XMLName.Local))
+	}
+}
+
+// query はqueryRQのクエリを評価し、レスポンスのtransportを作成する
+func (s *Server) query(ctx context.Context, query *model.Query) *transport {
+	if s.QueryHook != nil {
+		if fiapErr := s.QueryHook(ctx, query); fiapErr != nil {
+			return fiapError(query, fiapErr.Type, fiapErr.Value)
+		}
+	}
+	if query.Type != "" && query.Type != "storage" {
+		return fiapError(query, errorQueryNotSupported, fmt.Sprintf("query type %q is not supported", query.Type))
+	}
+
+	var (
+		pointSets []*model.OriginalPointSet
+		points    []*model.Point
+	)
+	if query.Cursor != "" {
+		var ok bool
+		if points, ok = s.takeCursor(query.Cursor); !ok {
+			return fiapError(query, errorInvalidCursor, fmt.Sprintf("cursor %q is not found or expired", query.Cursor))
+		}
+	} else {
+		for _, key := range query.Key {
+			pointSet, point, errorType, err := s.lookup(ctx, key)
+			if err != nil {
+				return fiapError(query, errorType, err.Error())
+			}
+			if pointSet != nil {
+				pointSets = append(pointSets, pointSet)
+			} else {
+				points = append(points, point)
+			}
+		}
+	}
+
+	size := s.MaxAcceptableSize
+	if query.AcceptableSize != 0 && (size == 0 || query.AcceptableSize < size) {
+		size = query.AcceptableSize
+	}
+	page, rest := paginate(points, size)
+
+	resQuery := *query
+	resQuery.Cursor = ""
+	if len(rest) > 0 {
+		resQuery.Cursor = s.putCursor(rest)
+	}
+	return &transport{
+		Header: &model.Header{OK: &model.OK{}, Query: &resQuery},
+		Body:   &body{PointSet: pointSets, Point: page},
+	}
+}
+
+// lookup はkeyのIDに対応するpointSetまたはpointを取得する。失敗した場合は、FIAPのerrorのtypeとエラーを返す
+func (s *Server) lookup(ctx context.Context, key model.Key) (*model.OriginalPointSet, *model.Point, string, error) {
+	pointSetIDs, pointIDs, err := s.Storage.PointSet(ctx, key.Id)
+	if err == nil {
+		pointSet := &model.OriginalPointSet{Id: key.Id}
+		for _, id := range pointSetIDs {
+			pointSet.PointSet = append(pointSet.PointSet, &model.OriginalPointSet{Id: id})
+		}
+		for _, id := range pointIDs {
+			pointSet.Point = append(pointSet.Point, &model.Point{Id: id})
+		}
+		return pointSet, nil, "", nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, errorServerError, errors.Wrapf(err, "failed to get pointSet %q", key.Id)
+	}
+
+	filter, err := storage.NewFilter(key)
+	if err != nil {
+		return nil, nil, errorInvalidRequest, err
+	}
+	values, err := s.Storage.Values(ctx, key.Id, filter)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, errorPointNotFound, errors.Newf("point %q is not found", key.Id)
+	}
+	if err != nil {
+		return nil, nil, errorServerError, errors.Wrapf(err, "failed to get point %q", key.Id)
+	}
+	return nil, &model.Point{Id: key.Id, Value: values}, "", nil
+}
+
+// data はdataRQのbodyを保存し、レスポンスのtransportを作成する
+func (s *Server) data(ctx context.Context, req *transport) *transport {
+	if req.Body == nil {
+		return &transport{Header: &model.Header{OK: &model.OK{}}}
+	}
+	if err := validateWrite("", req.Body.PointSet, req.Body.Point); err != nil {
+		return &transport{Header: &model.Header{Error: &model.Error{Type: errorInvalidRequest, Value: err.Error()}}}
+	}
+	if err := s.Storage.Write(ctx, req.Body.PointSet, req.Body.Point); err != nil {
+		s.logger().Error("failed to write", "error", err)
+		return &transport{Header: &model.Header{Error: &model.Error{Type: errorServerError, Value: err.Error()}}}
+	}
+	return &transport{Header: &model.Header{OK: &model.OK{}}}
+}
+
+// validateWrite は書き込むpointSetとpointのIDが空でなく、valueに時刻があることを検証する
+func validateWrite(id string, pointSets []*model.OriginalPointSet, points []*model.Point) error {
+	for _, pointSet := range pointSets {
+		if pointSet.Id == "" {
+			return errors.Newf("pointSet id is empty in pointSet %q", id)
+		}
+		if err := validateWrite(pointSet.Id, pointSet.PointSet, pointSet.Point); err != nil {
+			return err
+		}
+	}
+	for _, point := range points {
+		if point.Id == "" {
+			return errors.Newf("point id is empty in pointSet %q", id)
+		}
+		for _, v := range point.Value {
+			if v.Time.IsZero() {
+				return errors.Newf("value of point %q has no time", point.Id)
+			}
+		}
+	}
+	return nil
+}
+
+// putCursor はpointsを保持し、取得するためのcursorを返す。MaxCursorsを超える場合は、有効期限が最も近いcursorを破棄する
+func (s *Server) putCursor(points []*model.Point) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.pruneCursors(now)
+	for s.MaxCursors > 0 && len(s.cursors) >= s.MaxCursors {
+		oldest := ""
+		for id, c := range s.cursors {
+			if oldest == "" || c.expires.Before(s.cursors[oldest].expires) {
+				oldest = id
+			}
+		}
+		delete(s.cursors, oldest)
+	}
+	id := uuid.NewString()
+	s.cursors[id] = &cursor{points: points, expires: now.Add(s.CursorTTL)}
+	return id
+}
+
+// takeCursor はcursorに対応するpointsを取り出す。cursorは1度だけ使用できる
+func (s *Server) takeCursor(id string) ([]*model.Point, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneCursors(time.Now())
+	c, ok := s.cursors[id]
+	if !ok {
+		return nil, false
+	}
+	delete(s.cursors, id)
+	return c.points, true
+}
+
+// pruneCursors は期限切れのcursorを削除する。s.muを取得した状態で呼び出す
+func (s *Server) pruneCursors(now time.Time) {
+	for id, c := range s.cursors {
+		if now.After(c.expires) {
+			delete(s.cursors, id)
+		}
+	}
+}
+
+// paginate はpointsのvalueを先頭からsize個まで含むpageと、残りのrestに分ける。valueを持たないpointはpageに含める。sizeが0の場合は分けない
+func paginate(points []*model.Point, size uint) (page []*model.Point, rest []*model.Point) {
+	if size == 0 {
+		return points, nil
+	}
+	remaining := int(size)
+	for i, point := range points {
+		if remaining == 0 && len(point.Value) > 0 {
+			return page, points[i:]
+		}
+		if len(point.Value) <= remaining {
+			page = append(page, point)
+			remaining -= len(point.Value)
+			continue
+		}
+		page = append(page, &model.Point{Id: point.Id, Value: point.Value[:remaining]})
+		rest = append(rest, &model.Point{Id: point.Id, Value: point.Value[remaining:]})
+		return page, append(rest, points[i+1:]...)
+	}
+	return page, nil
+}
+
+// fiapError はheaderにerrorを含むtransportを作成する
+func fiapError(query *model.Query, errorType string, message string) *transport {
+	return &transport{Header: &model.Header{Error: &model.Error{Type: errorType, Value: message}, Query: query}}
+}
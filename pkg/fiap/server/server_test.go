@@ -0,0 +1,244 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/storage"
+)
+
+const (
+	building1 = "http://xxxxxxxx/tokyo/building1/"
+	room101   = "http://xxxxxxxx/tokyo/building1/Room101/"
+)
+
+// dataRQ はRoom101に2つのvalueを書き込むリクエスト
+const dataRQ = `<?xml version="1.0" encoding="utf-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/">
+	<soapenv:Body>
+		<ns2:dataRQ xmlns:ns2="http://soap.fiap.org/">
+			<transport xmlns="http://gutp.jp/fiap/2009/11/">
+				<body>
+					<pointSet id="http://xxxxxxxx/tokyo/building1/">
+						<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+							<value time="2012-02-02T16:34:00+09:00">30</value>
+							<value time="2012-02-02T16:35:00+09:00">31</value>
+						</point>
+					</pointSet>
+				</body>
+			</transport>
+		</ns2:dataRQ>
+	</soapenv:Body>
+</soapenv:Envelope>`
+
+// post はbodyをPOSTし、ステータスコードとレスポンスのbodyを返す
+func post(t *testing.T, url string, body string) (int, string) {
+	t.Helper()
+	res, err := http.Post(url, "text/xml", strings.NewReader(body))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(data)
+}
+
+func TestServerWriteAndQuery(t *testing.T) {
+	ts := httptest.NewServer(NewServer(storage.NewMemory()))
+	defer ts.Close()
+
+	// テスト対象の関数を実行
+	status, body := post(t, ts.URL, dataRQ)
+
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "dataRS")
+	assert.Contains(t, body, "<OK>")
+
+	f := fiap.NewFetchClient(ts.URL)
+	t.Run("point", func(t *testing.T) {
+		_, points, fiapErr, err := f.FetchLatest(nil, nil, room101)
+
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		require.Len(t, points[room101], 1)
+		assert.Equal(t, "31", points[room101][0].Value)
+	})
+
+	t.Run("pointSet", func(t *testing.T) {
+		pointSets, _, fiapErr, err := f.Fetch([]model.UserInputKey{{ID: building1}}, nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		assert.Equal(t, []string{room101}, pointSets[building1].PointID)
+	})
+
+	t.Run("point not found", func(t *testing.T) {
+		_, _, fiapErr, err := f.FetchLatest(nil, nil, "http://xxxxxxxx/tokyo/building1/Room999/")
+
+		require.NoError(t, err)
+		require.NotNil(t, fiapErr)
+		assert.Equal(t, "POINT_NOT_FOUND", fiapErr.Type)
+	})
+}
+
+func TestServerPagination(t *testing.T) {
+	store := storage.NewMemory()
+	base := time.Date(2012, 2, 2, 0, 0, 0, 0, time.UTC)
+	values := make([]model.Value, 0, 10)
+	for i := 0; i < 10; i++ {
+		values = append(values, model.Value{Time: base.Add(time.Duration(i) * time.Minute), Value: "v"})
+	}
+	require.NoError(t, store.Write(context.Background(), nil, []*model.Point{{Id: room101, Value: values}}))
+	s := NewServer(store, WithMaxAcceptableSize(4))
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	f := fiap.NewFetchClient(ts.URL)
+	keys := []model.UserInputKey{{ID: room101}}
+
+	t.Run("MaxAcceptableSize", func(t *testing.T) {
+		// テスト対象の関数を実行
+		_, points, cursor, fiapErr, err := f.FetchOnce(keys, &model.FetchOnceOption{AcceptableSize: 100})
+
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		assert.NotEmpty(t, cursor)
+		assert.Len(t, points[room101], 4)
+	})
+
+	t.Run("Fetch follows cursors", func(t *testing.T) {
+		// テスト対象の関数を実行
+		result, err := f.FetchContext(context.Background(), keys, &model.FetchOption{AcceptableSize: 3})
+
+		require.NoError(t, err)
+		assert.Nil(t, result.FIAPError)
+		assert.Equal(t, 4, result.PageCount)
+		assert.Len(t, result.Points[room101], 10)
+	})
+
+	t.Run("expired cursor", func(t *testing.T) {
+		s.CursorTTL = -time.Second
+		defer func() { s.CursorTTL = DefaultCursorTTL }()
+		_, _, cursor, _, err := f.FetchOnce(keys, nil)
+		require.NoError(t, err)
+		_, _, idle, _, err := f.FetchOnce(keys, nil)
+		require.NoError(t, err)
+
+		// テスト対象の関数を実行
+		_, _, _, fiapErr, err := f.FetchOnce(keys, &model.FetchOnceOption{Cursor: cursor})
+
+		require.NoError(t, err)
+		require.NotNil(t, fiapErr)
+		assert.Equal(t, "INVALID_CURSOR", fiapErr.Type)
+		// 使用されていない期限切れのcursorも、取得の際に削除する
+		assert.NotContains(t, s.cursors, idle)
+	})
+
+	t.Run("MaxCursors", func(t *testing.T) {
+		s.MaxCursors = 2
+		defer func() { s.MaxCursors = DefaultMaxCursors }()
+		cursors := make([]string, 0, 3)
+		for i := 0; i < 3; i++ {
+			// テスト対象の関数を実行
+			_, _, cursor, _, err := f.FetchOnce(keys, nil)
+			require.NoError(t, err)
+			cursors = append(cursors, cursor)
+		}
+
+		// 上限を超えた場合は有効期限が最も近いcursorから破棄する
+		assert.Len(t, s.cursors, 2)
+		_, _, _, fiapErr, err := f.FetchOnce(keys, &model.FetchOnceOption{Cursor: cursors[0]})
+		require.NoError(t, err)
+		require.NotNil(t, fiapErr)
+		assert.Equal(t, "INVALID_CURSOR", fiapErr.Type)
+		_, points, _, fiapErr, err := f.FetchOnce(keys, &model.FetchOnceOption{Cursor: cursors[2]})
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		assert.Len(t, points[room101], 4)
+	})
+}
+
+func TestServerInvalidRequest(t *testing.T) {
+	ts := httptest.NewServer(NewServer(storage.NewMemory()))
+	defer ts.Close()
+
+	t.Run("GET", func(t *testing.T) {
+		res, err := http.Get(ts.URL)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+	})
+
+	t.Run("not SOAP", func(t *testing.T) {
+		status, body := post(t, ts.URL, "hello")
+
+		assert.Equal(t, http.StatusInternalServerError, status)
+		assert.Contains(t, body, "soapenv:Fault")
+		assert.Contains(t, body, "failed to decode request")
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		small := httptest.NewServer(NewServer(storage.NewMemory(), WithMaxRequestSize(100)))
+		defer small.Close()
+
+		status, body := post(t, small.URL, dataRQ)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+		assert.Contains(t, body, "soapenv:Fault")
+		assert.Contains(t, body, "request body exceeds 100 bytes")
+	})
+
+	t.Run("point without id", func(t *testing.T) {
+		status, body := post(t, ts.URL, strings.Replace(dataRQ, `<point id="http://xxxxxxxx/tokyo/building1/Room101/">`, "<point>", 1))
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, `type="INVALID_REQUEST"`)
+	})
+}
+
+func TestServerQueryHook(t *testing.T) {
+	store := storage.NewMemory()
+	require.NoError(t, store.Write(context.Background(), nil, []*model.Point{{Id: room101, Value: []model.Value{{Time: time.Date(2012, 2, 2, 0, 0, 0, 0, time.UTC), Value: "30"}}}}))
+	var (
+		received []string
+		reject   *model.Error
+	)
+	ts := httptest.NewServer(NewServer(store, WithQueryHook(func(ctx context.Context, query *model.Query) *model.Error {
+		for _, key := range query.Key {
+			received = append(received, key.Id)
+		}
+		return reject
+	})))
+	defer ts.Close()
+	f := fiap.NewFetchClient(ts.URL)
+
+	t.Run("pass", func(t *testing.T) {
+		// テスト対象の関数を実行
+		_, points, fiapErr, err := f.FetchLatest(nil, nil, room101)
+
+		require.NoError(t, err)
+		assert.Nil(t, fiapErr)
+		assert.Len(t, points[room101], 1)
+		assert.Equal(t, []string{room101}, received)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		reject = &model.Error{Type: "FORBIDDEN", Value: "access denied"}
+
+		// テスト対象の関数を実行
+		_, points, fiapErr, err := f.FetchLatest(nil, nil, room101)
+
+		require.NoError(t, err)
+		assert.Equal(t, reject, fiapErr)
+		assert.Empty(t, points)
+	})
+}
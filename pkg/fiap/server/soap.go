@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/globusdigital/soap"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+// writeFault はSOAP Faultをステータスコード500で返す
+func writeFault(w http.ResponseWriter, code string, message string) {
+	writeEnvelope(w, http.StatusInternalServerError, &fault{Code: code, String: message})
+}
+
+// writeEnvelope はcontentをSOAPのEnvelopeで包んで返す
+func writeEnvelope(w http.ResponseWriter, statusCode int, content any) {
+	data, err := xml.Marshal(&envelope{Namespace: soap.NamespaceSoap11, Body: envelopeBody{Content: content}})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", soap.SoapContentType11)
+	w.WriteHeader(statusCode)
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
+
+// envelope はレスポンスのEnvelope。soapパッケージのクライアントはsoapで始まる接頭辞を要求するため、soapenvを付けて出力する
+type envelope struct {
+	XMLName xml.Name `xml:"soapenv:Envelope"`
+
+	Namespace string `xml:"xmlns:soapenv,attr"`
+
+	Header struct{} `xml:"soapenv:Header"`
+
+	Body envelopeBody `xml:"soapenv:Body"`
+}
+
+// envelopeBody はレスポンスのBody
+type envelopeBody struct {
+	Content any
+}
+
+// fault はレスポンスのSOAP Fault
+type fault struct {
+	XMLName xml.Name `xml:"soapenv:Fault"`
+
+	Code string `xml:"faultcode"`
+
+	String string `xml:"faultstring"`
+}
+
+// queryRS はqueryRQに対するレスポンス
+type queryRS struct {
+	XMLName xml.Name `xml:"http://soap.fiap.org/ queryRS"`
+
+	Transport *transport `xml:"transport"`
+}
+
+// dataRS はdataRQに対するレスポンス
+type dataRS struct {
+	XMLName xml.Name `xml:"http://soap.fiap.org/ dataRS"`
+
+	Transport *transport `xml:"transport"`
+}
+
+// transport はリクエストとレスポンスのtransport。model.PointSetは子孫のvalueを保持できないため、model.OriginalPointSetを使用する
+type transport struct {
+	XMLName xml.Name `xml:"http://gutp.jp/fiap/2009/11/ transport"`
+
+	Header *model.Header `xml:"header,omitempty"`
+
+	Body *body `xml:"body,omitempty"`
+}
+
+// body はリクエストとレスポンスのbody
+type body struct {
+	PointSet []*model.OriginalPointSet `xml:"pointSet,omitempty"`
+
+	Point []*model.Point `xml:"point,omitempty"`
+}
@@ -0,0 +1,9 @@
+/*
+Package storage provides the storage of points and pointSets for the FIAP storage server.
+
+storageパッケージは、FIAPストレージサーバがpointとpointSetを保存するためのストレージを提供します。
+
+Storageインターフェースを実装することで、任意の保存先を使用できます。
+このパッケージは、メモリ上に保存するMemoryと、JSONファイルに保存するFileを提供します。
+*/
+package storage
@@ -0,0 +1,319 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+/*
+DefaultCompactionSize is the default log size that triggers compaction of a File.
+
+DefaultCompactionSizeは、Fileのログを圧縮する大きさ(バイト数)の初期値です。
+*/
+const DefaultCompactionSize int64 = 4 << 20
+
+/*
+File is a Storage that keeps points and pointSets in memory and saves them to a JSON file.
+
+Fileは、pointとpointSetをメモリ上に保持し、JSONファイルへ保存するStorageです。NewFileで作成し、使用後はCloseを呼び出してください。
+
+書き込みは、pathに".log"を付けたログファイルに1行のJSONとして追記し、fsyncしてから返ります。
+そのため、1回の書き込みのコストは書き込んだデータの大きさに比例し、保存済みのデータの大きさには依存しません。
+ログがCompactionSizeとスナップショットの大きさのうち大きい方を超えると、すべての内容をpathのスナップショットに書き出し、ログを空にします(圧縮)。
+スナップショットは一時ファイルに書き込んでfsyncしてから置き換えるため、保存の途中でプロセスやOSが終了しても壊れません。
+読み込み時はスナップショットにログを順に適用します。同じ書き込みを繰り返し適用しても結果は変わらないため、圧縮の途中で終了しても内容は失われません。
+
+ファイルへの保存に失敗した場合、Writeはエラーを返しますが、メモリ上の内容には書き込みが反映されています。
+*/
+type File struct {
+	CompactionSize int64
+
+	mu           sync.Mutex
+	path         string
+	memory       *Memory
+	log          *os.File
+	logSize      int64
+	snapshotSize int64
+}
+
+// fileData はFileがスナップショットとして保存するJSONの形式
+type fileData struct {
+	PointSets map[string]*model.ProcessedPointSet `json:"point_sets"`
+	Points    map[string][]model.Value            `json:"points"`
+}
+
+// logRecord はFileがログに追記する1回の書き込み
+type logRecord struct {
+	PointSets []*model.OriginalPointSet `json:"point_sets,omitempty"`
+	Points    []*model.Point            `json:"points,omitempty"`
+}
+
+/*
+NewFile returns a File that saves to the path, loading its content if the file exists.
+
+NewFileは、pathに保存するFileを返します。スナップショットとログが存在する場合は、その内容を読み込みます。
+ログの最後の行が書き込みの途中で終了していた場合は、その行を無視します。
+
+errの発生条件
+ - ファイルを読み込めない場合
+ - ファイルの内容がJSONとして解釈できない場合
+*/
+func NewFile(path string) (*File, error) {
+	f := &File{CompactionSize: DefaultCompactionSize, path: path, memory: NewMemory()}
+	if err := f.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := f.loadLog(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// loadSnapshot はスナップショットを読み込む。ファイルが存在しない場合は何もしない
+func (f *File) loadSnapshot() error {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", f.path)
+	}
+	var content fileData
+	if err := json.Unmarshal(data, &content); err != nil {
+		return errors.Wrapf(err, "failed to parse %s", f.path)
+	}
+	for id, pointSet := range content.PointSets {
+		if pointSet == nil {
+			pointSet = &model.ProcessedPointSet{}
+		}
+		if pointSet.PointSetID == nil {
+			pointSet.PointSetID = []string{}
+		}
+		if pointSet.PointID == nil {
+			pointSet.PointID = []string{}
+		}
+		f.memory.pointSets[id] = pointSet
+	}
+	for id, values := range content.Points {
+		f.memory.writePoint(&model.Point{Id: id, Value: values})
+	}
+	f.snapshotSize = int64(len(data))
+	return nil
+}
+
+// loadLog はログの書き込みをメモリに適用する。改行で終わっていない最後の行は、書き込みの途中で終了したものとして無視する
+func (f *File) loadLog() error {
+	path := f.logPath()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", path)
+	}
+	complete := data[:bytes.LastIndexByte(data, '\n')+1]
+	scanner := bufio.NewScanner(bytes.NewReader(complete))
+	scanner.Buffer(nil, len(complete)+1)
+	for line := 1; scanner.Scan(); line++ {
+		var record logRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return errors.Wrapf(err, "failed to parse %s, line: %d", path, line)
+		}
+		if err := f.memory.Write(context.Background(), record.PointSets, record.Points); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "failed to read %s", path)
+	}
+	f.logSize = int64(len(complete))
+	if len(complete) < len(data) {
+		// 途中で終了した行を取り除き、続けて追記できるようにする
+		if err := os.Truncate(path, f.logSize); err != nil {
+			return errors.Wrapf(err, "failed to truncate %s", path)
+		}
+	}
+	return nil
+}
+
+/*
+PointSet implements Storage.
+
+PointSetは、Storageインターフェースの実装です。
+*/
+func (f *File) PointSet(ctx context.Context, id string) ([]string, []string, error) {
+	return f.memory.PointSet(ctx, id)
+}
+
+/*
+Values implements Storage.
+
+Valuesは、Storageインターフェースの実装です。
+*/
+func (f *File) Values(ctx context.Context, id string, filter Filter) ([]model.Value, error) {
+	return f.memory.Values(ctx, id, filter)
+}
+
+/*
+Write implements Storage.
+
+Writeは、Storageインターフェースの実装です。Memoryと同様に保存した後、書き込みをログに追記します。
+ログが大きくなった場合は、ログを圧縮します。
+*/
+func (f *File) Write(ctx context.Context, pointSets []*model.OriginalPointSet, points []*model.Point) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.memory.Write(ctx, pointSets, points); err != nil {
+		return err
+	}
+	if err := f.appendLog(logRecord{PointSets: pointSets, Points: points}); err != nil {
+		return err
+	}
+	if f.logSize > max(f.CompactionSize, f.snapshotSize) {
+		return f.compact()
+	}
+	return nil
+}
+
+/*
+Compact writes all content to the snapshot and empties the log.
+
+Compactは、すべての内容をスナップショットに書き出し、ログを空にします。
+*/
+func (f *File) Compact() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.compact()
+}
+
+/*
+Close compacts the log and closes the files.
+
+Closeは、ログを圧縮してファイルを閉じます。Closeの後にWriteを呼び出すと、ログを開き直します。
+*/
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.logSize > 0 {
+		if err := f.compact(); err != nil {
+			return err
+		}
+	}
+	if f.log == nil {
+		return nil
+	}
+	err := f.log.Close()
+	f.log = nil
+	if err != nil {
+		return errors.Wrapf(err, "failed to close %s", f.logPath())
+	}
+	return nil
+}
+
+// logPath はログファイルのパスを返す
+func (f *File) logPath() string {
+	return f.path + ".log"
+}
+
+// appendLog は書き込みをログに1行追記し、fsyncする。f.muを取得した状態で呼び出す
+func (f *File) appendLog(record logRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode storage log")
+	}
+	if f.log == nil {
+		log, err := os.OpenFile(f.logPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return errors.Wrapf(err, "failed to save %s", f.logPath())
+		}
+		if err := syncDir(filepath.Dir(f.path)); err != nil {
+			log.Close()
+			return errors.Wrapf(err, "failed to save %s", f.logPath())
+		}
+		f.log = log
+	}
+	if _, err := f.log.Write(append(data, '\n')); err != nil {
+		// 途中まで書き込んだ行を取り除き、次の追記でログが壊れないようにする
+		f.log.Truncate(f.logSize)
+		return errors.Wrapf(err, "failed to save %s", f.logPath())
+	}
+	if err := f.log.Sync(); err != nil {
+		return errors.Wrapf(err, "failed to save %s", f.logPath())
+	}
+	f.logSize += int64(len(data)) + 1
+	return nil
+}
+
+// compact はメモリ上の内容をスナップショットに保存し、ログを空にする。f.muを取得した状態で呼び出す
+func (f *File) compact() error {
+	f.memory.mu.RLock()
+	data, err := json.Marshal(fileData{PointSets: f.memory.pointSets, Points: f.memory.points})
+	f.memory.mu.RUnlock()
+	if err != nil {
+		return errors.Wrap(err, "failed to encode storage")
+	}
+	if err := writeFileSync(f.path, data); err != nil {
+		return errors.Wrapf(err, "failed to save %s", f.path)
+	}
+	f.snapshotSize = int64(len(data))
+
+	// スナップショットを置き換えた後にログを空にする。この間に終了しても、ログを再度適用するだけである
+	if f.log != nil {
+		if err := f.log.Truncate(0); err != nil {
+			return errors.Wrapf(err, "failed to truncate %s", f.logPath())
+		}
+		if err := f.log.Sync(); err != nil {
+			return errors.Wrapf(err, "failed to truncate %s", f.logPath())
+		}
+	} else if err := os.Truncate(f.logPath(), 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrapf(err, "failed to truncate %s", f.logPath())
+	}
+	f.logSize = 0
+	return nil
+}
+
+// writeFileSync はdataを一時ファイルに書き込んでfsyncし、pathに置き換えてからディレクトリをfsyncする
+func writeFileSync(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir はディレクトリをfsyncし、ファイルの作成や置き換えを永続化する
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+func TestFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "fiap.json")
+
+	f, err := NewFile(path)
+	require.NoError(t, err)
+
+	// テスト対象の関数を実行
+	err = f.Write(ctx, []*model.OriginalPointSet{
+		{Id: "building1/", Point: []*model.Point{{Id: "building1/Power"}}},
+	}, []*model.Point{
+		{Id: "building1/Power", Value: []model.Value{{Time: minutes(0), Value: "100"}}},
+	})
+	require.NoError(t, err)
+
+	// 保存したファイルを読み込み直す
+	reopened, err := NewFile(path)
+	require.NoError(t, err)
+
+	pointSetIDs, pointIDs, err := reopened.PointSet(ctx, "building1/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, pointSetIDs)
+	assert.Equal(t, []string{"building1/Power"}, pointIDs)
+
+	values, err := reopened.Values(ctx, "building1/Power", Filter{})
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	assert.True(t, values[0].Time.Equal(minutes(0)))
+	assert.Equal(t, "100", values[0].Value)
+
+	t.Run("invalid file", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "invalid.json")
+		require.NoError(t, os.WriteFile(invalid, []byte("{"), 0644))
+
+		_, err := NewFile(invalid)
+
+		assert.ErrorContains(t, err, "failed to parse")
+	})
+
+	t.Run("unwritable directory", func(t *testing.T) {
+		f, err := NewFile(filepath.Join(t.TempDir(), "missing", "fiap.json"))
+		require.NoError(t, err)
+
+		err = f.Write(ctx, nil, []*model.Point{{Id: "building1/Power"}})
+
+		assert.ErrorContains(t, err, "failed to save")
+	})
+}
+
+func TestFileLog(t *testing.T) {
+	ctx := context.Background()
+	point := func(i int) []*model.Point {
+		return []*model.Point{{Id: "building1/Power", Value: []model.Value{{Time: minutes(i), Value: "100"}}}}
+	}
+
+	t.Run("append without rewriting snapshot", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fiap.json")
+		f, err := NewFile(path)
+		require.NoError(t, err)
+
+		// テスト対象の関数を実行
+		require.NoError(t, f.Write(ctx, nil, point(0)))
+		require.NoError(t, f.Write(ctx, nil, point(1)))
+
+		_, err = os.Stat(path)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		reopened, err := NewFile(path)
+		require.NoError(t, err)
+		values, err := reopened.Values(ctx, "building1/Power", Filter{})
+		require.NoError(t, err)
+		assert.Len(t, values, 2)
+	})
+
+	t.Run("compaction", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fiap.json")
+		f, err := NewFile(path)
+		require.NoError(t, err)
+		f.CompactionSize = 1
+
+		// テスト対象の関数を実行
+		require.NoError(t, f.Write(ctx, nil, point(0)))
+
+		info, err := os.Stat(path + ".log")
+		require.NoError(t, err)
+		assert.Zero(t, info.Size())
+		_, err = os.Stat(path)
+		require.NoError(t, err)
+
+		// 圧縮の後もログに追記できる
+		f.CompactionSize = DefaultCompactionSize
+		require.NoError(t, f.Write(ctx, nil, point(1)))
+		reopened, err := NewFile(path)
+		require.NoError(t, err)
+		values, err := reopened.Values(ctx, "building1/Power", Filter{})
+		require.NoError(t, err)
+		assert.Len(t, values, 2)
+	})
+
+	t.Run("close compacts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fiap.json")
+		f, err := NewFile(path)
+		require.NoError(t, err)
+		require.NoError(t, f.Write(ctx, nil, point(0)))
+
+		// テスト対象の関数を実行
+		require.NoError(t, f.Close())
+
+		info, err := os.Stat(path + ".log")
+		require.NoError(t, err)
+		assert.Zero(t, info.Size())
+		reopened, err := NewFile(path)
+		require.NoError(t, err)
+		values, err := reopened.Values(ctx, "building1/Power", Filter{})
+		require.NoError(t, err)
+		assert.Len(t, values, 1)
+	})
+
+	t.Run("torn last line", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fiap.json")
+		f, err := NewFile(path)
+		require.NoError(t, err)
+		require.NoError(t, f.Write(ctx, nil, point(0)))
+		log, err := os.OpenFile(path+".log", os.O_WRONLY|os.O_APPEND, 0o644)
+		require.NoError(t, err)
+		_, err = log.WriteString(`{"points":[{"id":"building1/Po`)
+		require.NoError(t, err)
+		require.NoError(t, log.Close())
+
+		// テスト対象の関数を実行
+		reopened, err := NewFile(path)
+
+		require.NoError(t, err)
+		values, err := reopened.Values(ctx, "building1/Power", Filter{})
+		require.NoError(t, err)
+		assert.Len(t, values, 1)
+		// 途中で終了した行を取り除いてから追記する
+		require.NoError(t, reopened.Write(ctx, nil, point(1)))
+		again, err := NewFile(path)
+		require.NoError(t, err)
+		values, err = again.Values(ctx, "building1/Power", Filter{})
+		require.NoError(t, err)
+		assert.Len(t, values, 2)
+	})
+
+	t.Run("broken line", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fiap.json")
+		require.NoError(t, os.WriteFile(path+".log", []byte("{\n"), 0o644))
+
+		// テスト対象の関数を実行
+		_, err := NewFile(path)
+
+		assert.ErrorContains(t, err, "failed to parse")
+	})
+}
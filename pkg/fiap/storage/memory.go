@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+/*
+Memory is a Storage that keeps points and pointSets in memory.
+
+Memoryは、pointとpointSetをメモリ上に保持するStorageです。NewMemoryで作成してください。
+プロセスが終了すると、保存した内容は失われます。
+*/
+type Memory struct {
+	mu        sync.RWMutex
+	points    map[string][]model.Value
+	pointSets map[string]*model.ProcessedPointSet
+}
+
+/*
+NewMemory returns an empty Memory.
+
+NewMemoryは、空のMemoryを返します。
+*/
+func NewMemory() *Memory {
+	return &Memory{
+		points:    make(map[string][]model.Value),
+		pointSets: make(map[string]*model.ProcessedPointSet),
+	}
+}
+
+/*
+PointSet implements Storage.
+
+PointSetは、Storageインターフェースの実装です。
+*/
+func (m *Memory) PointSet(ctx context.Context, id string) ([]string, []string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	pointSet, ok := m.pointSets[id]
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	return append([]string{}, pointSet.PointSetID...), append([]string{}, pointSet.PointID...), nil
+}
+
+/*
+Values implements Storage.
+
+Valuesは、Storageインターフェースの実装です。
+*/
+func (m *Memory) Values(ctx context.Context, id string, filter Filter) ([]model.Value, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values, ok := m.points[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return filter.Apply(values), nil
+}
+
+/*
+Write implements Storage.
+
+Writeは、Storageインターフェースの実装です。
+すでに保存されているpointSetには子のIDを追加し、pointにはvalueを追加します。同じ時刻のvalueが保存されている場合は置き換えます。
+*/
+func (m *Memory) Write(ctx context.Context, pointSets []*model.OriginalPointSet, points []*model.Point) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, pointSet := range pointSets {
+		m.writePointSet(pointSet)
+	}
+	for _, point := range points {
+		m.writePoint(point)
+	}
+	return nil
+}
+
+// writePointSet はpointSetとその子孫を保存する。m.muを取得した状態で呼び出す
+func (m *Memory) writePointSet(pointSet *model.OriginalPointSet) {
+	stored, ok := m.pointSets[pointSet.Id]
+	if !ok {
+		stored = &model.ProcessedPointSet{PointSetID: []string{}, PointID: []string{}}
+		m.pointSets[pointSet.Id] = stored
+	}
+	for _, child := range pointSet.PointSet {
+		stored.PointSetID = appendUnique(stored.PointSetID, child.Id)
+		m.writePointSet(child)
+	}
+	for _, point := range pointSet.Point {
+		stored.PointID = appendUnique(stored.PointID, point.Id)
+		m.writePoint(point)
+	}
+}
+
+// writePoint はpointのvalueを時刻の昇順を保って保存する。m.muを取得した状態で呼び出す
+func (m *Memory) writePoint(point *model.Point) {
+	values, ok := m.points[point.Id]
+	if !ok {
+		values = []model.Value{}
+	}
+	for _, v := range point.Value {
+		i := sort.Search(len(values), func(i int) bool { return !values[i].Time.Before(v.Time) })
+		if i < len(values) && values[i].Time.Equal(v.Time) {
+			values[i] = v
+			continue
+		}
+		values = append(values, model.Value{})
+		copy(values[i+1:], values[i:])
+		values[i] = v
+	}
+	m.points[point.Id] = values
+}
+
+// appendUnique はidがidsに含まれていない場合のみ追加する
+func appendUnique(ids []string, id string) []string {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	// テスト対象の関数を実行
+	err := m.Write(ctx, []*model.OriginalPointSet{
+		{
+			Id:       "building1/",
+			PointSet: []*model.OriginalPointSet{{Id: "building1/floor1/", Point: []*model.Point{{Id: "building1/floor1/Room101"}}}},
+			Point:    []*model.Point{{Id: "building1/Power", Value: []model.Value{{Time: minutes(0), Value: "100"}}}},
+		},
+	}, []*model.Point{
+		{Id: "building1/Power", Value: []model.Value{{Time: minutes(1), Value: "110"}, {Time: minutes(0), Value: "105"}}},
+	})
+	require.NoError(t, err)
+
+	t.Run("PointSet", func(t *testing.T) {
+		pointSetIDs, pointIDs, err := m.PointSet(ctx, "building1/")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"building1/floor1/"}, pointSetIDs)
+		assert.Equal(t, []string{"building1/Power"}, pointIDs)
+
+		pointSetIDs, pointIDs, err = m.PointSet(ctx, "building1/floor1/")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{}, pointSetIDs)
+		assert.Equal(t, []string{"building1/floor1/Room101"}, pointIDs)
+	})
+
+	t.Run("Values", func(t *testing.T) {
+		values, err := m.Values(ctx, "building1/Power", Filter{})
+
+		require.NoError(t, err)
+		// 同じ時刻のvalueは後に書き込んだものに置き換わる
+		assert.Equal(t, []model.Value{{Time: minutes(0), Value: "105"}, {Time: minutes(1), Value: "110"}}, values)
+
+		values, err = m.Values(ctx, "building1/floor1/Room101", Filter{})
+
+		require.NoError(t, err)
+		assert.Empty(t, values)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, _, err := m.PointSet(ctx, "building1/Power")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		_, err = m.Values(ctx, "building2/Power", Filter{})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
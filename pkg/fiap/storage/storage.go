@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
+)
+
+/*
+ErrNotFound is returned when the point or pointSet does not exist.
+
+ErrNotFoundは、pointまたはpointSetが存在しないことを表します。
+*/
+var ErrNotFound = errors.New("not found")
+
+/*
+Storage is the interface of the storage used by the FIAP storage server.
+
+Storageは、FIAPストレージサーバが使用するストレージのインターフェースです。
+複数のgoroutineから同時に呼び出されるため、実装は並行に安全である必要があります。
+
+PointSetは、指定されたIDのpointSetが持つ子のpointSetとpointのIDを返します。pointSetが存在しない場合はErrNotFoundを返します。
+
+Valuesは、指定されたIDのpointのvalueのうち、filterの条件に一致するものを時刻の昇順で返します。
+pointが存在しない場合はErrNotFoundを返します。
+
+Writeは、pointSetとpointを保存します。pointSetの子のpointSetとpointも保存します。
+*/
+type Storage interface {
+	PointSet(ctx context.Context, id string) (pointSetIDs []string, pointIDs []string, err error)
+	Values(ctx context.Context, id string, filter Filter) ([]model.Value, error)
+	Write(ctx context.Context, pointSets []*model.OriginalPointSet, points []*model.Point) error
+}
+
+/*
+Filter is the condition of values parsed from a FIAP key.
+
+Filterは、FIAPのkeyから作成したvalueの条件です。nilの条件は使用しません。
+Valueは、attrNameが"value"のkeyから作成した値の条件です。この場合、時刻の条件はすべてnilになります。
+*/
+type Filter struct {
+	Eq     *time.Time
+	Neq    *time.Time
+	Lt     *time.Time
+	Gt     *time.Time
+	Lteq   *time.Time
+	Gteq   *time.Time
+	Value  *model.ValueCondition
+	Select model.SelectType
+}
+
+/*
+NewFilter parses the time conditions of the key.
+
+NewFilterは、keyの時刻の条件を解釈してFilterを返します。
+attrNameが"value"の場合は、keyの条件を値の条件としてFilter.Valueに設定します。
+
+errの発生条件
+ - attrNameがtime、value、空文字のいずれでもない場合
+ - 時刻の条件がRFC3339形式でない場合
+ - selectがmaximum、minimum、空文字のいずれでもない場合
+*/
+func NewFilter(key model.Key) (Filter, error) {
+	filter := Filter{Select: key.Select}
+	switch key.Select {
+	case model.SelectTypeNone, model.SelectTypeMaximum, model.SelectTypeMinimum:
+	default:
+		return Filter{}, errors.Newf("invalid select %q in key %q", key.Select, key.Id)
+	}
+	switch key.AttrName {
+	case "", model.AttrNameTime:
+	case model.AttrNameValue:
+		filter.Value = newValueCondition(key)
+		return filter, nil
+	default:
+		return Filter{}, errors.Newf("unsupported attrName %q in key %q", key.AttrName, key.Id)
+	}
+	for _, c := range []struct {
+		attr string
+		dst  **time.Time
+	}{
+		{key.Eq, &filter.Eq},
+		{key.Neq, &filter.Neq},
+		{key.Lt, &filter.Lt},
+		{key.Gt, &filter.Gt},
+		{key.Lteq, &filter.Lteq},
+		{key.Gteq, &filter.Gteq},
+	} {
+		if c.attr == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, c.attr)
+		if err != nil {
+			return Filter{}, errors.Newf("invalid time %q in key %q", c.attr, key.Id)
+		}
+		*c.dst = &t
+	}
+	return filter, nil
+}
+
+// newValueCondition はkeyの条件を値の条件に変換する。空文字の条件はnilとする
+func newValueCondition(key model.Key) *model.ValueCondition {
+	value := &model.ValueCondition{}
+	for _, c := range []struct {
+		attr string
+		dst  **string
+	}{
+		{key.Eq, &value.Eq},
+		{key.Neq, &value.Neq},
+		{key.Lt, &value.Lt},
+		{key.Gt, &value.Gt},
+		{key.Lteq, &value.Lteq},
+		{key.Gteq, &value.Gteq},
+	} {
+		if c.attr != "" {
+			attr := c.attr
+			*c.dst = &attr
+		}
+	}
+	return value
+}
+
+/*
+Match reports whether the time satisfies the time conditions. Select is not used.
+
+Matchは、時刻がfilterの時刻の条件をすべて満たす場合に真を返します。Selectは使用しません。
+*/
+func (f Filter) Match(t time.Time) bool {
+	return (f.Eq == nil || t.Equal(*f.Eq)) &&
+		(f.Neq == nil || !t.Equal(*f.Neq)) &&
+		(f.Lt == nil || t.Before(*f.Lt)) &&
+		(f.Gt == nil || t.After(*f.Gt)) &&
+		(f.Lteq == nil || !t.After(*f.Lteq)) &&
+		(f.Gteq == nil || !t.Before(*f.Gteq))
+}
+
+/*
+MatchValue reports whether the value satisfies the value conditions. It returns true if Value is nil.
+
+MatchValueは、値がfilterの値の条件をすべて満たす場合に真を返します。Valueがnilの場合は常に真を返します。
+値はtools.CompareValuesで比較します。
+*/
+func (f Filter) MatchValue(value string) bool {
+	c := f.Value
+	if c == nil {
+		return true
+	}
+	compare := func(cond *string) int { return tools.CompareValues(value, *cond) }
+	return (c.Eq == nil || compare(c.Eq) == 0) &&
+		(c.Neq == nil || compare(c.Neq) != 0) &&
+		(c.Lt == nil || compare(c.Lt) < 0) &&
+		(c.Gt == nil || compare(c.Gt) > 0) &&
+		(c.Lteq == nil || compare(c.Lteq) <= 0) &&
+		(c.Gteq == nil || compare(c.Gteq) >= 0)
+}
+
+/*
+Apply returns the values that match the filter in ascending order of time.
+
+Applyは、valuesのうちfilterの条件に一致するものを時刻の昇順で返します。
+Selectがmaximumの場合は最新の1つ、minimumの場合は最古の1つのみを返します。valuesは変更しません。
+Valueがnilでない場合は、Selectがmaximumのとき値が最大の1つ、minimumのとき値が最小の1つを返します。
+同じ値が複数ある場合は、maximumでは最新のもの、minimumでは最古のものを返します。
+*/
+func (f Filter) Apply(values []model.Value) []model.Value {
+	selected := make([]model.Value, 0, len(values))
+	for _, v := range values {
+		if f.Match(v.Time) && f.MatchValue(v.Value) {
+			selected = append(selected, v)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].Time.Before(selected[j].Time) })
+
+	if len(selected) == 0 {
+		return selected
+	}
+	if f.Value != nil && f.Select != model.SelectTypeNone {
+		return selectValue(selected, f.Select)
+	}
+	switch f.Select {
+	case model.SelectTypeMaximum:
+		return selected[len(selected)-1:]
+	case model.SelectTypeMinimum:
+		return selected[:1]
+	}
+	return selected
+}
+
+// selectValue は時刻の昇順に並んだvaluesから、値が最大または最小の1つを返す
+func selectValue(values []model.Value, selectType model.SelectType) []model.Value {
+	index := 0
+	for i, v := range values[1:] {
+		c := tools.CompareValues(v.Value, values[index].Value)
+		if (selectType == model.SelectTypeMaximum && c >= 0) || (selectType == model.SelectTypeMinimum && c < 0) {
+			index = i + 1
+		}
+	}
+	return values[index : index+1]
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+var base = time.Date(2012, 2, 2, 16, 34, 0, 0, time.UTC)
+
+// minutes はbaseからn分後の時刻を返す
+func minutes(n int) time.Time {
+	return base.Add(time.Duration(n) * time.Minute)
+}
+
+func TestNewFilter(t *testing.T) {
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		key      model.Key
+		expected []string
+		err      string
+	}{
+		{name: "no condition", key: model.Key{}, expected: []string{"0", "1", "2", "3", "10"}},
+		{name: "eq", key: model.Key{Eq: "2012-02-02T16:35:00Z"}, expected: []string{"1"}},
+		{name: "neq", key: model.Key{Neq: "2012-02-02T16:35:00Z"}, expected: []string{"0", "2", "3", "10"}},
+		{name: "gteq and lt", key: model.Key{Gteq: "2012-02-02T16:35:00Z", Lt: "2012-02-02T16:37:00Z"}, expected: []string{"1", "2"}},
+		{name: "gt and lteq with offset", key: model.Key{Gt: "2012-02-03T01:35:00+09:00", Lteq: "2012-02-03T01:37:00+09:00"}, expected: []string{"2", "3"}},
+		{name: "maximum", key: model.Key{Lt: "2012-02-02T16:37:00Z", Select: model.SelectTypeMaximum}, expected: []string{"2"}},
+		{name: "minimum", key: model.Key{Gt: "2012-02-02T16:34:00Z", Select: model.SelectTypeMinimum}, expected: []string{"1"}},
+		{name: "invalid time", key: model.Key{Id: "id1", Gteq: "yesterday"}, err: `invalid time "yesterday" in key "id1"`},
+		{name: "invalid select", key: model.Key{Id: "id1", Select: "latest"}, err: `invalid select "latest" in key "id1"`},
+		{name: "time attrName", key: model.Key{AttrName: model.AttrNameTime, Eq: "2012-02-02T16:35:00Z"}, expected: []string{"1"}},
+		{name: "value eq", key: model.Key{AttrName: model.AttrNameValue, Eq: "2.0"}, expected: []string{"2"}},
+		{name: "value neq", key: model.Key{AttrName: model.AttrNameValue, Neq: "2"}, expected: []string{"0", "1", "3", "10"}},
+		{name: "value numeric range", key: model.Key{AttrName: model.AttrNameValue, Gt: "1", Lteq: "10"}, expected: []string{"2", "3", "10"}},
+		{name: "value gteq and lt", key: model.Key{AttrName: model.AttrNameValue, Gteq: "1", Lt: "3"}, expected: []string{"1", "2"}},
+		{name: "value maximum", key: model.Key{AttrName: model.AttrNameValue, Lt: "10", Select: model.SelectTypeMaximum}, expected: []string{"3"}},
+		{name: "value minimum", key: model.Key{AttrName: model.AttrNameValue, Select: model.SelectTypeMinimum}, expected: []string{"0"}},
+		{name: "unsupported attrName", key: model.Key{Id: "id1", AttrName: "unit"}, err: `unsupported attrName "unit" in key "id1"`},
+	}
+
+	// 時刻の降順で渡しても昇順で返ることを確認する
+	values := []model.Value{
+		{Time: minutes(3), Value: "3"},
+		{Time: minutes(1), Value: "1"},
+		{Time: minutes(2), Value: "2"},
+		{Time: minutes(0), Value: "0"},
+		{Time: minutes(4), Value: "10"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// テスト対象の関数を実行
+			filter, err := NewFilter(tc.key)
+
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			actual := []string{}
+			for _, v := range filter.Apply(values) {
+				actual = append(actual, v.Value)
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
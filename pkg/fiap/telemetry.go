@@ -0,0 +1,137 @@
+package fiap
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+)
+
+// instrumentationName はOpenTelemetryのTracerとMeterの名前
+const instrumentationName = "github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap"
+
+// spanとメトリクスの属性のキー
+const (
+	attrURL            = attribute.Key("url.full")
+	attrHTTPStatusCode = attribute.Key("http.response.status_code")
+	attrErrorType      = attribute.Key("error.type")
+	attrQueryID        = attribute.Key("fiap.query.id")
+	attrKeyCount       = attribute.Key("fiap.key.count")
+	attrCursor         = attribute.Key("fiap.cursor")
+	attrNextCursor     = attribute.Key("fiap.cursor.next")
+	attrPageCount      = attribute.Key("fiap.page.count")
+	attrValueCount     = attribute.Key("fiap.value.count")
+	attrFIAPErrorType  = attribute.Key("fiap.error.type")
+)
+
+// error.typeに設定する、FIAPのエラー以外の失敗の種類
+const (
+	errorTypeTransport         = "transport"
+	errorTypeSOAPFault         = "soap_fault"
+	errorTypeMalformedResponse = "malformed_response"
+	errorTypeOther             = "_OTHER"
+)
+
+// instruments はクライアントが記録するメトリクスの計器
+type instruments struct {
+	requests metric.Int64Counter
+	errors   metric.Int64Counter
+	duration metric.Float64Histogram
+	bytes    metric.Int64Counter
+	values   metric.Int64Counter
+}
+
+// instrumentsCache はMeterProviderごとに作成した計器を保持する
+var instrumentsCache sync.Map
+
+// tracer はspanの作成に使用するTracerを返す。TracerProviderが設定されていない場合はグローバルのTracerProviderを使用する
+func (f *FetchClient) tracer() trace.Tracer {
+	tp := f.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(instrumentationName)
+}
+
+// instruments はメトリクスの記録に使用する計器を返す。MeterProviderが設定されていない場合はグローバルのMeterProviderを使用する
+func (f *FetchClient) instruments() *instruments {
+	mp := f.MeterProvider
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+	// 比較できないMeterProviderはmapのキーにできないため、その都度作成する
+	if !reflect.TypeOf(mp).Comparable() {
+		return newInstruments(mp)
+	}
+	if inst, ok := instrumentsCache.Load(mp); ok {
+		return inst.(*instruments)
+	}
+	inst, _ := instrumentsCache.LoadOrStore(mp, newInstruments(mp))
+	return inst.(*instruments)
+}
+
+// newInstruments は計器を作成する。作成に失敗した計器はnoopの計器になるため、エラーは無視する
+func newInstruments(mp metric.MeterProvider) *instruments {
+	meter := mp.Meter(instrumentationName)
+	inst := &instruments{}
+	inst.requests, _ = meter.Int64Counter("fiap.client.requests",
+		metric.WithDescription("Number of SOAP requests sent to FIAP servers."),
+		metric.WithUnit("{request}"))
+	inst.errors, _ = meter.Int64Counter("fiap.client.errors",
+		metric.WithDescription("Number of failed requests, including FIAP errors returned by servers."),
+		metric.WithUnit("{error}"))
+	inst.duration, _ = meter.Float64Histogram("fiap.client.request.duration",
+		metric.WithDescription("Duration of SOAP requests."),
+		metric.WithUnit("s"))
+	inst.bytes, _ = meter.Int64Counter("fiap.client.response.size",
+		metric.WithDescription("Number of bytes received in SOAP responses."),
+		metric.WithUnit("By"))
+	inst.values, _ = meter.Int64Counter("fiap.client.values",
+		metric.WithDescription("Number of time series values received."),
+		metric.WithUnit("{value}"))
+	return inst
+}
+
+// endSpan はエラーとFIAPのエラーをspanに記録し、spanを終了する
+func endSpan(span trace.Span, fiapErr *model.Error, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if fiapErr != nil {
+		span.SetAttributes(attrFIAPErrorType.String(fiapErr.Type))
+		span.SetStatus(codes.Error, "fiap error: "+fiapErr.Type)
+	}
+	span.End()
+}
+
+// countingDoFn はレスポンスのBodyから読み込んだバイト数をnに加算するようにdoFnを包む
+func countingDoFn(doFn func(*http.Request) (*http.Response, error), n *atomic.Int64) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		res, err := doFn(req)
+		if err == nil && res.Body != nil {
+			res.Body = &countingReadCloser{ReadCloser: res.Body, n: n}
+		}
+		return res, err
+	}
+}
+
+// countingReadCloser は読み込んだバイト数をnに加算する
+type countingReadCloser struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,219 @@
+package fiap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+// newTelemetryTestClient はspanとメトリクスを記録するクライアントを作成する
+func newTelemetryTestClient(connectionURL string) (*FetchClient, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	f := NewFetchClient(connectionURL,
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	return f, recorder, reader
+}
+
+// spanAttributes はspanの属性をmapに変換する
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+// sumValue はメトリクスの名前とerror.typeに一致するSumの合計値を返す
+func sumValue(t *testing.T, reader *sdkmetric.ManualReader, name string, errorType string) int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	var total int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				if v, ok := dp.Attributes.Value(attrErrorType); errorType != "" && (!ok || v.AsString() != errorType) {
+					continue
+				}
+				total += dp.Value
+			}
+		}
+	}
+	return total
+}
+
+func TestFetchTelemetry(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+	f, recorder, reader := newTelemetryTestClient(connectionURL)
+
+	// mockの有効化
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	// 1ページ目はcursorを返し、2ページ目で終了する
+	responder := testutil.CustomTransportResponder(`
+	<transport xmlns="http://gutp.jp/fiap/2009/11/">
+		<header>
+			<OK />
+			<query id="6ffac1fc-1d96-4c37-9a9a-0f1d8f3a3e2b" type="storage" cursor="cursor1" />
+		</header>
+		<body>
+			<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+				<value time="2012-02-02T16:34:05.000+09:00">30</value>
+				<value time="2012-02-02T16:35:05.000+09:00">31</value>
+			</point>
+		</body>
+	</transport>
+	`).Then(testutil.CustomBodyResponder(`
+	<body>
+		<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+			<value time="2012-02-02T16:36:05.000+09:00">32</value>
+		</point>
+	</body>
+	`))
+	httpmock.RegisterResponder("POST", connectionURL, responder)
+
+	// テスト対象の関数を実行
+	_, points, fiapErr, err := f.Fetch([]model.UserInputKey{
+		{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+	}, nil)
+
+	require.NoError(t, err)
+	require.Nil(t, fiapErr)
+	assert.Len(t, points["http://xxxxxxxx/tokyo/building1/Room101/"], 3)
+
+	// spanの確認
+	spans := recorder.Ended()
+	names := make([]string, 0, len(spans))
+	for _, s := range spans {
+		names = append(names, s.Name())
+	}
+	assert.Equal(t, []string{"fiap.query", "fiap.FetchOnce", "fiap.query", "fiap.FetchOnce", "fiap.Fetch"}, names)
+
+	fetchSpan := spans[4]
+	for _, s := range spans[:4] {
+		assert.Equal(t, fetchSpan.SpanContext().TraceID(), s.SpanContext().TraceID())
+	}
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, fetchSpan.SpanContext().SpanID(), spans[1].Parent().SpanID())
+
+	fetchAttrs := spanAttributes(fetchSpan)
+	assert.Equal(t, connectionURL, fetchAttrs[attrURL].AsString())
+	assert.Equal(t, int64(1), fetchAttrs[attrKeyCount].AsInt64())
+	assert.Equal(t, int64(2), fetchAttrs[attrPageCount].AsInt64())
+	assert.Equal(t, int64(3), fetchAttrs[attrValueCount].AsInt64())
+	assert.Equal(t, codes.Unset, fetchSpan.Status().Code)
+
+	firstPageAttrs := spanAttributes(spans[1])
+	assert.Equal(t, "", firstPageAttrs[attrCursor].AsString())
+	assert.Equal(t, "cursor1", firstPageAttrs[attrNextCursor].AsString())
+	assert.Equal(t, int64(2), firstPageAttrs[attrValueCount].AsInt64())
+	secondPageAttrs := spanAttributes(spans[3])
+	assert.Equal(t, "cursor1", secondPageAttrs[attrCursor].AsString())
+
+	queryAttrs := spanAttributes(spans[0])
+	assert.Equal(t, int64(200), queryAttrs[attrHTTPStatusCode].AsInt64())
+	assert.NotEmpty(t, queryAttrs[attrQueryID].AsString())
+
+	// メトリクスの確認
+	assert.Equal(t, int64(2), sumValue(t, reader, "fiap.client.requests", ""))
+	assert.Equal(t, int64(3), sumValue(t, reader, "fiap.client.values", ""))
+	assert.Equal(t, int64(0), sumValue(t, reader, "fiap.client.errors", ""))
+	assert.Greater(t, sumValue(t, reader, "fiap.client.response.size", ""), int64(0))
+}
+
+func TestFetchOnceTelemetryErrors(t *testing.T) {
+	var connectionURL = defaultConnectionURL
+
+	t.Run("fiap error", func(t *testing.T) {
+		f, recorder, reader := newTelemetryTestClient(connectionURL)
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, testutil.CustomHeaderBodyResponder(`
+		<header>
+			<error type="POINT_NOT_FOUND">point is not found</error>
+		</header>
+		`))
+
+		// テスト対象の関数を実行
+		_, _, _, fiapErr, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		require.NoError(t, err)
+		require.NotNil(t, fiapErr)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+		assert.Equal(t, "POINT_NOT_FOUND", spanAttributes(spans[1])[attrFIAPErrorType].AsString())
+		assert.Equal(t, int64(1), sumValue(t, reader, "fiap.client.errors", "POINT_NOT_FOUND"))
+	})
+
+	t.Run("malformed response", func(t *testing.T) {
+		f, recorder, reader := newTelemetryTestClient(connectionURL)
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, testutil.CustomTransportStatusCodeResponder("", 500))
+
+		// テスト対象の関数を実行
+		_, _, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		require.Error(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+		assert.NotEmpty(t, spans[1].Events())
+		assert.Equal(t, int64(1), sumValue(t, reader, "fiap.client.errors", errorTypeMalformedResponse))
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		f, recorder, reader := newTelemetryTestClient(connectionURL)
+
+		// mockの有効化
+		httpmock.Activate()
+		defer httpmock.DeactivateAndReset()
+
+		httpmock.RegisterResponder("POST", connectionURL, httpmock.NewErrorResponder(assert.AnError))
+
+		// テスト対象の関数を実行
+		_, _, _, _, err := f.FetchOnce([]model.UserInputKey{
+			{ID: "http://xxxxxxxx/tokyo/building1/Room101/"},
+		}, nil)
+
+		require.Error(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "fiap.query", spans[0].Name())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Equal(t, int64(1), sumValue(t, reader, "fiap.client.errors", errorTypeTransport))
+		assert.Equal(t, int64(1), sumValue(t, reader, "fiap.client.requests", ""))
+	})
+}
@@ -0,0 +1,10 @@
+package testutil
+
+/*
+StringToStringp returns a pointer to the given string.
+
+StringToStringpは指定された文字列のポインタを返します。
+*/
+func StringToStringp(s string) *string {
+	return &s
+}
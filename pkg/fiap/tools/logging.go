@@ -2,10 +2,20 @@ package tools
 
 import (
 	"log"
+	"log/slog"
+	"sync/atomic"
 )
 
 type logLevel int
 
+/*
+LogLevelTrace is a constant of logLevel, representing trace log level.
+
+LogLevelTraceは、トレースログレベルを表すlogLevelの定数です。
+送受信したデータの内容など、デバッグログよりも詳細なログを出力する場合に使用します。
+*/
+const LogLevelTrace logLevel = -1
+
 /*
 LogLevelDebug is a constant of logLevel, representing debug log level.
 
@@ -13,23 +23,65 @@ LogLevelDebugは、デバッグログレベルを表すlogLevelの定数です
 出力ログレベルの設定や、ログprint時のログレベルの指定に使用します。
 */
 const LogLevelDebug logLevel = 0
+
+/*
+LogLevelInfo is a constant of logLevel, representing info log level.
+
+LogLevelInfoは、情報ログレベルを表すlogLevelの定数です。
+出力ログレベルの設定や、ログprint時のログレベルの指定に使用します。
+*/
+const LogLevelInfo logLevel = 1
+
+/*
+LogLevelWarn is a constant of logLevel, representing warn log level.
+
+LogLevelWarnは、警告ログレベルを表すlogLevelの定数です。
+出力ログレベルの設定や、ログprint時のログレベルの指定に使用します。
+*/
+const LogLevelWarn logLevel = 2
+
 /*
-LogLevelError is a constant of logLevel, representing error log level.	
+LogLevelError is a constant of logLevel, representing error log level.
 
 LogLevelErrorは、エラーログレベルを表すlogLevelの定数です。
 出力ログレベルの設定や、ログprint時のログレベルの指定に使用します。
 */
 const LogLevelError  logLevel = 3
 
-var setLogLevel logLevel = LogLevelError
+/*
+LevelTrace is a slog.Level for trace logs.
+
+LevelTraceは、トレースログのためのslog.Levelです。slog.LevelDebugよりも詳細なレベルを表します。
+
+送受信したデータの内容はこのレベルでのみ出力されます。
+*/
+const LevelTrace slog.Level = slog.LevelDebug - 4
+
+var setLogLevel atomic.Int32
+
+func init() {
+	setLogLevel.Store(int32(LogLevelError))
+}
 
 /*
-SetLogLevel sets the output log level.
+SetLogLevel sets the default output log level.
 
-SetLogLevelは出力ログのレベルを設定します。
+SetLogLevelはデフォルトの出力ログのレベルを設定します。複数のgoroutineから同時に呼び出すことができます。
+
+このログレベルは、Loggerを設定していないクライアントにのみ適用されます。
+クライアントごとにログレベルを設定する場合は、fiap.WithLogLevelまたはfiap.WithLoggerを使用してください。
 */
 func SetLogLevel(l logLevel) {
-	setLogLevel = l
+	setLogLevel.Store(int32(l))
+}
+
+/*
+GetLogLevel returns the default output log level.
+
+GetLogLevelはデフォルトの出力ログのレベルを返します。
+*/
+func GetLogLevel() logLevel {
+	return logLevel(setLogLevel.Load())
 }
 
 /*
@@ -38,13 +90,100 @@ LogPrintf prints logs with the specified log level.
 LogPrintfはログレベルを指定してログのprintを行います。
 */
 func LogPrintf(printLogLevel logLevel, format string, a ...interface{}) {
-	if printLogLevel >= setLogLevel {
-		var levelStr string
-		if printLogLevel == LogLevelDebug {
-			levelStr = "Debug"
-		} else if printLogLevel == LogLevelError {
-			levelStr = "Error"
-		}
-		log.Printf(levelStr + ": " + format, a...)
+	if printLogLevel >= GetLogLevel() {
+		log.Printf(printLogLevel.String() + ": " + format, a...)
 	}
-}
\ No newline at end of file
+}
+
+func (l logLevel) String() string {
+	switch l {
+	case LogLevelTrace:
+		return "Trace"
+	case LogLevelDebug:
+		return "Debug"
+	case LogLevelInfo:
+		return "Info"
+	case LogLevelWarn:
+		return "Warn"
+	case LogLevelError:
+		return "Error"
+	}
+	return ""
+}
+
+/*
+Level returns the slog.Level corresponding to the log level.
+
+Levelは、ログレベルに対応するslog.Levelを返します。
+*/
+func (l logLevel) Level() slog.Level {
+	switch {
+	case l <= LogLevelTrace:
+		return LevelTrace
+	case l == LogLevelDebug:
+		return slog.LevelDebug
+	case l == LogLevelInfo:
+		return slog.LevelInfo
+	case l == LogLevelWarn:
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
+
+/*
+Logger returns the default *slog.Logger used by the fiap package.
+
+Loggerは、fiapパッケージで使用するデフォルトの*slog.Loggerを返します。
+
+このLoggerは、SetLogLevelで設定したログレベル以上のログを標準のlogパッケージを通して出力します。
+ログレベルは出力のたびに参照するため、常に同じLoggerを返します。
+*/
+func Logger() *slog.Logger {
+	return defaultLogger
+}
+
+// defaultLogger はLoggerが返す共有の*slog.Logger
+var defaultLogger = slog.New(NewLogHandler(globalLeveler{}))
+
+/*
+NewLogHandler returns a slog.Handler which prints logs through the standard log package.
+
+NewLogHandlerは、標準のlogパッケージを通してログを出力するslog.Handlerを返します。
+
+levelより低いレベルのログは出力されません。ログは"level=DEBUG msg=... key=value"の形式で出力されます。
+*/
+func NewLogHandler(level slog.Leveler) slog.Handler {
+	return slog.NewTextHandler(stdLogWriter{}, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return a
+			}
+			switch a.Key {
+			case slog.TimeKey:
+				// 時刻はlogパッケージが出力する
+				return slog.Attr{}
+			case slog.LevelKey:
+				if l, ok := a.Value.Any().(slog.Level); ok && l <= LevelTrace {
+					return slog.String(slog.LevelKey, "TRACE")
+				}
+			}
+			return a
+		},
+	})
+}
+
+// globalLeveler はSetLogLevelで設定されたログレベルをslog.Levelとして返す
+type globalLeveler struct{}
+
+func (globalLeveler) Level() slog.Level {
+	return GetLogLevel().Level()
+}
+
+// stdLogWriter は書き込まれた内容を標準のlogパッケージで出力する
+type stdLogWriter struct{}
+
+func (stdLogWriter) Write(p []byte) (int, error) {
+	log.Print(string(p))
+	return len(p), nil
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+		SetLogLevel(LogLevelError)
+	}()
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		logLevel logLevel
+		expected string
+	}{
+		{name: "error", logLevel: LogLevelError, expected: "level=ERROR msg=error\n"},
+		{name: "warn", logLevel: LogLevelWarn, expected: "level=WARN msg=warn\nlevel=ERROR msg=error\n"},
+		{name: "info", logLevel: LogLevelInfo, expected: "level=INFO msg=info url=http://example.com\nlevel=WARN msg=warn\nlevel=ERROR msg=error\n"},
+		{name: "trace", logLevel: LogLevelTrace, expected: "level=TRACE msg=trace\nlevel=DEBUG msg=debug\nlevel=INFO msg=info url=http://example.com\nlevel=WARN msg=warn\nlevel=ERROR msg=error\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			SetLogLevel(tc.logLevel)
+
+			logger := Logger()
+			logger.Log(context.Background(), LevelTrace, "trace")
+			logger.Debug("debug")
+			logger.Info("info", "url", "http://example.com")
+			logger.Warn("warn")
+			logger.Error("error")
+
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	t.Run("shared", func(t *testing.T) {
+		buf.Reset()
+		SetLogLevel(LogLevelError)
+		logger := Logger()
+
+		// 取得した後に変更したログレベルも適用される
+		SetLogLevel(LogLevelInfo)
+		logger.Info("info")
+
+		assert.Same(t, logger, Logger())
+		assert.Equal(t, "level=INFO msg=info\n", buf.String())
+	})
+}
+
+func TestLogLevelLevel(t *testing.T) {
+	assert.Equal(t, LevelTrace, LogLevelTrace.Level())
+	assert.Equal(t, slog.LevelDebug, LogLevelDebug.Level())
+	assert.Equal(t, slog.LevelInfo, LogLevelInfo.Level())
+	assert.Equal(t, slog.LevelWarn, LogLevelWarn.Level())
+	assert.Equal(t, slog.LevelError, LogLevelError.Level())
+}
@@ -1,17 +1,195 @@
 package tools
 
 import (
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/cockroachdb/errors"
 )
 
 /*
 TimeToString returns the string representation(RFC3339) of the given time.Time.
 
-TimeToStringは指定されたtime.Timeの文字列表現(RFC3339)を返します。
+TimeToStringは指定されたtime.Timeの文字列表現(RFC3339)を返します。秒未満は切り捨てられます。
 */
 func TimeToString(t *time.Time) string {
+	return FormatTime(t, time.RFC3339)
+}
+
+/*
+FormatTime returns the string representation of the given time.Time in the layout.
+
+FormatTimeは指定されたtime.Timeをlayoutの書式で文字列にします。tがnilの場合は""を返します。
+layoutが空文字の場合はtime.RFC3339を使用します。秒未満を含める場合はtime.RFC3339Nanoを指定します。
+*/
+func FormatTime(t *time.Time, layout string) string {
 	if t == nil {
 		return ""
 	}
-	return t.Format(time.RFC3339)
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return t.Format(layout)
+}
+
+// layoutsWithoutOffset はオフセットを含まない日時と日付の書式。locのタイムゾーンの日時として解釈する
+var layoutsWithoutOffset = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var (
+	regexpUnixTime       = regexp.MustCompile(`^(@?)(\d+)(?:\.(\d{1,9}))?$`)
+	regexpRelativeTime   = regexp.MustCompile(`^([a-z]*)(?:([+-])(.+))?$`)
+	regexpDurationPart   = regexp.MustCompile(`^(\d+(?:\.\d+)?)(ns|us|µs|ms|s|m|h|d|w)`)
+	regexpLooksLikeDigit = regexp.MustCompile(`^\d`)
+)
+
+// maxAmbiguousUnixTimeDigits は、@を付けずに指定したUnix時間として受け付けない整数部の最大桁数です。
+// 20240101のような区切りのない日付がUnix時間として解釈されることを防ぎます。
+const maxAmbiguousUnixTimeDigits = 8
+
+/*
+ParseTime parses a datetime string or a relative time expression.
+
+ParseTimeは、日時の文字列または相対的な時刻の表現を解釈します。
+
+受け付ける書式は以下の通りです。
+ - RFC3339形式の日時 (例: 2012-01-01T00:00:00+09:00、2012-01-01T00:00:00.5Z)
+ - オフセットを含まない日時、日付 (例: 2012-01-01T00:00:00、2012-01-01 00:00、2012-01-01)。locのタイムゾーンの日時として解釈します
+ - Unix時間の秒数 (例: 1325343600、1325343600.5、@1325343600)。
+   20240101のような8桁以下の数字は日付との区別がつかないためエラーとし、Unix時間として指定する場合は@を付けます (例: @20240101)
+ - 基準時刻と、それに対する加減算 (例: now、now-7d、today+9h、-24h、+30m)
+
+基準時刻には now、today、yesterday、tomorrow、startofweek(月曜日の0時)、startofmonth、startofyear を使用できます。
+基準時刻を省略した場合はnowを基準とします。now以外の基準時刻はlocのタイムゾーンで計算します。
+加減算にはtime.ParseDurationの単位に加えて、d(日)とw(週)を使用できます。dとwは暦上の日数として加減算します。
+
+locがnilの場合はtime.Localを使用します。
+*/
+func ParseTime(s string, now time.Time, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, errors.New("time expression is empty")
+	}
+
+	if m := regexpUnixTime.FindStringSubmatch(s); m != nil {
+		if m[1] == "" && len(m[2]) <= maxAmbiguousUnixTimeDigits {
+			return time.Time{}, errors.Newf("ambiguous time expression '%s', use '@%s' for unix time or a date such as 2012-01-01", s, s)
+		}
+		return parseUnixTime(m[2], m[3], loc)
+	}
+	if !regexpLooksLikeDigit.MatchString(s) {
+		return parseRelativeTime(s, now, loc)
+	}
+
+	t, rfc3339Err := time.Parse(time.RFC3339, s)
+	if rfc3339Err == nil {
+		return t, nil
+	}
+	for _, layout := range layoutsWithoutOffset {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, rfc3339Err
+}
+
+func parseUnixTime(seconds, fraction string, loc *time.Location) (time.Time, error) {
+	sec, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid unix time '%s'", seconds)
+	}
+	var nsec int64
+	if fraction != "" {
+		// 小数部を9桁のナノ秒に揃える
+		nsec, _ = strconv.ParseInt(fraction+strings.Repeat("0", 9-len(fraction)), 10, 64)
+	}
+	return time.Unix(sec, nsec).In(loc), nil
+}
+
+func parseRelativeTime(s string, now time.Time, loc *time.Location) (time.Time, error) {
+	m := regexpRelativeTime.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return time.Time{}, errors.Newf("invalid time expression '%s'", s)
+	}
+	anchor, sign, offset := m[1], m[2], m[3]
+
+	var base time.Time
+	local := now.In(loc)
+	y, mon, d := local.Date()
+	switch anchor {
+	case "", "now":
+		base = now
+	case "today":
+		base = time.Date(y, mon, d, 0, 0, 0, 0, loc)
+	case "yesterday":
+		base = time.Date(y, mon, d-1, 0, 0, 0, 0, loc)
+	case "tomorrow":
+		base = time.Date(y, mon, d+1, 0, 0, 0, 0, loc)
+	case "startofweek":
+		// 月曜日を週の始まりとする
+		base = time.Date(y, mon, d-(int(local.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	case "startofmonth":
+		base = time.Date(y, mon, 1, 0, 0, 0, 0, loc)
+	case "startofyear":
+		base = time.Date(y, 1, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Time{}, errors.Newf("invalid time expression '%s', unknown anchor '%s'", s, anchor)
+	}
+	if anchor == "" && sign == "" {
+		return time.Time{}, errors.Newf("invalid time expression '%s'", s)
+	}
+	if sign == "" {
+		return base, nil
+	}
+
+	days, duration, err := parseOffset(offset)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid time expression '%s'", s)
+	}
+	if sign == "-" {
+		days, duration = -days, -duration
+	}
+	return base.AddDate(0, 0, days).Add(duration), nil
+}
+
+// parseOffset は"1d12h"のような加減算の表現を、暦上の日数とそれ以外の時間に分けて解釈する
+func parseOffset(s string) (days int, duration time.Duration, err error) {
+	if s == "" {
+		return 0, 0, errors.New("offset is empty")
+	}
+	for rest := s; rest != ""; {
+		m := regexpDurationPart.FindStringSubmatch(rest)
+		if m == nil {
+			return 0, 0, errors.Newf("cannot parse offset '%s'", rest)
+		}
+		rest = rest[len(m[0]):]
+		switch m[2] {
+		case "d", "w":
+			n, err := strconv.Atoi(m[1])
+			if err != nil {
+				return 0, 0, errors.Newf("days and weeks allow only integer, offset: %s", m[0])
+			}
+			if m[2] == "w" {
+				n *= 7
+			}
+			days += n
+		default:
+			d, err := time.ParseDuration(m[0])
+			if err != nil {
+				return 0, 0, errors.Wrapf(err, "cannot parse offset '%s'", m[0])
+			}
+			duration += d
+		}
+	}
+	return days, duration, nil
 }
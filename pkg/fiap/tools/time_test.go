@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTime(t *testing.T) {
+	tokyoTz := time.FixedZone("Asia/Tokyo", 9*60*60)
+	// 2024-03-14(木) 01:30:00 JST = 2024-03-13 16:30:00 UTC
+	now := time.Date(2024, 3, 13, 16, 30, 0, 0, time.UTC)
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		input    string
+		loc      *time.Location
+		expected time.Time
+	}{
+		{name: "RFC3339", input: "2012-01-01T00:00:00+09:00", expected: time.Date(2012, 1, 1, 0, 0, 0, 0, tokyoTz)},
+		{name: "RFC3339 with fraction", input: "2012-01-01T00:00:00.25Z", expected: time.Date(2012, 1, 1, 0, 0, 0, 250000000, time.UTC)},
+		{name: "datetime without offset", input: "2012-01-01T12:34:56", loc: tokyoTz, expected: time.Date(2012, 1, 1, 12, 34, 56, 0, tokyoTz)},
+		{name: "datetime without offset with fraction", input: "2012-01-01T12:34:56.125", loc: tokyoTz, expected: time.Date(2012, 1, 1, 12, 34, 56, 125000000, tokyoTz)},
+		{name: "datetime with space", input: "2012-01-01 12:34:56", loc: tokyoTz, expected: time.Date(2012, 1, 1, 12, 34, 56, 0, tokyoTz)},
+		{name: "datetime without seconds", input: "2012-01-01T12:34", loc: tokyoTz, expected: time.Date(2012, 1, 1, 12, 34, 0, 0, tokyoTz)},
+		{name: "date only", input: "2012-01-01", loc: tokyoTz, expected: time.Date(2012, 1, 1, 0, 0, 0, 0, tokyoTz)},
+		{name: "unix time", input: "1325343600", loc: time.UTC, expected: time.Date(2011, 12, 31, 15, 0, 0, 0, time.UTC)},
+		{name: "unix time with fraction", input: "1325343600.5", loc: time.UTC, expected: time.Date(2011, 12, 31, 15, 0, 0, 500000000, time.UTC)},
+		{name: "unix time with at mark", input: "@1325343600", loc: time.UTC, expected: time.Date(2011, 12, 31, 15, 0, 0, 0, time.UTC)},
+		{name: "short unix time with at mark", input: "@20240101", loc: time.UTC, expected: time.Date(1970, 8, 23, 6, 15, 1, 0, time.UTC)},
+		{name: "now", input: "now", expected: now},
+		{name: "now minus days", input: "now-7d", expected: now.AddDate(0, 0, -7)},
+		{name: "minus hours", input: "-24h", expected: now.Add(-24 * time.Hour)},
+		{name: "plus minutes", input: "+30m", expected: now.Add(30 * time.Minute)},
+		{name: "combined offset", input: "now-1d12h", expected: now.AddDate(0, 0, -1).Add(-12 * time.Hour)},
+		{name: "weeks", input: "now-2w", expected: now.AddDate(0, 0, -14)},
+		{name: "today in UTC", input: "today", loc: time.UTC, expected: time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC)},
+		{name: "today in Tokyo", input: "today", loc: tokyoTz, expected: time.Date(2024, 3, 14, 0, 0, 0, 0, tokyoTz)},
+		{name: "today with offset", input: "today+9h", loc: tokyoTz, expected: time.Date(2024, 3, 14, 9, 0, 0, 0, tokyoTz)},
+		{name: "yesterday", input: "yesterday", loc: tokyoTz, expected: time.Date(2024, 3, 13, 0, 0, 0, 0, tokyoTz)},
+		{name: "tomorrow", input: "tomorrow", loc: tokyoTz, expected: time.Date(2024, 3, 15, 0, 0, 0, 0, tokyoTz)},
+		{name: "start of week", input: "startofweek", loc: tokyoTz, expected: time.Date(2024, 3, 11, 0, 0, 0, 0, tokyoTz)},
+		{name: "start of month", input: "startofmonth", loc: tokyoTz, expected: time.Date(2024, 3, 1, 0, 0, 0, 0, tokyoTz)},
+		{name: "start of month minus a day", input: "StartOfMonth-1d", loc: tokyoTz, expected: time.Date(2024, 2, 29, 0, 0, 0, 0, tokyoTz)},
+		{name: "start of year", input: "startofyear", loc: tokyoTz, expected: time.Date(2024, 1, 1, 0, 0, 0, 0, tokyoTz)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ParseTime(tc.input, now, tc.loc)
+			assert.NoError(t, err)
+			assert.True(t, tc.expected.Equal(actual), "expected %s but %s", tc.expected, actual)
+		})
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	at := time.Date(2012, 1, 1, 0, 0, 0, 100000000, time.UTC)
+	whole := time.Date(2012, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		t        *time.Time
+		layout   string
+		expected string
+	}{
+		{name: "nil", t: nil, layout: time.RFC3339Nano, expected: ""},
+		{name: "default layout", t: &at, expected: "2012-01-01T00:00:00Z"},
+		{name: "RFC3339", t: &at, layout: time.RFC3339, expected: "2012-01-01T00:00:00Z"},
+		{name: "RFC3339Nano", t: &at, layout: time.RFC3339Nano, expected: "2012-01-01T00:00:00.1Z"},
+		{name: "RFC3339Nano without fraction", t: &whole, layout: time.RFC3339Nano, expected: "2012-01-01T00:00:00Z"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FormatTime(tc.t, tc.layout))
+		})
+	}
+	assert.Equal(t, "2012-01-01T00:00:00Z", TimeToString(&at))
+}
+
+func TestParseTimeStartOfWeekOnMonday(t *testing.T) {
+	monday := time.Date(2024, 3, 11, 10, 0, 0, 0, time.UTC)
+
+	actual, err := ParseTime("startofweek", monday, time.UTC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), actual)
+}
+
+func TestParseTimeErrors(t *testing.T) {
+	now := time.Date(2024, 3, 13, 16, 30, 0, 0, time.UTC)
+
+	// テストケースを定義
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "unknown anchor", input: "lastweek"},
+		{name: "unknown unit", input: "now-7x"},
+		{name: "missing offset", input: "now-"},
+		{name: "fractional days", input: "now-1.5d"},
+		{name: "sign only", input: "-"},
+		{name: "invalid date", input: "2012-02-30T23:59:59+09:00"},
+		{name: "invalid format", input: "2012/01/01 00:00:00 +0900"},
+		{name: "words", input: "Dec 31, 2012 11:59:59 PM JST"},
+		{name: "compact date", input: "20240101"},
+		{name: "compact date with fraction", input: "20240101.5"},
+		{name: "short digits", input: "123"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseTime(tc.input, now, time.UTC)
+			assert.Error(t, err)
+		})
+	}
+}
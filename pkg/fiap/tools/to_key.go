@@ -1,6 +1,8 @@
 package tools
 
 import (
+	"time"
+
 	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
 )
 
@@ -8,21 +10,42 @@ import (
 UserInputKeysToKeys converts UserInputKey to Key.
 
 UserInputKeysToKeysは、UserInputKeyをKeyに変換します。
+k.Valueがnilの場合はattrNameを"time"として時刻の条件を、nilでない場合はattrNameを"value"として値の条件を設定します。
+時刻はRFC3339形式で、秒未満は切り捨てられます。
 */
 func UserInputKeysToKeys(ks []model.UserInputKey) []model.Key {
+	return UserInputKeysToKeysWithLayout(ks, time.RFC3339)
+}
+
+/*
+UserInputKeysToKeysWithLayout converts UserInputKey to Key, formatting times in the layout.
+
+UserInputKeysToKeysWithLayoutは、UserInputKeysToKeysと同様にUserInputKeyをKeyに変換し、時刻をlayoutの書式で設定します。
+layoutが空文字の場合はtime.RFC3339を使用します。
+*/
+func UserInputKeysToKeysWithLayout(ks []model.UserInputKey, layout string) []model.Key {
 	var keys []model.Key
 	for _, k := range ks {
 		key := model.Key{
 			Id:       k.ID,
-			AttrName: "time",
-			Eq:       TimeToString(k.Eq),
-			Neq:      TimeToString(k.Neq),
-			Lt:       TimeToString(k.Lt),
-			Gt:       TimeToString(k.Gt),
-			Lteq:     TimeToString(k.Lteq),
-			Gteq:     TimeToString(k.Gteq),
+			AttrName: model.AttrNameTime,
+			Eq:       FormatTime(k.Eq, layout),
+			Neq:      FormatTime(k.Neq, layout),
+			Lt:       FormatTime(k.Lt, layout),
+			Gt:       FormatTime(k.Gt, layout),
+			Lteq:     FormatTime(k.Lteq, layout),
+			Gteq:     FormatTime(k.Gteq, layout),
 			Select:   k.MinMaxIndicator,
 		}
+		if v := k.Value; v != nil {
+			key.AttrName = model.AttrNameValue
+			key.Eq = StringToString(v.Eq)
+			key.Neq = StringToString(v.Neq)
+			key.Lt = StringToString(v.Lt)
+			key.Gt = StringToString(v.Gt)
+			key.Lteq = StringToString(v.Lteq)
+			key.Gteq = StringToString(v.Gteq)
+		}
 		keys = append(keys, key)
 	}
 	return keys
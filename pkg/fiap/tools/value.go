@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+CompareValues compares two values of FIAP. It compares them as numbers if both are numbers, otherwise as strings.
+
+CompareValuesは、FIAPの2つの値を比較し、aがbより小さい場合は-1、等しい場合は0、大きい場合は1を返します。
+両方が数値として解釈できる場合は数値として、それ以外の場合は文字列として比較します。
+*/
+func CompareValues(a, b string) int {
+	x, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	y, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if errA != nil || errB != nil || math.IsNaN(x) || math.IsNaN(y) {
+		return strings.Compare(a, b)
+	}
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+/*
+StringToString returns the string, or "" if s is nil.
+
+StringToStringは、sが指す文字列を返します。sがnilの場合は""を返します。
+*/
+func StringToString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
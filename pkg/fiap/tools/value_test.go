@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareValues(t *testing.T) {
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		a        string
+		b        string
+		expected int
+	}{
+		{name: "numbers less", a: "9", b: "10", expected: -1},
+		{name: "numbers equal", a: "1.0", b: "1", expected: 0},
+		{name: "numbers greater", a: "-1", b: "-2.5", expected: 1},
+		{name: "numbers with spaces", a: " 30 ", b: "4", expected: 1},
+		{name: "strings", a: "10", b: "abc", expected: -1},
+		{name: "strings greater", a: "on", b: "off", expected: 1},
+		{name: "NaN", a: "NaN", b: "1", expected: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// テスト対象の関数を実行
+			actual := CompareValues(tc.a, tc.b)
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package fiap
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/cockroachdb/errors"
+	"github.com/globusdigital/soap"
+)
+
+// treeQueryRS はpointSetの入れ子の構造を読み込むためのqueryRS
+type treeQueryRS struct {
+	XMLName   xml.Name `xml:"http://soap.fiap.org/ queryRS"`
+	Transport *struct {
+		Body *struct {
+			PointSet []*model.OriginalPointSet `xml:"pointSet"`
+		} `xml:"body"`
+	} `xml:"transport"`
+}
+
+// parseTrees は受信したqueryRSから、bodyの各pointSetの木構造をIDをキーとしたmapで返す。時刻はlocのタイムゾーンに揃える
+func parseTrees(body []byte, loc *time.Location) (map[string]*model.PointSetTree, error) {
+	queryRS := &treeQueryRS{}
+	if err := xml.Unmarshal(body, &soap.Envelope{Body: soap.Body{Content: queryRS}}); err != nil {
+		return nil, errors.Wrap(err, "failed to parse pointSet tree")
+	}
+	trees := make(map[string]*model.PointSetTree)
+	if queryRS.Transport == nil || queryRS.Transport.Body == nil {
+		return trees, nil
+	}
+	for _, ps := range queryRS.Transport.Body.PointSet {
+		tree, err := newTree(ps, loc)
+		if err != nil {
+			return nil, err
+		}
+		if existing, ok := trees[ps.Id]; ok {
+			mergeTree(existing, tree)
+		} else {
+			trees[ps.Id] = tree
+		}
+	}
+	return trees, nil
+}
+
+// newTree はOriginalPointSetを木構造に変換する。IDが空のpointSetとpoint、時刻のないvalueはエラーとする
+func newTree(ps *model.OriginalPointSet, loc *time.Location) (*model.PointSetTree, error) {
+	if err := validateResponseID(ps.Id); err != nil {
+		return nil, errors.Wrap(err, "invalid pointSet id in tree")
+	}
+	tree := &model.PointSetTree{
+		PointSets: make(map[string]*model.PointSetTree),
+		Points:    make(map[string]([]model.Value)),
+	}
+	for _, child := range ps.PointSet {
+		childTree, err := newTree(child, loc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "in pointSet %s", ps.Id)
+		}
+		if existing, ok := tree.PointSets[child.Id]; ok {
+			mergeTree(existing, childTree)
+		} else {
+			tree.PointSets[child.Id] = childTree
+		}
+	}
+	for _, p := range ps.Point {
+		if err := validateResponseID(p.Id); err != nil {
+			return nil, errors.Wrapf(err, "invalid point id in pointSet %s", ps.Id)
+		}
+		values := make([]model.Value, 0, len(p.Value))
+		for j, v := range p.Value {
+			if v.Time.IsZero() {
+				return nil, errors.Newf("value has no time, point: %s, index: %d", p.Id, j)
+			}
+			if loc != nil {
+				v.Time = v.Time.In(loc)
+			}
+			values = append(values, v)
+		}
+		tree.Points[p.Id] = append(tree.Points[p.Id], values...)
+	}
+	return tree, nil
+}
+
+// mergeTree はsrcの子のpointSetとpointをdstに追加する。同じIDの子のpointSetは再帰的にまとめ、同じIDのpointは時系列データを連結する
+func mergeTree(dst *model.PointSetTree, src *model.PointSetTree) {
+	for id, child := range src.PointSets {
+		if existing, ok := dst.PointSets[id]; ok {
+			mergeTree(existing, child)
+		} else {
+			dst.PointSets[id] = child
+		}
+	}
+	for id, values := range src.Points {
+		dst.Points[id] = append(dst.Points[id], values...)
+	}
+}
+
+// mergeTrees はsrcの各木構造をdstの同じIDの木構造にまとめる
+func mergeTrees(dst map[string]*model.PointSetTree, src map[string]*model.PointSetTree) {
+	for id, tree := range src {
+		if existing, ok := dst[id]; ok {
+			mergeTree(existing, tree)
+		} else {
+			dst[id] = tree
+		}
+	}
+}
+
+// deduplicateTrees はpolicyに従って木構造に含まれるすべての時系列データの重複を取り除く
+func deduplicateTrees(trees map[string]*model.PointSetTree, policy model.DeduplicationPolicy) error {
+	for id, tree := range trees {
+		if err := deduplicatePoints(tree.Points, policy); err != nil {
+			return errors.Wrapf(err, "in pointSet %s", id)
+		}
+		if err := deduplicateTrees(tree.PointSets, policy); err != nil {
+			return errors.Wrapf(err, "in pointSet %s", id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package fiap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+// nestedBody は入れ子のpointSetの中に時系列データを返すbody
+const nestedBody = `
+<body>
+	<pointSet id="http://xxxxxxxx/tokyo/">
+		<pointSet id="http://xxxxxxxx/tokyo/building1/">
+			<point id="http://xxxxxxxx/tokyo/building1/Room101/">
+				<value time="2012-02-02T16:34:05.000+09:00">30</value>
+				<value time="2012-02-02T16:35:05.000+09:00">31</value>
+			</point>
+			<point id="http://xxxxxxxx/tokyo/building1/Room102/">
+				<value time="2012-02-02T16:34:05.000+09:00">25</value>
+				<value time="2012-02-02T16:34:05.000+09:00">26</value>
+			</point>
+		</pointSet>
+		<pointSet id="http://xxxxxxxx/tokyo/building2/"/>
+		<point id="http://xxxxxxxx/tokyo/weather/"/>
+	</pointSet>
+</body>
+`
+
+func TestFetchOnceTree(t *testing.T) {
+	f := &FetchClient{ConnectionURL: defaultConnectionURL, Location: time.UTC}
+	keys := []model.UserInputKey{{ID: "http://xxxxxxxx/tokyo/"}}
+
+	// mockの有効化
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", defaultConnectionURL, testutil.CustomBodyResponder(nestedBody))
+
+	t.Run("tree", func(t *testing.T) {
+		expected := map[string]*model.PointSetTree{
+			"http://xxxxxxxx/tokyo/": {
+				PointSets: map[string]*model.PointSetTree{
+					"http://xxxxxxxx/tokyo/building1/": {
+						PointSets: map[string]*model.PointSetTree{},
+						Points: map[string]([]model.Value){
+							"http://xxxxxxxx/tokyo/building1/Room101/": {
+								{Time: time.Date(2012, 2, 2, 7, 34, 5, 0, time.UTC), Value: "30"},
+								{Time: time.Date(2012, 2, 2, 7, 35, 5, 0, time.UTC), Value: "31"},
+							},
+							"http://xxxxxxxx/tokyo/building1/Room102/": {
+								{Time: time.Date(2012, 2, 2, 7, 34, 5, 0, time.UTC), Value: "26"},
+							},
+						},
+					},
+					"http://xxxxxxxx/tokyo/building2/": {
+						PointSets: map[string]*model.PointSetTree{},
+						Points:    map[string]([]model.Value){},
+					},
+				},
+				Points: map[string]([]model.Value){
+					"http://xxxxxxxx/tokyo/weather/": {},
+				},
+			},
+		}
+
+		// テスト対象の関数を実行
+		result, err := f.FetchOnceContext(context.Background(), keys, &model.FetchOnceOption{Tree: true, Deduplication: model.DeduplicationKeepLast})
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, result.Trees)
+		// 平坦化したpointSetも従来どおり返す
+		assert.Equal(t, model.ProcessedPointSet{
+			PointSetID: []string{"http://xxxxxxxx/tokyo/building1/", "http://xxxxxxxx/tokyo/building2/"},
+			PointID:    []string{"http://xxxxxxxx/tokyo/weather/"},
+		}, result.PointSets["http://xxxxxxxx/tokyo/"])
+	})
+
+	t.Run("without tree", func(t *testing.T) {
+		// テスト対象の関数を実行
+		result, err := f.FetchOnceContext(context.Background(), keys, &model.FetchOnceOption{})
+
+		require.NoError(t, err)
+		assert.Nil(t, result.Trees)
+		assert.Len(t, result.PointSets, 1)
+	})
+
+	t.Run("fetch with tree", func(t *testing.T) {
+		// テスト対象の関数を実行
+		result, err := f.FetchContext(context.Background(), keys, &model.FetchOption{Tree: true})
+
+		require.NoError(t, err)
+		require.Contains(t, result.Trees, "http://xxxxxxxx/tokyo/")
+		assert.Len(t, result.Trees["http://xxxxxxxx/tokyo/"].PointSets["http://xxxxxxxx/tokyo/building1/"].Points["http://xxxxxxxx/tokyo/building1/Room102/"], 2)
+	})
+}
+
+func TestFetchOnceTreeLargeResponse(t *testing.T) {
+	f := &FetchClient{ConnectionURL: defaultConnectionURL, Location: time.UTC}
+	keys := []model.UserInputKey{{ID: "http://xxxxxxxx/tokyo/"}}
+
+	// エラーの詳細のために保持する大きさ(maxCapturedBodySize)を超えるレスポンス
+	const n = 3000
+	var body strings.Builder
+	body.WriteString(`<body><pointSet id="http://xxxxxxxx/tokyo/"><point id="http://xxxxxxxx/tokyo/Room101/">`)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&body, `<value time="%s">%d</value>`, time.Date(2012, 2, 2, 0, 0, i, 0, time.UTC).Format(time.RFC3339), i)
+	}
+	body.WriteString(`</point></pointSet></body>`)
+	require.Greater(t, body.Len(), maxCapturedBodySize)
+
+	// mockの有効化
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", defaultConnectionURL, testutil.CustomBodyResponder(body.String()))
+
+	// テスト対象の関数を実行
+	result, err := f.FetchOnceContext(context.Background(), keys, &model.FetchOnceOption{Tree: true})
+
+	require.NoError(t, err)
+	require.Contains(t, result.Trees, "http://xxxxxxxx/tokyo/")
+	assert.Len(t, result.Trees["http://xxxxxxxx/tokyo/"].Points["http://xxxxxxxx/tokyo/Room101/"], n)
+}
+
+func TestFetchOnceTreeMalformed(t *testing.T) {
+	f := &FetchClient{ConnectionURL: defaultConnectionURL}
+	keys := []model.UserInputKey{{ID: "http://xxxxxxxx/tokyo/"}}
+
+	// mockの有効化
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", defaultConnectionURL, testutil.CustomBodyResponder(`
+	<body>
+		<pointSet id="http://xxxxxxxx/tokyo/">
+			<pointSet id="http://xxxxxxxx/tokyo/building1/">
+				<point id="http://xxxxxxxx/tokyo/building1/Room101/"><value>30</value></point>
+			</pointSet>
+		</pointSet>
+	</body>
+	`))
+
+	// テスト対象の関数を実行
+	_, err := f.FetchOnceContext(context.Background(), keys, &model.FetchOnceOption{Tree: true})
+
+	assert.ErrorIs(t, err, ErrMalformedResponse)
+	assert.ErrorContains(t, err, "value has no time, point: http://xxxxxxxx/tokyo/building1/Room101/, index: 0")
+
+	// Treeを指定しない場合は子の時系列データを読み込まない
+	_, err = f.FetchOnceContext(context.Background(), keys, &model.FetchOnceOption{})
+	assert.NoError(t, err)
+}
+
+func TestMergeTrees(t *testing.T) {
+	at := time.Date(2012, 2, 2, 16, 34, 5, 0, time.UTC)
+	newTree := func(value string) map[string]*model.PointSetTree {
+		return map[string]*model.PointSetTree{
+			"ps": {
+				PointSets: map[string]*model.PointSetTree{
+					"child": {
+						PointSets: map[string]*model.PointSetTree{},
+						Points:    map[string]([]model.Value){"p": {{Time: at, Value: value}}},
+					},
+				},
+				Points: map[string]([]model.Value){},
+			},
+		}
+	}
+	dst := newTree("1")
+
+	// テスト対象の関数を実行
+	mergeTrees(dst, newTree("2"))
+
+	assert.Equal(t, []model.Value{{Time: at, Value: "1"}, {Time: at, Value: "2"}}, dst["ps"].PointSets["child"].Points["p"])
+}
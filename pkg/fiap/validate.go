@@ -0,0 +1,209 @@
+package fiap
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/tools"
+	"github.com/cockroachdb/errors"
+)
+
+// invalidIDChars はFIAPのIDとして使用できない文字。URIに使用できない文字と空白を含む
+const invalidIDChars = " <>\"{}|\\^`"
+
+/*
+ValidateKeys checks the keys against the constraints of the FIAP specification and returns all problems at once.
+
+ValidateKeysは、keysがFIAPの仕様の制約を満たしているかを検証し、すべての問題をまとめたエラーを返します。
+問題がない場合はnilを返します。
+
+FetchとFetchOnceは、リクエストを送信する前にこの関数でkeysを検証します。
+返されたエラーはerrors.Is(err, ErrInvalidKey)で判定できます。
+
+keyが同じ条件かどうかは、FetchClient.TimeLayoutの初期値と同じtime.RFC3339の書式で時刻を比較して判定します。
+そのため、秒未満のみが異なるkeyは重複として扱います。別の書式で送信する場合はValidateKeysWithLayoutを使用してください。
+
+errの発生条件
+ - keysの長さが0の場合(ErrEmptyKeysとも判定できます)
+ - keys.IDが空の場合(ErrEmptyIDとも判定できます)
+ - keys.IDがURIとして解釈できない場合、または空白やURIに使用できない文字を含む場合
+ - keys.LtとKeys.Lteq、またはkeys.GtとKeys.Gteqの両方が指定されている場合
+ - keys.Eqと他の時刻の条件(keys.Neq、keys.Lt、keys.Gt、keys.Lteq、keys.Gteq)の両方が指定されている場合
+ - keys.MinMaxIndicatorとkeys.Eqの両方が指定されている場合
+ - 範囲の開始時刻(GtまたはGteq)が終了時刻(LtまたはLteq)より後の場合。境界を含まない条件で開始時刻と終了時刻が同じ場合も含む
+ - keys.Valueと時刻の条件(keys.Eq、keys.Neq、keys.Lt、keys.Gt、keys.Lteq、keys.Gteq)の両方が指定されている場合
+ - keys.Valueの条件に空文字列が指定されている場合、またはkeys.Valueの条件の組み合わせが時刻の条件と同じ制約を満たさない場合(keys.Value.Eqと他の値の条件の両方が指定されている場合を含む)
+ - 同じ条件のkeyが複数指定されている場合
+*/
+func ValidateKeys(keys []model.UserInputKey) error {
+	return ValidateKeysWithLayout(keys, time.RFC3339)
+}
+
+/*
+ValidateKeysWithLayout checks the keys like ValidateKeys, comparing the times of duplicate keys in the layout.
+
+ValidateKeysWithLayoutは、ValidateKeysと同様にkeysを検証します。
+keyが同じ条件かどうかは、FIAPサーバに送信するときと同じlayoutの書式で時刻を比較して判定します。layoutが空文字の場合はtime.RFC3339を使用します。
+
+FetchとFetchOnceは、FetchClient.TimeLayoutを指定してこの関数でkeysを検証します。errの発生条件はValidateKeysと同じです。
+*/
+func ValidateKeysWithLayout(keys []model.UserInputKey, layout string) error {
+	if len(keys) == 0 {
+		return markError(errors.New("keys is empty"), ErrEmptyKeys, ErrInvalidKey)
+	}
+
+	var errs []error
+	seen := make(map[string]int, len(keys))
+	for i, key := range keys {
+		errs = append(errs, validateKey(i, key)...)
+
+		identity := keyIdentity(key, layout)
+		if j, ok := seen[identity]; ok {
+			errs = append(errs, errors.Newf("keys is duplicated, index: %d and %d, id: %s", j, i, key.ID))
+		} else {
+			seen[identity] = i
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	for i, err := range errs {
+		errs[i] = markError(err, ErrInvalidKey)
+	}
+	return errors.Join(errs...)
+}
+
+// validateKey は1つのkeyを検証し、見つかった問題をすべて返す。iはエラーメッセージに含めるkeysの位置
+func validateKey(i int, key model.UserInputKey) []error {
+	var errs []error
+	if key.ID == "" {
+		errs = append(errs, markError(errors.Newf("keys.ID is empty, index: %d", i), ErrEmptyID))
+	} else if err := validateID(key.ID); err != nil {
+		errs = append(errs, errors.Wrapf(err, "keys.ID is not a valid URI, index: %d, id: %q", i, key.ID))
+	}
+	if key.Eq != nil && (key.Neq != nil || key.Lt != nil || key.Gt != nil || key.Lteq != nil || key.Gteq != nil) {
+		errs = append(errs, errors.Newf("keys.Eq is combined with other time conditions, index: %d", i))
+	}
+	if key.Lt != nil && key.Lteq != nil {
+		errs = append(errs, errors.Newf("both keys.Lt and keys.Lteq are set, index: %d", i))
+	}
+	if key.Gt != nil && key.Gteq != nil {
+		errs = append(errs, errors.Newf("both keys.Gt and keys.Gteq are set, index: %d", i))
+	}
+	if key.Eq != nil && key.MinMaxIndicator != model.SelectTypeNone {
+		errs = append(errs, errors.Newf("keys.MinMaxIndicator %q is combined with keys.Eq, index: %d", key.MinMaxIndicator, i))
+	}
+	for _, lower := range []*time.Time{key.Gteq, key.Gt} {
+		for _, upper := range []*time.Time{key.Lteq, key.Lt} {
+			if lower == nil || upper == nil {
+				continue
+			}
+			// 境界を含まない条件では、開始時刻と終了時刻が同じ場合も範囲が空になる
+			if lower.After(*upper) || (lower.Equal(*upper) && (lower == key.Gt || upper == key.Lt)) {
+				errs = append(errs, errors.Newf("keys range is inverted, index: %d, from: %s, until: %s", i, lower.Format(time.RFC3339Nano), upper.Format(time.RFC3339Nano)))
+			}
+		}
+	}
+	if key.Value != nil {
+		errs = append(errs, validateValueCondition(i, key)...)
+	}
+	return errs
+}
+
+// validateValueCondition はkey.Valueの条件を検証し、見つかった問題をすべて返す。値の範囲はtools.CompareValuesで比較する
+func validateValueCondition(i int, key model.UserInputKey) []error {
+	var errs []error
+	v := key.Value
+	if key.Eq != nil || key.Neq != nil || key.Lt != nil || key.Gt != nil || key.Lteq != nil || key.Gteq != nil {
+		errs = append(errs, errors.Newf("keys.Value is combined with time conditions, index: %d", i))
+	}
+	for _, c := range []struct {
+		name  string
+		value *string
+	}{
+		{"Eq", v.Eq}, {"Neq", v.Neq}, {"Lt", v.Lt}, {"Gt", v.Gt}, {"Lteq", v.Lteq}, {"Gteq", v.Gteq},
+	} {
+		if c.value != nil && *c.value == "" {
+			errs = append(errs, errors.Newf("keys.Value.%s is empty, index: %d", c.name, i))
+		}
+	}
+	if v.Eq != nil && (v.Neq != nil || v.Lt != nil || v.Gt != nil || v.Lteq != nil || v.Gteq != nil) {
+		errs = append(errs, errors.Newf("keys.Value.Eq is combined with other value conditions, index: %d", i))
+	}
+	if v.Lt != nil && v.Lteq != nil {
+		errs = append(errs, errors.Newf("both keys.Value.Lt and keys.Value.Lteq are set, index: %d", i))
+	}
+	if v.Gt != nil && v.Gteq != nil {
+		errs = append(errs, errors.Newf("both keys.Value.Gt and keys.Value.Gteq are set, index: %d", i))
+	}
+	if v.Eq != nil && key.MinMaxIndicator != model.SelectTypeNone {
+		errs = append(errs, errors.Newf("keys.MinMaxIndicator %q is combined with keys.Value.Eq, index: %d", key.MinMaxIndicator, i))
+	}
+	for _, lower := range []*string{v.Gteq, v.Gt} {
+		for _, upper := range []*string{v.Lteq, v.Lt} {
+			if lower == nil || upper == nil {
+				continue
+			}
+			c := tools.CompareValues(*lower, *upper)
+			if c > 0 || (c == 0 && (lower == v.Gt || upper == v.Lt)) {
+				errs = append(errs, errors.Newf("keys value range is inverted, index: %d, from: %s, until: %s", i, *lower, *upper))
+			}
+		}
+	}
+	return errs
+}
+
+// validateID はIDがFIAPのIDとして使用できる絶対URIかを検証する
+func validateID(id string) error {
+	if i := strings.IndexFunc(id, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(invalidIDChars, r)
+	}); i >= 0 {
+		return errors.Newf("invalid character %q", []rune(id[i:])[0])
+	}
+	u, err := url.Parse(id)
+	if err != nil {
+		return errors.Unwrap(err)
+	}
+	if u.Fragment != "" || strings.Contains(id, "#") {
+		return errors.New("fragment is not allowed")
+	}
+	if !u.IsAbs() {
+		return errors.New("scheme is empty")
+	}
+	if (u.Scheme == "http" || u.Scheme == "https") && u.Host == "" {
+		return errors.New("host is empty")
+	}
+	return nil
+}
+
+// keyIdentity は重複を検出するため、keyの条件を文字列に変換する。時刻はFIAPサーバに送信するときと同じlayoutの書式で比較する
+func keyIdentity(key model.UserInputKey, layout string) string {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	var b strings.Builder
+	b.WriteString(key.ID)
+	for _, t := range []*time.Time{key.Eq, key.Neq, key.Lt, key.Gt, key.Lteq, key.Gteq} {
+		b.WriteByte('\x00')
+		if t != nil {
+			b.WriteString(t.UTC().Format(layout))
+		}
+	}
+	b.WriteByte('\x00')
+	b.WriteString(string(key.MinMaxIndicator))
+	if v := key.Value; v != nil {
+		b.WriteString("\x00value")
+		for _, s := range []*string{v.Eq, v.Neq, v.Lt, v.Gt, v.Lteq, v.Gteq} {
+			b.WriteByte('\x00')
+			if s != nil {
+				b.WriteString(strconv.Quote(*s))
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,259 @@
+package fiap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/model"
+	"github.com/SIOS-Technology-Inc/go-fiap-client/pkg/fiap/testutil"
+)
+
+func TestValidateKeys(t *testing.T) {
+	id := "http://xxxxxxxx/tokyo/building1/Room101/"
+	fromDate := testutil.TimeToTimep(time.Date(2012, 2, 2, 0, 0, 0, 0, time.UTC))
+	untilDate := testutil.TimeToTimep(time.Date(2012, 2, 3, 0, 0, 0, 0, time.UTC))
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		keys     []model.UserInputKey
+		is       []error
+		messages []string
+	}{
+		{
+			name: "valid keys",
+			keys: []model.UserInputKey{
+				{ID: id, Gteq: fromDate, Lteq: untilDate, MinMaxIndicator: model.SelectTypeMaximum},
+				{ID: id, Eq: fromDate},
+				{ID: id, Gt: fromDate, Lt: untilDate, Neq: fromDate},
+				{ID: "http://example.jp/sios/example/Temperature"},
+				{ID: "urn:example:point:1"},
+			},
+		},
+		{
+			name: "same from and until",
+			keys: []model.UserInputKey{{ID: id, Gteq: fromDate, Lteq: fromDate}},
+		},
+		{
+			name:     "empty keys",
+			keys:     []model.UserInputKey{},
+			is:       []error{ErrEmptyKeys},
+			messages: []string{"keys is empty"},
+		},
+		{
+			name:     "empty id",
+			keys:     []model.UserInputKey{{ID: id}, {ID: ""}},
+			is:       []error{ErrEmptyID},
+			messages: []string{"keys.ID is empty, index: 1"},
+		},
+		{
+			name:     "id with space",
+			keys:     []model.UserInputKey{{ID: "http://xxxxxxxx/tokyo/building1/Room 101/"}},
+			messages: []string{`keys.ID is not a valid URI, index: 0, id: "http://xxxxxxxx/tokyo/building1/Room 101/": invalid character ' '`},
+		},
+		{
+			name:     "id with quote",
+			keys:     []model.UserInputKey{{ID: `"http://xxxxxxxx/tokyo/building1/", "http://xxxxxxxx/tokyo/building2/"`}},
+			messages: []string{`invalid character '"'`},
+		},
+		{
+			name:     "id with fragment",
+			keys:     []model.UserInputKey{{ID: "http://xxxxxxxx/tokyo/building1/#Room101"}},
+			messages: []string{"fragment is not allowed"},
+		},
+		{
+			name:     "relative id",
+			keys:     []model.UserInputKey{{ID: "tokyo/building1/Room101/"}},
+			messages: []string{`keys.ID is not a valid URI, index: 0, id: "tokyo/building1/Room101/": scheme is empty`},
+		},
+		{
+			name:     "id without host",
+			keys:     []model.UserInputKey{{ID: "http:///tokyo/building1/"}},
+			messages: []string{"host is empty"},
+		},
+		{
+			name:     "id with invalid escape",
+			keys:     []model.UserInputKey{{ID: "http://xxxxxxxx/tokyo/%zz/"}},
+			messages: []string{"keys.ID is not a valid URI, index: 0"},
+		},
+		{
+			name:     "Lt and Lteq",
+			keys:     []model.UserInputKey{{ID: id, Lt: untilDate, Lteq: untilDate}},
+			messages: []string{"both keys.Lt and keys.Lteq are set, index: 0"},
+		},
+		{
+			name:     "Gt and Gteq",
+			keys:     []model.UserInputKey{{ID: id, Gt: fromDate, Gteq: fromDate}},
+			messages: []string{"both keys.Gt and keys.Gteq are set, index: 0"},
+		},
+		{
+			name:     "select with Eq",
+			keys:     []model.UserInputKey{{ID: id, Eq: fromDate, MinMaxIndicator: model.SelectTypeMinimum}},
+			messages: []string{`keys.MinMaxIndicator "minimum" is combined with keys.Eq, index: 0`},
+		},
+		{
+			name:     "Eq with other time conditions",
+			keys:     []model.UserInputKey{{ID: id, Eq: fromDate, Lt: untilDate}},
+			messages: []string{"keys.Eq is combined with other time conditions, index: 0"},
+		},
+		{
+			name:     "inverted range",
+			keys:     []model.UserInputKey{{ID: id, Gteq: untilDate, Lteq: fromDate}},
+			messages: []string{"keys range is inverted, index: 0, from: 2012-02-03T00:00:00Z, until: 2012-02-02T00:00:00Z"},
+		},
+		{
+			name:     "exclusive range at the same time",
+			keys:     []model.UserInputKey{{ID: id, Gt: fromDate, Lteq: fromDate}},
+			messages: []string{"keys range is inverted, index: 0"},
+		},
+		{
+			name: "valid value keys",
+			keys: []model.UserInputKey{
+				{ID: id, Value: &model.ValueCondition{Gteq: testutil.StringToStringp("9"), Lt: testutil.StringToStringp("10")}, MinMaxIndicator: model.SelectTypeMaximum},
+				{ID: id, Value: &model.ValueCondition{Eq: testutil.StringToStringp("ON")}},
+			},
+		},
+		{
+			name:     "value with time conditions",
+			keys:     []model.UserInputKey{{ID: id, Gteq: fromDate, Value: &model.ValueCondition{Eq: testutil.StringToStringp("30")}}},
+			messages: []string{"keys.Value is combined with time conditions, index: 0"},
+		},
+		{
+			name:     "empty value",
+			keys:     []model.UserInputKey{{ID: id, Value: &model.ValueCondition{Neq: testutil.StringToStringp("")}}},
+			messages: []string{"keys.Value.Neq is empty, index: 0"},
+		},
+		{
+			name: "conflicting value conditions",
+			keys: []model.UserInputKey{{ID: id, Value: &model.ValueCondition{
+				Lt: testutil.StringToStringp("30"), Lteq: testutil.StringToStringp("30"),
+				Gt: testutil.StringToStringp("20"), Gteq: testutil.StringToStringp("20"),
+			}}},
+			messages: []string{
+				"both keys.Value.Lt and keys.Value.Lteq are set, index: 0",
+				"both keys.Value.Gt and keys.Value.Gteq are set, index: 0",
+			},
+		},
+		{
+			name:     "value Eq with other value conditions",
+			keys:     []model.UserInputKey{{ID: id, Value: &model.ValueCondition{Eq: testutil.StringToStringp("30"), Neq: testutil.StringToStringp("40")}}},
+			messages: []string{"keys.Value.Eq is combined with other value conditions, index: 0"},
+		},
+		{
+			name:     "select with value Eq",
+			keys:     []model.UserInputKey{{ID: id, Value: &model.ValueCondition{Eq: testutil.StringToStringp("30")}, MinMaxIndicator: model.SelectTypeMinimum}},
+			messages: []string{`keys.MinMaxIndicator "minimum" is combined with keys.Value.Eq, index: 0`},
+		},
+		{
+			name:     "inverted value range",
+			keys:     []model.UserInputKey{{ID: id, Value: &model.ValueCondition{Gteq: testutil.StringToStringp("10"), Lteq: testutil.StringToStringp("9")}}},
+			messages: []string{"keys value range is inverted, index: 0, from: 10, until: 9"},
+		},
+		{
+			name: "duplicate keys",
+			keys: []model.UserInputKey{
+				{ID: id, Gteq: fromDate},
+				{ID: id},
+				{ID: id, Gteq: testutil.TimeToTimep(fromDate.In(time.FixedZone("Asia/Tokyo", 9*60*60)))},
+			},
+			messages: []string{"keys is duplicated, index: 0 and 2"},
+		},
+		{
+			name: "duplicate value keys",
+			keys: []model.UserInputKey{
+				{ID: id, Value: &model.ValueCondition{Eq: testutil.StringToStringp("30")}},
+				{ID: id, Value: &model.ValueCondition{Neq: testutil.StringToStringp("30")}},
+				{ID: id, Value: &model.ValueCondition{Eq: testutil.StringToStringp("30")}},
+			},
+			messages: []string{"keys is duplicated, index: 0 and 2"},
+		},
+		{
+			name: "multiple problems",
+			keys: []model.UserInputKey{
+				{ID: "", Lt: untilDate, Lteq: untilDate},
+				{ID: id, Eq: fromDate, MinMaxIndicator: model.SelectTypeMaximum, Gteq: untilDate, Lteq: fromDate},
+			},
+			is: []error{ErrEmptyID},
+			messages: []string{
+				"keys.ID is empty, index: 0",
+				"both keys.Lt and keys.Lteq are set, index: 0",
+				`keys.MinMaxIndicator "maximum" is combined with keys.Eq, index: 1`,
+				"keys range is inverted, index: 1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateKeys(tc.keys)
+
+			if len(tc.messages) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidKey)
+			for _, target := range tc.is {
+				assert.ErrorIs(t, err, target)
+			}
+			for _, message := range tc.messages {
+				assert.Contains(t, err.Error(), message)
+			}
+		})
+	}
+}
+
+func TestValidateKeysWithLayout(t *testing.T) {
+	id := "http://xxxxxxxx/tokyo/building1/Room101/"
+	fromDate := time.Date(2012, 2, 2, 0, 0, 0, 0, time.UTC)
+	// 秒未満のみが異なるkey
+	keys := []model.UserInputKey{
+		{ID: id, Gteq: testutil.TimeToTimep(fromDate)},
+		{ID: id, Gteq: testutil.TimeToTimep(fromDate.Add(500 * time.Millisecond))},
+	}
+
+	// テストケースを定義
+	testCases := []struct {
+		name     string
+		layout   string
+		expected string
+	}{
+		{name: "default layout", layout: "", expected: "keys is duplicated, index: 0 and 1"},
+		{name: "RFC3339", layout: time.RFC3339, expected: "keys is duplicated, index: 0 and 1"},
+		{name: "RFC3339Nano", layout: time.RFC3339Nano},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// テスト対象の関数を実行
+			err := ValidateKeysWithLayout(keys, tc.layout)
+
+			if tc.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidKey)
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+
+	// ValidateKeysは送信時の初期値と同じRFC3339の書式で比較する
+	assert.ErrorContains(t, ValidateKeys(keys), "keys is duplicated, index: 0 and 1")
+}
+
+func TestFetchOnceInvalidKeys(t *testing.T) {
+	f := NewFetchClient(defaultConnectionURL)
+	fromDate := time.Date(2012, 2, 3, 0, 0, 0, 0, time.UTC)
+	untilDate := time.Date(2012, 2, 2, 0, 0, 0, 0, time.UTC)
+
+	// リクエストを送信する前にエラーになるため、mockは不要
+	_, _, _, _, err := f.FetchOnce([]model.UserInputKey{
+		{ID: "http://xxxxxxxx/tokyo/building1/Room101/", Gteq: &fromDate, Lteq: &untilDate},
+	}, nil)
+
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	assert.False(t, errors.Is(err, ErrTransport))
+	assert.Contains(t, err.Error(), "keys range is inverted")
+}